@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/josegonzalez/aws-eks-calculator/internal/prefs"
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// Seams so tests can avoid the Pricing API.
+var (
+	newAPISource = pricing.APISource
+	listRegions  = pricing.Regions
+)
+
+// cacheCommand inspects and maintains the pricing cache. It honours the
+// cache TTL set in the preferences, and fetches with the credentials in
+// opts, recording or replaying responses as opts say. Offline, only the
+// subcommands that do not fetch run.
+func cacheCommand(args []string, opts tui.Options) error {
+	if len(args) == 0 {
+		return fmt.Errorf("cache requires a subcommand: list, purge, refresh or warm\n\n%s", usage)
+	}
+	c := newCache()
+	c.SetTTL(prefs.Load().CacheTTLDuration())
+	client := pricing.NewClient(opts.Credentials)
+	client.SetEndpoint(opts.Endpoint)
+
+	if opts.Offline && (args[0] == "refresh" || args[0] == "warm") {
+		return fmt.Errorf("cache %s calls the Pricing API, which offline mode rules out", args[0])
+	}
+
+	switch args[0] {
+	case "list":
+		return cacheList(c)
+	case "purge":
+		return cachePurge(c, args[1:])
+	case "refresh":
+		return cacheRefresh(c, opts.PricingAPI(client), args[1:])
+	case "warm":
+		return cacheWarm(c, opts.PricingAPI(client), client.Endpoint(), args[1:])
+	default:
+		return fmt.Errorf("unknown cache subcommand %q\n\n%s", args[0], usage)
+	}
+}
+
+// cacheList lists every cached region with the age and sources of its
+// rates.
+func cacheList(c *pricing.Cache) error {
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(stdout, "No cached rates")
+		return err
+	}
+
+	// The tabwriter holds the table until Flush, which reports any error
+	// writing it out.
+	now := time.Now()
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "REGION\tFETCHED\tAGE\tSOURCE\tSTATUS")
+	for _, e := range entries {
+		status := "fresh"
+		if e.Expired {
+			status = "expired"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Region, e.FetchedAt.Local().Format("2006-01-02 15:04"),
+			formatAge(now.Sub(e.FetchedAt)), entrySources(e.Rates), status)
+	}
+	return w.Flush()
+}
+
+// formatAge renders a duration in its largest whole unit, e.g. "3h".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
+
+// entrySources lists the sources of the cached rates in field order, each
+// once.
+func entrySources(rates pricing.RateSheet) string {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, f := range pricing.Fields() {
+		s := rates.Provenance[f].Source
+		if s == "" {
+			s = "unknown"
+		}
+		if !seen[s] {
+			seen[s] = true
+			sources = append(sources, s)
+		}
+	}
+	return strings.Join(sources, ",")
+}
+
+// cachePurge deletes the cached rates for the given regions, or for every
+// region with --all.
+func cachePurge(c *pricing.Cache, regions []string) error {
+	switch {
+	case len(regions) == 1 && regions[0] == "--all":
+		if err := c.PurgeAll(); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(stdout, "Purged every cached region")
+		return err
+	case len(regions) == 0:
+		return fmt.Errorf("cache purge requires regions or --all\n\n%s", usage)
+	}
+
+	for _, region := range regions {
+		if err := c.Purge(region); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(stdout, "Purged %d region(s): %s\n", len(regions), strings.Join(regions, ", "))
+	return err
+}
+
+// cacheRefresh fetches the given regions from the Pricing API whether or
+// not they are cached. Without regions it refreshes every cached region.
+func cacheRefresh(c *pricing.Cache, client pricing.PricingAPI, regions []string) error {
+	if len(regions) == 0 {
+		entries, err := c.Entries()
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			regions = append(regions, e.Region)
+		}
+	}
+	if len(regions) == 0 {
+		_, err := fmt.Fprintln(stdout, "No cached rates to refresh")
+		return err
+	}
+	return fetchRegions(c, client, regions, "Refreshed")
+}
+
+// cacheWarm fetches the given regions, or every known region whose prices
+// the endpoint serves, unless they are already cached and fresh.
+func cacheWarm(c *pricing.Cache, client pricing.PricingAPI, endpoint string, regions []string) error {
+	if len(regions) == 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), pricing.CallTimeout(endpoint))
+		known, _ := listRegions(ctx, client, c)
+		cancel()
+		for _, r := range known {
+			if slices.Contains(r.Partition().Endpoints, endpoint) {
+				regions = append(regions, r.Code)
+			}
+		}
+	}
+
+	var stale []string
+	for _, region := range regions {
+		if c.Load(region) == nil {
+			stale = append(stale, region)
+		}
+	}
+	if fresh := len(regions) - len(stale); fresh > 0 {
+		if _, err := fmt.Fprintf(stdout, "%d region(s) already cached\n", fresh); err != nil {
+			return err
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return fetchRegions(c, client, stale, "Cached")
+}
+
+// fetchRegions fetches each region from the Pricing API, which saves it to
+// c, reporting the regions done with verb. Each fetch is given long enough
+// to retry and fail over across the endpoints. It returns the failures,
+// and stops at the first credentials problem, explaining it.
+func fetchRegions(c *pricing.Cache, client pricing.PricingAPI, regions []string, verb string) error {
+	api := newAPISource(client, c)
+	var done []string
+	var errs []error
+	for _, region := range regions {
+		ctx, cancel := context.WithTimeout(context.Background(), pricing.CallTimeout(region))
+		rates, err := api.Lookup(ctx, region)
+		cancel()
+		if pricing.Classify(err) == pricing.ErrorAuth {
+			// Every other region would fail the same way.
+			errs = append(errs, fmt.Errorf("%s: %s: %w", region, pricing.Diagnose(err), err))
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", region, err))
+			continue
+		}
+		done = append(done, region)
+		if err := reportSuspect(region, rates); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+	}
+
+	if len(done) > 0 {
+		if _, err := fmt.Fprintf(stdout, "%s rates for %d region(s): %s\n", verb, len(done), strings.Join(done, ", ")); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reportSuspect prints the rates for region that fail pricing.CheckRates.
+// They are cached all the same: whether to use them is decided when rates
+// are resolved.
+func reportSuspect(region string, rates pricing.RateSheet) error {
+	for _, a := range pricing.CheckRates(rates) {
+		if _, err := fmt.Fprintf(stdout, "Suspect rate in %s: %s\n", region, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josegonzalez/aws-eks-calculator/internal/prefs"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// withCacheCommand isolates the cache and preferences, captures stdout and
+// replaces the Pricing API with fetch, recording the regions asked for.
+func withCacheCommand(t *testing.T, fetch func(region string) error) (*pricing.Cache, *bytes.Buffer, *[]string) {
+	t.Helper()
+	dir := withTestCache(t)
+	prefs.SetDir(t.TempDir())
+	t.Cleanup(func() { prefs.SetDir("") })
+
+	oldOut := stdout
+	t.Cleanup(func() { stdout = oldOut })
+	var buf bytes.Buffer
+	stdout = &buf
+
+	var fetched []string
+	oldSource := newAPISource
+	t.Cleanup(func() { newAPISource = oldSource })
+	newAPISource = func(_ pricing.PricingAPI, c *pricing.Cache) pricing.Source {
+		return pricing.NewSource(pricing.SourceAPI, func(_ context.Context, region string) (pricing.RateSheet, error) {
+			fetched = append(fetched, region)
+			if err := fetch(region); err != nil {
+				return pricing.RateSheet{}, err
+			}
+			_ = c.Save(region, pricing.DefaultRates())
+			return pricing.RateSheet{Rates: pricing.DefaultRates()}, nil
+		})
+	}
+
+	oldList := listRegions
+	t.Cleanup(func() { listRegions = oldList })
+	listRegions = func(context.Context, pricing.PricingAPI, *pricing.Cache) ([]pricing.Region, error) {
+		return []pricing.Region{{Code: "us-east-1"}, {Code: "eu-west-1"}, {Code: "ap-south-1"}, {Code: "cn-north-1"}}, nil
+	}
+
+	return pricing.NewCacheDir(dir), &buf, &fetched
+}
+
+func succeed(string) error { return nil }
+
+func TestCacheCommandUsage(t *testing.T) {
+	withCacheCommand(t, succeed)
+	if err := run([]string{"cache"}); err == nil || !strings.Contains(err.Error(), "requires a subcommand") {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+	if err := run([]string{"cache", "bogus"}); err == nil || !strings.Contains(err.Error(), `unknown cache subcommand "bogus"`) {
+		t.Errorf("expected an unknown subcommand error, got %v", err)
+	}
+}
+
+func TestCacheList(t *testing.T) {
+	c, out, _ := withCacheCommand(t, succeed)
+	if err := run([]string{"cache", "list"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No cached rates") {
+		t.Errorf("expected an empty listing, got %q", out.String())
+	}
+
+	rates := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	rates.Provenance = map[pricing.Field]pricing.Provenance{
+		pricing.FieldArgoCDBasePerHour: {Source: pricing.SourceAPI},
+		pricing.FieldArgoCDAppPerHour:  {Source: pricing.SourceDefaults},
+	}
+	_ = c.SaveSheet("us-east-1", rates)
+	_ = c.Save("eu-west-1", pricing.DefaultRates())
+	_ = prefs.Save(prefs.Prefs{CacheTTL: "1ns"})
+
+	out.Reset()
+	if err := run([]string{"cache", "list"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "REGION") {
+		t.Fatalf("expected a header and two regions, got %q", out.String())
+	}
+	if !strings.HasPrefix(lines[1], "eu-west-1") || !strings.Contains(lines[1], "unknown") {
+		t.Errorf("expected eu-west-1 without provenance first, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "api,defaults,unknown") || !strings.HasSuffix(lines[2], "expired") {
+		t.Errorf("expected the sources and the configured TTL applied, got %q", lines[2])
+	}
+}
+
+func TestCacheListError(t *testing.T) {
+	withCacheCommand(t, succeed)
+	newCache = func() *pricing.Cache { return pricing.NewCacheDir(filepath.Join(t.TempDir(), "bad[")) }
+	if err := run([]string{"cache", "list"}); err == nil {
+		t.Error("expected the listing error")
+	}
+	if err := run([]string{"cache", "refresh"}); err == nil {
+		t.Error("expected the listing error")
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "<1m"},
+		{5 * time.Minute, "5m"},
+		{3 * time.Hour, "3h"},
+		{72 * time.Hour, "3d"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	c, out, _ := withCacheCommand(t, succeed)
+	for _, region := range []string{"us-east-1", "eu-west-1", "ap-south-1"} {
+		_ = c.Save(region, pricing.DefaultRates())
+	}
+
+	if err := run([]string{"cache", "purge"}); err == nil || !strings.Contains(err.Error(), "requires regions or --all") {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+
+	if err := run([]string{"cache", "purge", "us-east-1", "eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Load("us-east-1") != nil || c.Load("ap-south-1") == nil {
+		t.Error("expected only the named regions purged")
+	}
+	if !strings.Contains(out.String(), "Purged 2 region(s): us-east-1, eu-west-1") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	if err := run([]string{"cache", "purge", "--all"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Load("ap-south-1") != nil || !strings.Contains(out.String(), "Purged every cached region") {
+		t.Errorf("expected every region purged, got %q", out.String())
+	}
+}
+
+func TestCachePurgeErrors(t *testing.T) {
+	withCacheCommand(t, succeed)
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".lock"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	newCache = func() *pricing.Cache { return pricing.NewCacheDir(dir) }
+
+	if err := run([]string{"cache", "purge", "us-east-1"}); err == nil {
+		t.Error("expected the purge error")
+	}
+	if err := run([]string{"cache", "purge", "--all"}); err == nil {
+		t.Error("expected the purge error")
+	}
+}
+
+func TestCacheRefresh(t *testing.T) {
+	c, out, fetched := withCacheCommand(t, func(region string) error {
+		if region == "eu-west-1" {
+			return errors.New("throttled")
+		}
+		return nil
+	})
+
+	if err := run([]string{"cache", "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No cached rates to refresh") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	_ = c.Save("us-east-1", pricing.DefaultRates())
+	_ = c.Save("eu-west-1", pricing.DefaultRates())
+	err := run([]string{"cache", "refresh"})
+	if err == nil || err.Error() != "eu-west-1: throttled" {
+		t.Errorf("expected the failed region reported, got %v", err)
+	}
+	if strings.Join(*fetched, ",") != "eu-west-1,us-east-1" {
+		t.Errorf("expected every cached region fetched though fresh, got %v", *fetched)
+	}
+	if !strings.Contains(out.String(), "Refreshed rates for 1 region(s): us-east-1") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	*fetched = nil
+	if err := run([]string{"cache", "refresh", "ap-south-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(*fetched, ",") != "ap-south-1" || c.Load("ap-south-1") == nil {
+		t.Errorf("expected ap-south-1 fetched and cached, got %v", *fetched)
+	}
+}
+
+func TestCacheRefreshSuspectRates(t *testing.T) {
+	_, out, _ := withCacheCommand(t, succeed)
+	newAPISource = func(pricing.PricingAPI, *pricing.Cache) pricing.Source {
+		return pricing.NewSource(pricing.SourceAPI, func(context.Context, string) (pricing.RateSheet, error) {
+			return pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 3}, Provenance: map[pricing.Field]pricing.Provenance{
+				pricing.FieldArgoCDBasePerHour: {Source: pricing.SourceAPI, Unit: "Hrs"},
+			}}, nil
+		})
+	}
+
+	if err := run([]string{"cache", "refresh", "eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Suspect rate in eu-west-1: ArgoCDBasePerHour is 3, 100x the default 0.03") {
+		t.Errorf("expected the suspect rate reported, got %q", out.String())
+	}
+
+	stdout = &failingWriter{}
+	if err := run([]string{"cache", "refresh", "eu-west-1"}); err == nil || err.Error() != "write failed" {
+		t.Errorf("expected the write error, got %v", err)
+	}
+}
+
+func TestCacheWarm(t *testing.T) {
+	c, out, fetched := withCacheCommand(t, succeed)
+	_ = c.Save("us-east-1", pricing.DefaultRates())
+
+	if err := run([]string{"cache", "warm"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(*fetched, ",") != "eu-west-1,ap-south-1" {
+		t.Errorf("expected the uncached regions fetched, got %v", *fetched)
+	}
+	if !strings.Contains(out.String(), "1 region(s) already cached") || !strings.Contains(out.String(), "Cached rates for 2 region(s): eu-west-1, ap-south-1") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	// The China endpoint serves only the China regions.
+	*fetched = nil
+	if err := run([]string{"--endpoint", "cn-northwest-1", "cache", "warm"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(*fetched, ",") != "cn-north-1" {
+		t.Errorf("expected the China region fetched, got %v", *fetched)
+	}
+
+	*fetched = nil
+	out.Reset()
+	if err := run([]string{"cache", "warm", "us-east-1", "eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*fetched) != 0 || !strings.Contains(out.String(), "2 region(s) already cached") {
+		t.Errorf("expected fresh regions skipped, got %v, %q", *fetched, out.String())
+	}
+}
+
+// lastEndpointAnswers stands in for a Pricing API where only the last
+// endpoint for region answers and the ones before it hang: the call
+// succeeds only if ctx allows every attempt at those to time out first.
+func lastEndpointAnswers(ctx context.Context, region string) error {
+	endpoints := pricing.PartitionOf(region).Endpoints
+	failover := pricing.CallTimeout(region) / time.Duration(len(endpoints)) * time.Duration(len(endpoints)-1)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < failover {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+func TestCacheFetchFailsOver(t *testing.T) {
+	_, out, _ := withCacheCommand(t, succeed)
+	newAPISource = func(_ pricing.PricingAPI, c *pricing.Cache) pricing.Source {
+		return pricing.NewSource(pricing.SourceAPI, func(ctx context.Context, region string) (pricing.RateSheet, error) {
+			if err := lastEndpointAnswers(ctx, region); err != nil {
+				return pricing.RateSheet{}, err
+			}
+			_ = c.Save(region, pricing.DefaultRates())
+			return pricing.RateSheet{Rates: pricing.DefaultRates()}, nil
+		})
+	}
+	listRegions = func(ctx context.Context, _ pricing.PricingAPI, _ *pricing.Cache) ([]pricing.Region, error) {
+		if err := lastEndpointAnswers(ctx, pricing.DefaultEndpoint); err != nil {
+			return nil, err
+		}
+		return []pricing.Region{{Code: "us-east-1"}, {Code: "eu-west-1"}}, nil
+	}
+
+	if err := run([]string{"cache", "refresh", "ap-south-1"}); err != nil {
+		t.Errorf("expected the refresh to fail over, got %v", err)
+	}
+	if err := run([]string{"cache", "warm"}); err != nil {
+		t.Errorf("expected warming to fail over, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Cached rates for 2 region(s): us-east-1, eu-west-1") {
+		t.Errorf("expected the discovered regions warmed, got %q", out.String())
+	}
+}
+
+// failingWriter fails every write after the first ok.
+type failingWriter struct{ ok int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.ok > 0 {
+		w.ok--
+		return len(p), nil
+	}
+	return 0, errors.New("write failed")
+}
+
+func TestCacheCommandWriteErrors(t *testing.T) {
+	c, _, _ := withCacheCommand(t, succeed)
+	stdout = &failingWriter{}
+
+	commands := [][]string{
+		{"cache", "list"},
+		{"cache", "refresh"},
+		{"cache", "purge", "--all"},
+		{"cache", "purge", "us-east-1"},
+		{"cache", "refresh", "us-east-1"},
+		{"cache", "list"},
+		{"cache", "warm", "us-east-1"},
+	}
+	for _, args := range commands {
+		if err := run(args); err == nil || err.Error() != "write failed" {
+			t.Errorf("%v: expected the write error, got %v", args, err)
+		}
+		_ = c.Save("us-east-1", pricing.DefaultRates())
+	}
+}
+
+func TestCacheCommandCredentials(t *testing.T) {
+	withCacheCommand(t, succeed)
+	var used []pricing.Credentials
+	record := func(client pricing.PricingAPI) {
+		used = append(used, client.(*pricing.Client).Credentials())
+	}
+	newAPISource = func(client pricing.PricingAPI, _ *pricing.Cache) pricing.Source {
+		record(client)
+		return pricing.NewSource(pricing.SourceAPI, func(context.Context, string) (pricing.RateSheet, error) {
+			return pricing.RateSheet{Rates: pricing.DefaultRates()}, nil
+		})
+	}
+	listRegions = func(_ context.Context, client pricing.PricingAPI, _ *pricing.Cache) ([]pricing.Region, error) {
+		record(client)
+		return []pricing.Region{{Code: "us-east-1"}}, nil
+	}
+
+	_ = prefs.Save(prefs.Prefs{AWSProfile: "saved"})
+	if err := run([]string{"cache", "refresh", "us-east-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"--profile", "prod", "cache", "warm"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []pricing.Credentials{{Profile: "saved"}, {Profile: "prod"}, {Profile: "prod"}}
+	if len(used) != len(want) || used[0] != want[0] || used[1] != want[1] || used[2] != want[2] {
+		t.Errorf("expected the saved profile, then the option's, got %+v", used)
+	}
+}
+
+func TestCacheRefreshStopsOnCredentialsError(t *testing.T) {
+	_, _, fetched := withCacheCommand(t, func(string) error {
+		return &pricing.CredentialsError{Profile: "prod", Err: errors.New("no EC2 IMDS role found")}
+	})
+
+	err := run([]string{"cache", "refresh", "us-east-1", "eu-west-1"})
+	if err == nil || !strings.Contains(err.Error(), `us-east-1: no usable AWS credentials for profile "prod"`) {
+		t.Errorf("expected the credentials problem explained, got %v", err)
+	}
+	if len(*fetched) != 1 {
+		t.Errorf("expected the refresh to stop after the first region, got %v", *fetched)
+	}
+}
+
+func TestCacheCommandOffline(t *testing.T) {
+	c, out, fetched := withCacheCommand(t, succeed)
+	_ = c.Save("us-east-1", pricing.DefaultRates())
+
+	for _, sub := range []string{"refresh", "warm"} {
+		err := run([]string{"--offline", "cache", sub})
+		if err == nil || !strings.Contains(err.Error(), "cache "+sub+" calls the Pricing API") {
+			t.Errorf("%s: expected an offline error, got %v", sub, err)
+		}
+	}
+	if len(*fetched) != 0 {
+		t.Errorf("expected nothing fetched offline, got %v", *fetched)
+	}
+	if err := run([]string{"--offline", "cache", "list"}); err != nil || !strings.Contains(out.String(), "us-east-1") {
+		t.Errorf("expected the listing offline, got %v, %q", err, out.String())
+	}
+}
+
+func TestCacheCommandFixtures(t *testing.T) {
+	withCacheCommand(t, succeed)
+	var used []pricing.PricingAPI
+	newAPISource = func(client pricing.PricingAPI, _ *pricing.Cache) pricing.Source {
+		used = append(used, client)
+		return pricing.NewSource(pricing.SourceAPI, func(context.Context, string) (pricing.RateSheet, error) {
+			return pricing.RateSheet{Rates: pricing.DefaultRates()}, nil
+		})
+	}
+
+	dir := t.TempDir()
+	if err := run([]string{"--replay", dir, "cache", "refresh", "us-east-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"--record", dir, "cache", "warm", "eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := used[0].(*pricing.Replayer); !ok {
+		t.Errorf("expected a Replayer, got %T", used[0])
+	}
+	if _, ok := used[1].(*pricing.Recorder); !ok {
+		t.Errorf("expected a Recorder, got %T", used[1])
+	}
+}
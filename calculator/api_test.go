@@ -0,0 +1,91 @@
+package calculator_test
+
+import (
+	"testing"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+)
+
+// These assignments fail to compile if a public signature changes. Update
+// them only together with a major version bump.
+var (
+	_ func(calculator.ScenarioInput) calculator.CostBreakdown                            = calculator.Calculate
+	_ func(calculator.Capability) calculator.ScenarioInput                               = calculator.DefaultInput
+	_ func(calculator.ScenarioInput, calculator.CostBreakdown) []calculator.TenantCharge = calculator.Chargeback
+	_ func(calculator.ScenarioInput, float64) calculator.Capacity                        = calculator.Plan
+	_ func(calculator.Capability) string                                                 = calculator.Capability.String
+	_ func(calculator.AllocationPolicy) string                                           = calculator.AllocationPolicy.String
+	_ func(calculator.Capacity) bool                                                     = calculator.Capacity.Fits
+	_ func([]calculator.RateTier, float64) []calculator.TierCost                         = calculator.ApplyTiers
+	_ []calculator.Capability                                                            = calculator.AllCapabilities
+	_ []calculator.AllocationPolicy                                                      = calculator.AllAllocationPolicies
+)
+
+func TestAPIScenarioInputFields(t *testing.T) {
+	_ = calculator.ScenarioInput{
+		Name:                         "",
+		Capability:                   calculator.CapabilityArgoCD,
+		NumClusters:                  0,
+		ResourcesPerCluster:          0,
+		HoursPerMonth:                calculator.DefaultHoursPerMonth,
+		Region:                       "",
+		Currency:                     "",
+		AppTemplates:                 0,
+		ClustersPerTemplate:          0,
+		BasePerHour:                  0,
+		ResourcePerHour:              0,
+		ResourceTiers:                []calculator.RateTier{{From: 0, To: 0, PerHour: 0}},
+		SelfManagedVCPUPerCluster:    0,
+		SelfManagedMemGBPerCluster:   0,
+		SelfManagedVCPUCostPerHour:   0,
+		SelfManagedMemGBCostPerHour:  0,
+		SelfManagedALBsPerCluster:    0,
+		SelfManagedLCUsPerALB:        0,
+		SelfManagedEBSGBPerCluster:   0,
+		SelfManagedLogsGBPerCluster:  0,
+		SelfManagedALBCostPerHour:    0,
+		SelfManagedLCUCostPerHour:    0,
+		SelfManagedEBSCostPerGBMonth: 0,
+		SelfManagedLogsCostPerGB:     0,
+		Tenants:                      []calculator.Tenant{{Name: "", Resources: 0}},
+		Allocation:                   calculator.AllocateEven,
+	}
+}
+
+func TestAPICostBreakdownFields(t *testing.T) {
+	b := calculator.CostBreakdown{}
+	_ = []any{
+		b.TotalResources, b.Currency,
+		b.BaseCapabilityMonthly, b.PerResourceMonthly, b.CapabilitySubtotalMonthly,
+		b.TotalMonthly, b.TotalAnnual,
+		b.SelfManagedComputeMonthly, b.SelfManagedALBMonthly, b.SelfManagedLCUMonthly,
+		b.SelfManagedEBSMonthly, b.SelfManagedLogsMonthly,
+		b.SelfManagedTotalMonthly, b.SelfManagedTotalAnnual, b.ManagedVsSelfManaged,
+		b.PerResourceTiers, b.Chargeback,
+	}
+
+	tc := calculator.TierCost{}
+	_ = []any{tc.Tier, tc.ResourceHours, tc.Monthly}
+
+	c := calculator.TenantCharge{}
+	_ = []any{c.Tenant, c.Resources, c.BaseMonthly, c.ResourceMonthly, c.TotalMonthly, c.Share}
+
+	p := calculator.Capacity{}
+	_ = []any{p.Budget, p.MaxClusters, p.MaxResourcesPerCluster, p.Headroom}
+}
+
+func TestAPIConstantsStable(t *testing.T) {
+	// Enum values are persisted by callers; their order must not change.
+	if calculator.CapabilityArgoCD != 0 || calculator.CapabilityACK != 1 || calculator.CapabilityKro != 2 {
+		t.Error("Capability values changed")
+	}
+	if calculator.AllocateEven != 0 || calculator.AllocateProportional != 1 || calculator.AllocatePlatform != 2 {
+		t.Error("AllocationPolicy values changed")
+	}
+	if calculator.Unlimited != -1 {
+		t.Error("Unlimited changed")
+	}
+	if calculator.PlatformTenant != "platform" {
+		t.Error("PlatformTenant changed")
+	}
+}
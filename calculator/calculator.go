@@ -12,13 +12,25 @@ package calculator
 //     This fee is charged per cluster that has the capability enabled.
 //
 //  3. Per-resource = resource_rate/hr x total_resources x hours_per_month
-//     Each resource instance is billed individually.
+//     Each resource instance is billed individually. With volume tiers, the
+//     total resource-hours (total_resources x hours_per_month) are split
+//     across the tiers and each band is billed at its own rate.
 //
 //  4. Self-managed comparison estimates the compute cost of running the capability yourself:
 //     compute_per_cluster = (vCPU x vCPU_rate + memory_GB x memory_rate)
-//     self_managed_total = compute_per_cluster x hours x clusters
+//     self_managed_compute = compute_per_cluster x hours x clusters
 //     This excludes operational overhead (upgrades, monitoring, HA setup).
 //
+//  5. Ancillary self-managed infrastructure (ArgoCD only):
+//     alb = albs x alb_rate/hr x hours x clusters
+//     lcu = albs x lcus_per_alb x lcu_rate/hr x hours x clusters
+//     ebs = ebs_gb x gb_month_rate x (hours / 730) x clusters
+//     logs = logs_gb x logs_rate/GB x clusters
+//     self_managed_total = self_managed_compute + alb + lcu + ebs + logs
+//
+//  6. Chargeback (optional): when the scenario names tenants, the managed
+//     costs are allocated across them. See Chargeback.
+//
 // EKS cluster costs are excluded — both managed and self-managed assume
 // existing EKS clusters.
 func Calculate(input ScenarioInput) CostBreakdown {
@@ -39,6 +51,14 @@ func Calculate(input ScenarioInput) CostBreakdown {
 	// Managed service costs
 	baseMonthly := input.BasePerHour * hours * float64(input.NumClusters)
 	resourceMonthly := input.ResourcePerHour * float64(totalResources) * hours
+	var tierCosts []TierCost
+	if len(input.ResourceTiers) > 0 {
+		tierCosts = ApplyTiers(input.ResourceTiers, float64(totalResources)*hours)
+		resourceMonthly = 0
+		for _, tc := range tierCosts {
+			resourceMonthly += tc.Monthly
+		}
+	}
 	capabilitySubtotal := baseMonthly + resourceMonthly
 
 	totalMonthly := capabilitySubtotal
@@ -48,18 +68,40 @@ func Calculate(input ScenarioInput) CostBreakdown {
 	computePerCluster := input.SelfManagedVCPUPerCluster*input.SelfManagedVCPUCostPerHour +
 		input.SelfManagedMemGBPerCluster*input.SelfManagedMemGBCostPerHour
 	selfManagedCompute := computePerCluster * hours * float64(input.NumClusters)
-	selfManagedAnnual := selfManagedCompute * 12
 
-	return CostBreakdown{
+	// Ancillary infrastructure only applies to self-hosted ArgoCD
+	var albMonthly, lcuMonthly, ebsMonthly, logsMonthly float64
+	if input.Capability == CapabilityArgoCD {
+		clusters := float64(input.NumClusters)
+		albs := float64(input.SelfManagedALBsPerCluster)
+		albMonthly = albs * input.SelfManagedALBCostPerHour * hours * clusters
+		lcuMonthly = albs * input.SelfManagedLCUsPerALB * input.SelfManagedLCUCostPerHour * hours * clusters
+		ebsMonthly = input.SelfManagedEBSGBPerCluster * input.SelfManagedEBSCostPerGBMonth * (hours / DefaultHoursPerMonth) * clusters
+		logsMonthly = input.SelfManagedLogsGBPerCluster * input.SelfManagedLogsCostPerGB * clusters
+	}
+
+	selfManagedTotal := selfManagedCompute + albMonthly + lcuMonthly + ebsMonthly + logsMonthly
+	selfManagedAnnual := selfManagedTotal * 12
+
+	breakdown := CostBreakdown{
 		TotalResources:            totalResources,
+		Currency:                  input.Currency,
 		BaseCapabilityMonthly:     baseMonthly,
 		PerResourceMonthly:        resourceMonthly,
+		PerResourceTiers:          tierCosts,
 		CapabilitySubtotalMonthly: capabilitySubtotal,
 		TotalMonthly:              totalMonthly,
 		TotalAnnual:               totalAnnual,
 		SelfManagedComputeMonthly: selfManagedCompute,
-		SelfManagedTotalMonthly:   selfManagedCompute,
+		SelfManagedALBMonthly:     albMonthly,
+		SelfManagedLCUMonthly:     lcuMonthly,
+		SelfManagedEBSMonthly:     ebsMonthly,
+		SelfManagedLogsMonthly:    logsMonthly,
+		SelfManagedTotalMonthly:   selfManagedTotal,
 		SelfManagedTotalAnnual:    selfManagedAnnual,
-		ManagedVsSelfManaged:      totalMonthly - selfManagedCompute,
+		ManagedVsSelfManaged:      totalMonthly - selfManagedTotal,
 	}
+	breakdown.Chargeback = Chargeback(input, breakdown)
+
+	return breakdown
 }
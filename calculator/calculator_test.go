@@ -239,3 +239,133 @@ func TestCalculateZeroRates(t *testing.T) {
 		t.Errorf("TotalMonthly: got %.2f, want 0", result.TotalMonthly)
 	}
 }
+
+func TestCalculateAncillaryArgoCD(t *testing.T) {
+	// 2 clusters, 1 ALB each averaging 2 LCUs, 20 GB EBS, 5 GB logs/mo
+	// ALB:  1 x 0.0225 x 730 x 2 = 32.85
+	// LCU:  1 x 2 x 0.008 x 730 x 2 = 23.36
+	// EBS:  20 x 0.08 x 1 x 2 = 3.20
+	// Logs: 5 x 0.50 x 2 = 5.00
+	input := ScenarioInput{
+		Capability:                   CapabilityArgoCD,
+		NumClusters:                  2,
+		HoursPerMonth:                730,
+		SelfManagedALBsPerCluster:    1,
+		SelfManagedLCUsPerALB:        2,
+		SelfManagedEBSGBPerCluster:   20,
+		SelfManagedLogsGBPerCluster:  5,
+		SelfManagedALBCostPerHour:    0.0225,
+		SelfManagedLCUCostPerHour:    0.008,
+		SelfManagedEBSCostPerGBMonth: 0.08,
+		SelfManagedLogsCostPerGB:     0.50,
+	}
+
+	result := Calculate(input)
+
+	if !almostEqual(result.SelfManagedALBMonthly, 32.85) {
+		t.Errorf("SelfManagedALBMonthly: got %.2f, want 32.85", result.SelfManagedALBMonthly)
+	}
+	if !almostEqual(result.SelfManagedLCUMonthly, 23.36) {
+		t.Errorf("SelfManagedLCUMonthly: got %.2f, want 23.36", result.SelfManagedLCUMonthly)
+	}
+	if !almostEqual(result.SelfManagedEBSMonthly, 3.20) {
+		t.Errorf("SelfManagedEBSMonthly: got %.2f, want 3.20", result.SelfManagedEBSMonthly)
+	}
+	if !almostEqual(result.SelfManagedLogsMonthly, 5.00) {
+		t.Errorf("SelfManagedLogsMonthly: got %.2f, want 5.00", result.SelfManagedLogsMonthly)
+	}
+	if !almostEqual(result.SelfManagedTotalMonthly, 64.41) {
+		t.Errorf("SelfManagedTotalMonthly: got %.2f, want 64.41", result.SelfManagedTotalMonthly)
+	}
+	if !almostEqual(result.SelfManagedTotalAnnual, result.SelfManagedTotalMonthly*12) {
+		t.Errorf("SelfManagedTotalAnnual: got %.2f, want %.2f", result.SelfManagedTotalAnnual, result.SelfManagedTotalMonthly*12)
+	}
+	if !almostEqual(result.ManagedVsSelfManaged, -64.41) {
+		t.Errorf("ManagedVsSelfManaged: got %.2f, want -64.41", result.ManagedVsSelfManaged)
+	}
+}
+
+func TestCalculateAncillaryEBSProratedByHours(t *testing.T) {
+	// Half a month of storage: 100 GB x 0.08 x (365 / 730) = 4.00
+	input := ScenarioInput{
+		Capability:                   CapabilityArgoCD,
+		NumClusters:                  1,
+		HoursPerMonth:                365,
+		SelfManagedEBSGBPerCluster:   100,
+		SelfManagedEBSCostPerGBMonth: 0.08,
+	}
+
+	result := Calculate(input)
+
+	if !almostEqual(result.SelfManagedEBSMonthly, 4.00) {
+		t.Errorf("SelfManagedEBSMonthly: got %.2f, want 4.00", result.SelfManagedEBSMonthly)
+	}
+}
+
+func TestCalculateAncillaryIgnoredForACK(t *testing.T) {
+	input := ScenarioInput{
+		Capability:                   CapabilityACK,
+		NumClusters:                  2,
+		HoursPerMonth:                730,
+		SelfManagedALBsPerCluster:    1,
+		SelfManagedLCUsPerALB:        2,
+		SelfManagedEBSGBPerCluster:   20,
+		SelfManagedLogsGBPerCluster:  5,
+		SelfManagedALBCostPerHour:    0.0225,
+		SelfManagedLCUCostPerHour:    0.008,
+		SelfManagedEBSCostPerGBMonth: 0.08,
+		SelfManagedLogsCostPerGB:     0.50,
+	}
+
+	result := Calculate(input)
+
+	if result.SelfManagedTotalMonthly != 0 {
+		t.Errorf("SelfManagedTotalMonthly: got %.2f, want 0 (ACK ignores ancillary)", result.SelfManagedTotalMonthly)
+	}
+}
+
+func TestCalculateResourceTiers(t *testing.T) {
+	// 10 clusters x 100 resources x 730h = 730,000 resource-hours:
+	// 500,000 at $0.002 = $1,000 and 230,000 at $0.001 = $230.
+	input := ScenarioInput{
+		Capability:          CapabilityACK,
+		NumClusters:         10,
+		ResourcesPerCluster: 100,
+		HoursPerMonth:       730,
+		ResourcePerHour:     0.002,
+		ResourceTiers: []RateTier{
+			{From: 0, To: 500_000, PerHour: 0.002},
+			{From: 500_000, PerHour: 0.001},
+		},
+	}
+
+	result := Calculate(input)
+
+	if len(result.PerResourceTiers) != 2 {
+		t.Fatalf("PerResourceTiers: got %d, want 2", len(result.PerResourceTiers))
+	}
+	if !almostEqual(result.PerResourceTiers[0].Monthly, 1000) || !almostEqual(result.PerResourceTiers[1].Monthly, 230) {
+		t.Errorf("tier costs: got %+v", result.PerResourceTiers)
+	}
+	if !almostEqual(result.PerResourceMonthly, 1230) {
+		t.Errorf("PerResourceMonthly: got %.2f, want 1230.00", result.PerResourceMonthly)
+	}
+	if !almostEqual(result.TotalMonthly, 1230) {
+		t.Errorf("TotalMonthly: got %.2f, want 1230.00", result.TotalMonthly)
+	}
+}
+
+func TestCalculateFlatRateHasNoTiers(t *testing.T) {
+	result := Calculate(DefaultInput(CapabilityArgoCD))
+	if result.PerResourceTiers != nil {
+		t.Errorf("expected no tiers for a flat rate, got %+v", result.PerResourceTiers)
+	}
+}
+
+func TestCalculateCurrency(t *testing.T) {
+	input := DefaultInput(CapabilityArgoCD)
+	input.Currency = "CNY"
+	if got := Calculate(input).Currency; got != "CNY" {
+		t.Errorf("Currency: got %q, want CNY", got)
+	}
+}
@@ -0,0 +1,124 @@
+package calculator
+
+// PlatformTenant is the name under which unassigned resources and any base
+// capability fees held by the platform team are reported.
+const PlatformTenant = "platform"
+
+// AllocationPolicy determines how base capability fees are split across tenants.
+type AllocationPolicy int
+
+const (
+	// AllocateEven splits base fees equally across all named tenants.
+	AllocateEven AllocationPolicy = iota
+	// AllocateProportional splits base fees by each tenant's share of resources.
+	AllocateProportional
+	// AllocatePlatform assigns all base fees to the platform team.
+	AllocatePlatform
+)
+
+// String returns the display name for the allocation policy.
+func (p AllocationPolicy) String() string {
+	switch p {
+	case AllocateEven:
+		return "Even split"
+	case AllocateProportional:
+		return "Proportional"
+	case AllocatePlatform:
+		return "Platform team"
+	default:
+		return "Unknown"
+	}
+}
+
+// AllAllocationPolicies returns all supported allocation policies.
+var AllAllocationPolicies = []AllocationPolicy{AllocateEven, AllocateProportional, AllocatePlatform}
+
+// Tenant is a named team or tenant that owns a share of a scenario's
+// billable resources (Applications, ACK resources or RGD instances).
+type Tenant struct {
+	Name      string
+	Resources int
+}
+
+// TenantCharge holds the monthly chargeback for a single tenant.
+type TenantCharge struct {
+	Tenant          string
+	Resources       int
+	BaseMonthly     float64
+	ResourceMonthly float64
+	TotalMonthly    float64
+	Share           float64 // fraction of the managed monthly total, 0-1
+}
+
+// Chargeback allocates a scenario's managed costs across its tenants.
+//
+// Per-resource fees follow resource ownership: each tenant pays for the
+// resources it owns, and unassigned resources are charged to the platform
+// team. If tenants claim more resources than the scenario has, the
+// per-resource fee is split proportionally so the report still reconciles
+// with the breakdown. Under volume tiers every resource is charged the
+// scenario's average per-resource rate. Base capability fees are split using input.Allocation.
+//
+// The platform row is only included when it carries a cost or resources.
+// Returns nil when the scenario has no tenants.
+func Chargeback(input ScenarioInput, breakdown CostBreakdown) []TenantCharge {
+	tenants := input.Tenants
+	if len(tenants) == 0 {
+		return nil
+	}
+
+	assigned := 0
+	for _, t := range tenants {
+		assigned += t.Resources
+	}
+
+	unassigned := breakdown.TotalResources - assigned
+	if unassigned < 0 {
+		unassigned = 0
+	}
+
+	// Resources that share the per-resource fee: either the scenario total or,
+	// when over-assigned, everything the tenants claimed.
+	billable := assigned + unassigned
+
+	charges := make([]TenantCharge, 0, len(tenants)+1)
+	for _, t := range tenants {
+		c := TenantCharge{Tenant: t.Name, Resources: t.Resources}
+		if billable > 0 {
+			c.ResourceMonthly = breakdown.PerResourceMonthly * float64(t.Resources) / float64(billable)
+		}
+
+		switch input.Allocation {
+		case AllocateEven:
+			c.BaseMonthly = breakdown.BaseCapabilityMonthly / float64(len(tenants))
+		case AllocateProportional:
+			if assigned > 0 {
+				c.BaseMonthly = breakdown.BaseCapabilityMonthly * float64(t.Resources) / float64(assigned)
+			} else {
+				c.BaseMonthly = breakdown.BaseCapabilityMonthly / float64(len(tenants))
+			}
+		}
+
+		charges = append(charges, c)
+	}
+
+	platform := TenantCharge{Tenant: PlatformTenant, Resources: unassigned}
+	if billable > 0 {
+		platform.ResourceMonthly = breakdown.PerResourceMonthly * float64(unassigned) / float64(billable)
+	}
+	if input.Allocation == AllocatePlatform {
+		platform.BaseMonthly = breakdown.BaseCapabilityMonthly
+	}
+	if platform.Resources > 0 || platform.BaseMonthly > 0 || platform.ResourceMonthly > 0 {
+		charges = append(charges, platform)
+	}
+
+	for i := range charges {
+		charges[i].TotalMonthly = charges[i].BaseMonthly + charges[i].ResourceMonthly
+		if breakdown.TotalMonthly > 0 {
+			charges[i].Share = charges[i].TotalMonthly / breakdown.TotalMonthly
+		}
+	}
+
+	return charges
+}
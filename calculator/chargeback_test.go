@@ -0,0 +1,194 @@
+package calculator
+
+import "testing"
+
+func chargebackInput(tenants []Tenant, policy AllocationPolicy) ScenarioInput {
+	// 2 clusters x 10 apps = 20 resources
+	// Base: 0.03 x 730 x 2 = 43.80
+	// Apps: 0.0015 x 20 x 730 = 21.90
+	return ScenarioInput{
+		Capability:          CapabilityArgoCD,
+		NumClusters:         2,
+		ResourcesPerCluster: 10,
+		HoursPerMonth:       730,
+		BasePerHour:         0.03,
+		ResourcePerHour:     0.0015,
+		Tenants:             tenants,
+		Allocation:          policy,
+	}
+}
+
+func findCharge(t *testing.T, charges []TenantCharge, name string) TenantCharge {
+	t.Helper()
+	for _, c := range charges {
+		if c.Tenant == name {
+			return c
+		}
+	}
+	t.Fatalf("no charge for tenant %q in %+v", name, charges)
+	return TenantCharge{}
+}
+
+func sumCharges(charges []TenantCharge) float64 {
+	total := 0.0
+	for _, c := range charges {
+		total += c.TotalMonthly
+	}
+	return total
+}
+
+func TestChargebackNoTenants(t *testing.T) {
+	result := Calculate(chargebackInput(nil, AllocateEven))
+	if result.Chargeback != nil {
+		t.Errorf("expected nil chargeback without tenants, got %+v", result.Chargeback)
+	}
+}
+
+func TestChargebackEvenSplit(t *testing.T) {
+	tenants := []Tenant{{"payments", 15}, {"search", 5}}
+	result := Calculate(chargebackInput(tenants, AllocateEven))
+
+	if len(result.Chargeback) != 2 {
+		t.Fatalf("expected 2 charges (no platform remainder), got %d", len(result.Chargeback))
+	}
+
+	payments := findCharge(t, result.Chargeback, "payments")
+	if !almostEqual(payments.BaseMonthly, 21.90) {
+		t.Errorf("payments base: got %.2f, want 21.90", payments.BaseMonthly)
+	}
+	// 15/20 of 21.90
+	if !almostEqual(payments.ResourceMonthly, 16.43) {
+		t.Errorf("payments per-resource: got %.2f, want 16.43", payments.ResourceMonthly)
+	}
+
+	search := findCharge(t, result.Chargeback, "search")
+	if !almostEqual(search.BaseMonthly, 21.90) {
+		t.Errorf("search base: got %.2f, want 21.90", search.BaseMonthly)
+	}
+	if !almostEqual(search.TotalMonthly, 21.90+5.475) {
+		t.Errorf("search total: got %.2f, want 27.38", search.TotalMonthly)
+	}
+
+	if !almostEqual(sumCharges(result.Chargeback), result.TotalMonthly) {
+		t.Errorf("charges %.2f do not reconcile with total %.2f", sumCharges(result.Chargeback), result.TotalMonthly)
+	}
+}
+
+func TestChargebackProportional(t *testing.T) {
+	tenants := []Tenant{{"payments", 15}, {"search", 5}}
+	result := Calculate(chargebackInput(tenants, AllocateProportional))
+
+	payments := findCharge(t, result.Chargeback, "payments")
+	// 15/20 of 43.80
+	if !almostEqual(payments.BaseMonthly, 32.85) {
+		t.Errorf("payments base: got %.2f, want 32.85", payments.BaseMonthly)
+	}
+	if !almostEqual(payments.Share, 0.75) {
+		t.Errorf("payments share: got %.2f, want 0.75", payments.Share)
+	}
+}
+
+func TestChargebackProportionalNoResources(t *testing.T) {
+	tenants := []Tenant{{"a", 0}, {"b", 0}}
+	result := Calculate(chargebackInput(tenants, AllocateProportional))
+
+	a := findCharge(t, result.Chargeback, "a")
+	if !almostEqual(a.BaseMonthly, 21.90) {
+		t.Errorf("base should fall back to an even split, got %.2f", a.BaseMonthly)
+	}
+
+	// All 20 resources are unassigned and charged to the platform
+	platform := findCharge(t, result.Chargeback, PlatformTenant)
+	if platform.Resources != 20 {
+		t.Errorf("platform resources: got %d, want 20", platform.Resources)
+	}
+	if !almostEqual(platform.ResourceMonthly, 21.90) {
+		t.Errorf("platform per-resource: got %.2f, want 21.90", platform.ResourceMonthly)
+	}
+}
+
+func TestChargebackPlatformPolicy(t *testing.T) {
+	tenants := []Tenant{{"payments", 20}}
+	result := Calculate(chargebackInput(tenants, AllocatePlatform))
+
+	payments := findCharge(t, result.Chargeback, "payments")
+	if payments.BaseMonthly != 0 {
+		t.Errorf("payments base: got %.2f, want 0", payments.BaseMonthly)
+	}
+
+	platform := findCharge(t, result.Chargeback, PlatformTenant)
+	if !almostEqual(platform.BaseMonthly, 43.80) {
+		t.Errorf("platform base: got %.2f, want 43.80", platform.BaseMonthly)
+	}
+	if platform.Resources != 0 {
+		t.Errorf("platform resources: got %d, want 0", platform.Resources)
+	}
+	if !almostEqual(sumCharges(result.Chargeback), result.TotalMonthly) {
+		t.Errorf("charges %.2f do not reconcile with total %.2f", sumCharges(result.Chargeback), result.TotalMonthly)
+	}
+}
+
+func TestChargebackUnassignedRemainder(t *testing.T) {
+	tenants := []Tenant{{"payments", 5}}
+	result := Calculate(chargebackInput(tenants, AllocateEven))
+
+	platform := findCharge(t, result.Chargeback, PlatformTenant)
+	if platform.Resources != 15 {
+		t.Errorf("platform resources: got %d, want 15", platform.Resources)
+	}
+	if platform.BaseMonthly != 0 {
+		t.Errorf("platform base: got %.2f, want 0 under even split", platform.BaseMonthly)
+	}
+	if !almostEqual(sumCharges(result.Chargeback), result.TotalMonthly) {
+		t.Errorf("charges %.2f do not reconcile with total %.2f", sumCharges(result.Chargeback), result.TotalMonthly)
+	}
+}
+
+func TestChargebackOverAssigned(t *testing.T) {
+	// Tenants claim 40 resources but the scenario only has 20
+	tenants := []Tenant{{"a", 30}, {"b", 10}}
+	result := Calculate(chargebackInput(tenants, AllocateEven))
+
+	if len(result.Chargeback) != 2 {
+		t.Fatalf("expected no platform row when over-assigned, got %+v", result.Chargeback)
+	}
+	a := findCharge(t, result.Chargeback, "a")
+	// 30/40 of 21.90
+	if !almostEqual(a.ResourceMonthly, 16.43) {
+		t.Errorf("a per-resource: got %.2f, want 16.43", a.ResourceMonthly)
+	}
+	if !almostEqual(sumCharges(result.Chargeback), result.TotalMonthly) {
+		t.Errorf("charges %.2f do not reconcile with total %.2f", sumCharges(result.Chargeback), result.TotalMonthly)
+	}
+}
+
+func TestChargebackZeroTotal(t *testing.T) {
+	input := chargebackInput([]Tenant{{"a", 0}}, AllocateEven)
+	input.NumClusters = 0
+	result := Calculate(input)
+
+	a := findCharge(t, result.Chargeback, "a")
+	if a.TotalMonthly != 0 || a.Share != 0 {
+		t.Errorf("expected zero charge, got %+v", a)
+	}
+}
+
+func TestAllocationPolicyString(t *testing.T) {
+	tests := []struct {
+		policy AllocationPolicy
+		want   string
+	}{
+		{AllocateEven, "Even split"},
+		{AllocateProportional, "Proportional"},
+		{AllocatePlatform, "Platform team"},
+		{AllocationPolicy(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.String(); got != tt.want {
+			t.Errorf("AllocationPolicy(%d).String(): got %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+	if len(AllAllocationPolicies) != 3 {
+		t.Errorf("expected 3 allocation policies, got %d", len(AllAllocationPolicies))
+	}
+}
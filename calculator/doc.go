@@ -0,0 +1,23 @@
+// Package calculator computes the monthly cost of AWS EKS Capabilities
+// (ArgoCD, ACK and kro) and compares it with self-managing the same
+// controllers.
+//
+// Build a ScenarioInput, usually starting from DefaultInput, fill in the
+// hourly rates (see the pricing package) and pass it to Calculate:
+//
+//	input := calculator.DefaultInput(calculator.CapabilityArgoCD)
+//	input.NumClusters = 3
+//	input.BasePerHour, input.ResourcePerHour = rates.ForCapability(input.Capability)
+//	breakdown := calculator.Calculate(input)
+//
+// Chargeback splits a breakdown across tenants and Plan answers the inverse
+// question of how much capacity a budget buys.
+//
+// # Compatibility
+//
+// This package follows semantic versioning. Within a major version exported
+// functions keep their signatures, and exported types and constants are not
+// removed or renamed. New fields may be added to structs, so construct them
+// with field names. The formulas may change when AWS changes how a
+// capability is billed.
+package calculator
@@ -0,0 +1,61 @@
+package calculator_test
+
+import (
+	"fmt"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+)
+
+func ExampleCalculate() {
+	input := calculator.DefaultInput(calculator.CapabilityArgoCD)
+	input.NumClusters = 3
+	input.ResourcesPerCluster = 10
+	input.BasePerHour = 0.03
+	input.ResourcePerHour = 0.0015
+
+	b := calculator.Calculate(input)
+	fmt.Printf("resources: %d\n", b.TotalResources)
+	fmt.Printf("monthly:   %.2f\n", b.TotalMonthly)
+	fmt.Printf("annual:    %.2f\n", b.TotalAnnual)
+	// Output:
+	// resources: 30
+	// monthly:   98.55
+	// annual:    1182.60
+}
+
+func ExampleChargeback() {
+	input := calculator.DefaultInput(calculator.CapabilityArgoCD)
+	input.NumClusters = 1
+	input.ResourcesPerCluster = 10
+	input.BasePerHour = 0.03
+	input.ResourcePerHour = 0.0015
+	input.Allocation = calculator.AllocateEven
+	input.Tenants = []calculator.Tenant{
+		{Name: "payments", Resources: 6},
+		{Name: "search", Resources: 4},
+	}
+
+	for _, c := range calculator.Calculate(input).Chargeback {
+		fmt.Printf("%-8s %.2f\n", c.Tenant, c.TotalMonthly)
+	}
+	// Output:
+	// payments 17.52
+	// search   15.33
+}
+
+func ExamplePlan() {
+	input := calculator.DefaultInput(calculator.CapabilityArgoCD)
+	input.NumClusters = 2
+	input.ResourcesPerCluster = 10
+	input.BasePerHour = 0.03
+	input.ResourcePerHour = 0.0015
+
+	c := calculator.Plan(input, 100)
+	fmt.Println("max clusters:", c.MaxClusters)
+	fmt.Println("max resources/cluster:", c.MaxResourcesPerCluster)
+	fmt.Println("fits:", c.Fits())
+	// Output:
+	// max clusters: 3
+	// max resources/cluster: 25
+	// fits: true
+}
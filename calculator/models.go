@@ -0,0 +1,139 @@
+package calculator
+
+// Capability represents an EKS capability type.
+type Capability int
+
+const (
+	CapabilityArgoCD Capability = iota
+	CapabilityACK
+	CapabilityKro
+)
+
+// String returns the display name for the capability.
+func (c Capability) String() string {
+	switch c {
+	case CapabilityArgoCD:
+		return "ArgoCD"
+	case CapabilityACK:
+		return "ACK"
+	case CapabilityKro:
+		return "kro"
+	default:
+		return "Unknown"
+	}
+}
+
+// AllCapabilities returns all supported capabilities.
+var AllCapabilities = []Capability{CapabilityArgoCD, CapabilityACK, CapabilityKro}
+
+// ScenarioInput holds all user-configurable inputs for a cost scenario.
+type ScenarioInput struct {
+	Name        string
+	Capability  Capability
+	NumClusters int
+	// ResourcesPerCluster is the number of billable resources per cluster
+	// (Applications for ArgoCD, managed AWS resources for ACK, RGD instances for kro).
+	ResourcesPerCluster int
+	HoursPerMonth       float64
+
+	// AWS region code for pricing lookup (default: "us-east-1").
+	Region string
+
+	// Currency is the ISO 4217 code of every rate and cost below; empty
+	// means USD. Rates for AWS China regions are published in CNY.
+	Currency string
+
+	// Capability rates (fetched from AWS Pricing API or hardcoded defaults).
+	BasePerHour     float64
+	ResourcePerHour float64
+
+	// ResourceTiers, when set, replaces ResourcePerHour with graduated
+	// volume tiers applied to the scenario's total resource-hours.
+	ResourceTiers []RateTier
+
+	// ApplicationSet expansion (ArgoCD-only): each template generates one Application per target cluster.
+	AppTemplates        int
+	ClustersPerTemplate int
+
+	// Self-managed comparison inputs.
+	// Default resources based on ArgoCD recommended requests for core components
+	// (server: 125m/128Mi, repo-server: 250m/256Mi, application-controller: 250m/1Gi).
+	// Default rates use EKS Fargate pricing for us-east-1 Linux/X86:
+	// vCPU: $0.000011244/sec = $0.04048/hr, GB: $0.000001235/sec = $0.004446/hr
+	SelfManagedVCPUPerCluster   float64
+	SelfManagedMemGBPerCluster  float64
+	SelfManagedVCPUCostPerHour  float64
+	SelfManagedMemGBCostPerHour float64
+
+	// Ancillary self-managed infrastructure (ArgoCD-only, optional).
+	// A self-hosted ArgoCD usually fronts its UI/API with a load balancer,
+	// persists redis state on EBS and ships logs to CloudWatch. Zero
+	// quantities leave the component out of the comparison.
+	SelfManagedALBsPerCluster    int
+	SelfManagedLCUsPerALB        float64
+	SelfManagedEBSGBPerCluster   float64
+	SelfManagedLogsGBPerCluster  float64 // log ingestion per cluster per month
+	SelfManagedALBCostPerHour    float64
+	SelfManagedLCUCostPerHour    float64
+	SelfManagedEBSCostPerGBMonth float64
+	SelfManagedLogsCostPerGB     float64
+
+	// Chargeback: optional split of the billable resources across named
+	// tenants or teams, and how base capability fees are allocated to them.
+	Tenants    []Tenant
+	Allocation AllocationPolicy
+}
+
+// DefaultInput returns a ScenarioInput with sensible defaults for the given capability.
+func DefaultInput(cap Capability) ScenarioInput {
+	return ScenarioInput{
+		Name:                         "Custom",
+		Capability:                   cap,
+		NumClusters:                  1,
+		ResourcesPerCluster:          5,
+		HoursPerMonth:                DefaultHoursPerMonth,
+		Region:                       "us-east-1",
+		SelfManagedVCPUPerCluster:    1.0,
+		SelfManagedMemGBPerCluster:   2.0,
+		SelfManagedVCPUCostPerHour:   0.04048,
+		SelfManagedMemGBCostPerHour:  0.004446,
+		SelfManagedALBCostPerHour:    0.0225,
+		SelfManagedLCUCostPerHour:    0.008,
+		SelfManagedEBSCostPerGBMonth: 0.08,
+		SelfManagedLogsCostPerGB:     0.50,
+	}
+}
+
+// CostBreakdown holds the calculated cost breakdown for a scenario.
+type CostBreakdown struct {
+	TotalResources int
+
+	// Currency of the costs, copied from ScenarioInput.Currency.
+	Currency string
+
+	// Capability managed service costs.
+	BaseCapabilityMonthly     float64
+	PerResourceMonthly        float64
+	CapabilitySubtotalMonthly float64
+
+	// Per-resource cost of each tier when the scenario has ResourceTiers;
+	// nil otherwise. The tiers sum to PerResourceMonthly.
+	PerResourceTiers []TierCost
+
+	// Totals (managed only, assumes existing EKS clusters).
+	TotalMonthly float64
+	TotalAnnual  float64
+
+	// Self-managed comparison.
+	SelfManagedComputeMonthly float64 // compute cost for pods
+	SelfManagedALBMonthly     float64 // load balancer hours (ArgoCD only)
+	SelfManagedLCUMonthly     float64 // load balancer capacity units (ArgoCD only)
+	SelfManagedEBSMonthly     float64 // persistent storage (ArgoCD only)
+	SelfManagedLogsMonthly    float64 // log ingestion (ArgoCD only)
+	SelfManagedTotalMonthly   float64 // compute + ancillary (assumes existing EKS clusters)
+	SelfManagedTotalAnnual    float64
+	ManagedVsSelfManaged      float64 // positive means managed costs more
+
+	// Per-tenant allocation of the managed costs; nil without tenants.
+	Chargeback []TenantCharge
+}
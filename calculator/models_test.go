@@ -32,6 +32,15 @@ func TestDefaultInput(t *testing.T) {
 	if d.SelfManagedMemGBCostPerHour != 0.004446 {
 		t.Errorf("SelfManagedMemGBCostPerHour: got %f, want 0.004446", d.SelfManagedMemGBCostPerHour)
 	}
+	if d.SelfManagedALBsPerCluster != 0 {
+		t.Errorf("SelfManagedALBsPerCluster: got %d, want 0 (ancillary is opt-in)", d.SelfManagedALBsPerCluster)
+	}
+	if d.SelfManagedALBCostPerHour != 0.0225 {
+		t.Errorf("SelfManagedALBCostPerHour: got %f, want 0.0225", d.SelfManagedALBCostPerHour)
+	}
+	if d.SelfManagedLogsCostPerGB != 0.50 {
+		t.Errorf("SelfManagedLogsCostPerGB: got %f, want 0.50", d.SelfManagedLogsCostPerGB)
+	}
 	if d.Region != "us-east-1" {
 		t.Errorf("Region: got %q, want %q", d.Region, "us-east-1")
 	}
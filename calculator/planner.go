@@ -0,0 +1,83 @@
+package calculator
+
+// Unlimited is reported by Plan when a dimension can grow without ever
+// exceeding the budget (for example when its rate is zero).
+const Unlimited = -1
+
+// planSearchLimit bounds the capacity search. Anything that still fits the
+// budget at this size is reported as Unlimited.
+const planSearchLimit = 10_000_000
+
+// Capacity describes how much a monthly budget buys for a scenario.
+type Capacity struct {
+	Budget float64
+
+	// MaxClusters is the most clusters that fit the budget with
+	// ResourcesPerCluster held fixed.
+	MaxClusters int
+
+	// MaxResourcesPerCluster is the most resources per cluster that fit the
+	// budget with NumClusters held fixed. Zero if the base fee alone for
+	// NumClusters already exceeds the budget.
+	MaxResourcesPerCluster int
+
+	// Headroom is the budget left over (negative if over budget) for the
+	// scenario as entered.
+	Headroom float64
+}
+
+// Fits reports whether the scenario as entered is within budget.
+func (c Capacity) Fits() bool {
+	return c.Headroom >= 0
+}
+
+// Plan is the inverse of Calculate: given a monthly budget for the managed
+// capability, it finds the maximum clusters and resources per cluster that
+// fit, holding the other inputs fixed.
+//
+// The search evaluates Calculate directly rather than inverting the formula,
+// so it stays correct for any pricing Calculate supports. Monthly cost is
+// monotonic in both dimensions, which allows a bounded binary search.
+func Plan(input ScenarioInput, budget float64) Capacity {
+	withClusters := func(n int) float64 {
+		in := input
+		in.NumClusters = n
+		return Calculate(in).TotalMonthly
+	}
+	withResources := func(r int) float64 {
+		in := input
+		in.ResourcesPerCluster = r
+		return Calculate(in).TotalMonthly
+	}
+
+	return Capacity{
+		Budget:                 budget,
+		MaxClusters:            maxWithinBudget(withClusters, budget),
+		MaxResourcesPerCluster: maxWithinBudget(withResources, budget),
+		Headroom:               budget - Calculate(input).TotalMonthly,
+	}
+}
+
+// maxWithinBudget returns the largest n in [0, planSearchLimit] for which
+// cost(n) <= budget, Unlimited if even planSearchLimit fits, or 0 if nothing
+// does. cost must be non-decreasing in n.
+func maxWithinBudget(cost func(int) float64, budget float64) int {
+	if cost(planSearchLimit) <= budget {
+		return Unlimited
+	}
+	if cost(0) > budget {
+		return 0
+	}
+
+	lo, hi := 0, planSearchLimit // cost(lo) fits, cost(hi) does not
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if cost(mid) <= budget {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo
+}
@@ -0,0 +1,114 @@
+package calculator
+
+import "testing"
+
+func planInput() ScenarioInput {
+	// Per cluster: base 0.03 x 730 = 21.90, 10 apps x 0.0015 x 730 = 10.95
+	return ScenarioInput{
+		Capability:          CapabilityArgoCD,
+		NumClusters:         2,
+		ResourcesPerCluster: 10,
+		HoursPerMonth:       730,
+		BasePerHour:         0.03,
+		ResourcePerHour:     0.0015,
+	}
+}
+
+func TestPlanMaxClusters(t *testing.T) {
+	// Each cluster costs 32.85/mo: 100 / 32.85 = 3.04 -> 3 clusters
+	c := Plan(planInput(), 100)
+
+	if c.MaxClusters != 3 {
+		t.Errorf("MaxClusters: got %d, want 3", c.MaxClusters)
+	}
+	if c.Budget != 100 {
+		t.Errorf("Budget: got %.2f, want 100", c.Budget)
+	}
+}
+
+func TestPlanMaxResourcesPerCluster(t *testing.T) {
+	// 2 clusters: base 43.80, leaves 56.20 for apps
+	// 56.20 / (0.0015 x 730 x 2) = 25.66 -> 25 apps/cluster
+	c := Plan(planInput(), 100)
+
+	if c.MaxResourcesPerCluster != 25 {
+		t.Errorf("MaxResourcesPerCluster: got %d, want 25", c.MaxResourcesPerCluster)
+	}
+}
+
+func TestPlanHeadroom(t *testing.T) {
+	// Scenario costs 65.70/mo
+	c := Plan(planInput(), 100)
+	if !almostEqual(c.Headroom, 34.30) {
+		t.Errorf("Headroom: got %.2f, want 34.30", c.Headroom)
+	}
+	if !c.Fits() {
+		t.Error("scenario should fit a 100/mo budget")
+	}
+
+	c = Plan(planInput(), 50)
+	if c.Fits() {
+		t.Error("scenario should not fit a 50/mo budget")
+	}
+}
+
+func TestPlanBaseExceedsBudget(t *testing.T) {
+	// Base fee for 2 clusters (43.80) already exceeds the budget
+	c := Plan(planInput(), 40)
+
+	if c.MaxResourcesPerCluster != 0 {
+		t.Errorf("MaxResourcesPerCluster: got %d, want 0", c.MaxResourcesPerCluster)
+	}
+	if c.MaxClusters != 1 {
+		t.Errorf("MaxClusters: got %d, want 1", c.MaxClusters)
+	}
+}
+
+func TestPlanIncludesApplicationSets(t *testing.T) {
+	// 3 templates x 4 clusters = 12 appset apps = 13.14/mo regardless of clusters
+	input := planInput()
+	input.AppTemplates = 3
+	input.ClustersPerTemplate = 4
+
+	// (100 - 13.14) / 32.85 = 2.64 -> 2 clusters
+	c := Plan(input, 100)
+	if c.MaxClusters != 2 {
+		t.Errorf("MaxClusters: got %d, want 2", c.MaxClusters)
+	}
+}
+
+func TestPlanUnlimited(t *testing.T) {
+	input := planInput()
+	input.ResourcePerHour = 0
+
+	c := Plan(input, 100)
+	if c.MaxResourcesPerCluster != Unlimited {
+		t.Errorf("MaxResourcesPerCluster: got %d, want Unlimited", c.MaxResourcesPerCluster)
+	}
+
+	input.BasePerHour = 0
+	c = Plan(input, 0)
+	if c.MaxClusters != Unlimited {
+		t.Errorf("MaxClusters: got %d, want Unlimited", c.MaxClusters)
+	}
+}
+
+func TestPlanZeroBudget(t *testing.T) {
+	c := Plan(planInput(), 0)
+	if c.MaxClusters != 0 {
+		t.Errorf("MaxClusters: got %d, want 0", c.MaxClusters)
+	}
+	if c.MaxResourcesPerCluster != 0 {
+		t.Errorf("MaxResourcesPerCluster: got %d, want 0", c.MaxResourcesPerCluster)
+	}
+}
+
+func TestMaxWithinBudgetNothingFits(t *testing.T) {
+	cost := func(n int) float64 { return 10 + float64(n) }
+	if got := maxWithinBudget(cost, 5); got != 0 {
+		t.Errorf("got %d, want 0", got)
+	}
+	if got := maxWithinBudget(cost, 15.5); got != 5 {
+		t.Errorf("got %d, want 5", got)
+	}
+}
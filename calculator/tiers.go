@@ -0,0 +1,40 @@
+package calculator
+
+import "math"
+
+// RateTier is one band of a graduated per-resource rate. Usage between From
+// and To is charged at PerHour; usage beyond To falls into the next tier.
+// Ranges count resource-hours per month, the unit AWS price dimensions are
+// metered in.
+type RateTier struct {
+	From    float64
+	To      float64 // zero means no upper bound
+	PerHour float64
+}
+
+// TierCost is the share of the per-resource cost that falls within one tier.
+type TierCost struct {
+	Tier          RateTier
+	ResourceHours float64
+	Monthly       float64
+}
+
+// ApplyTiers splits usage (in resource-hours) across graduated tiers and
+// returns the cost accrued in each, in tier order. Tiers must be sorted by
+// From; usage beyond the last tier's upper bound is charged at its rate.
+// Every tier is returned, with zero usage for the ones not reached.
+func ApplyTiers(tiers []RateTier, usage float64) []TierCost {
+	costs := make([]TierCost, len(tiers))
+	for i, t := range tiers {
+		upper := t.To
+		if upper <= 0 || i == len(tiers)-1 {
+			upper = math.Inf(1)
+		}
+		hours := math.Min(usage, upper) - t.From
+		if hours < 0 {
+			hours = 0
+		}
+		costs[i] = TierCost{Tier: t, ResourceHours: hours, Monthly: hours * t.PerHour}
+	}
+	return costs
+}
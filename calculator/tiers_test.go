@@ -0,0 +1,48 @@
+package calculator
+
+import "testing"
+
+func TestApplyTiers(t *testing.T) {
+	tiers := []RateTier{
+		{From: 0, To: 1000, PerHour: 0.002},
+		{From: 1000, To: 5000, PerHour: 0.001},
+		{From: 5000, PerHour: 0.0005},
+	}
+
+	tests := []struct {
+		usage float64
+		hours []float64
+	}{
+		{0, []float64{0, 0, 0}},
+		{600, []float64{600, 0, 0}},
+		{1000, []float64{1000, 0, 0}},
+		{3000, []float64{1000, 2000, 0}},
+		{9000, []float64{1000, 4000, 4000}},
+	}
+	for _, tt := range tests {
+		costs := ApplyTiers(tiers, tt.usage)
+		if len(costs) != len(tiers) {
+			t.Fatalf("usage %.0f: got %d tiers, want %d", tt.usage, len(costs), len(tiers))
+		}
+		for i, c := range costs {
+			if c.ResourceHours != tt.hours[i] {
+				t.Errorf("usage %.0f tier %d: got %.0f hours, want %.0f", tt.usage, i, c.ResourceHours, tt.hours[i])
+			}
+			if !almostEqual(c.Monthly, tt.hours[i]*tiers[i].PerHour) {
+				t.Errorf("usage %.0f tier %d: got $%.2f", tt.usage, i, c.Monthly)
+			}
+			if c.Tier != tiers[i] {
+				t.Errorf("usage %.0f tier %d: got tier %+v", tt.usage, i, c.Tier)
+			}
+		}
+	}
+}
+
+func TestApplyTiersBoundedLastTier(t *testing.T) {
+	// Usage past the last published bound is charged at the last rate.
+	tiers := []RateTier{{From: 0, To: 100, PerHour: 1}, {From: 100, To: 200, PerHour: 0.5}}
+	costs := ApplyTiers(tiers, 500)
+	if costs[0].ResourceHours != 100 || costs[1].ResourceHours != 400 {
+		t.Errorf("unexpected split %+v", costs)
+	}
+}
@@ -0,0 +1,35 @@
+package currency_test
+
+import (
+	"testing"
+
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+)
+
+// These assignments fail to compile if a public signature changes. Update
+// them only together with a major version bump.
+var (
+	_ func() currency.Formatter                                                    = currency.USD
+	_ func(string, string, currency.Rates) (currency.Formatter, error)             = currency.New
+	_ func(string) (currency.Rates, error)                                         = currency.LoadRates
+	_ func(currency.Rates, map[string]float64) currency.Rates                      = currency.Rates.Merge
+	_ func(currency.Rates) []string                                                = currency.Rates.Codes
+	_ func(currency.Formatter, float64) string                                     = currency.Formatter.Format
+	_ func(currency.Formatter, float64) string                                     = currency.Formatter.FormatSigned
+	_ func(currency.Formatter, float64, int) string                                = currency.Formatter.FormatRate
+	_ func(currency.Formatter, float64) float64                                    = currency.Formatter.Convert
+	_ func(currency.Formatter, float64) float64                                    = currency.Formatter.ToUSD
+	_ func(currency.Formatter, float64) float64                                    = currency.Formatter.ToBase
+	_ func(currency.Formatter, string, currency.Rates) (currency.Formatter, error) = currency.Formatter.WithBase
+	_ func(currency.Formatter) string                                              = currency.Formatter.Symbol
+)
+
+func TestAPIFields(t *testing.T) {
+	_ = currency.Formatter{Code: "", Rate: 0, AsOf: "", Locale: currency.Locale{}, Base: "", BaseRate: 0}
+	_ = currency.Locale{Name: "", Group: "", Decimal: "", SymbolAfter: false, Space: false}
+	_ = currency.Rates{AsOf: "", Rates: nil}
+
+	if currency.BaseCurrency != "USD" || currency.RatesFile != "exchange-rates.json" {
+		t.Error("public constants changed")
+	}
+}
@@ -0,0 +1,346 @@
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// BaseCurrency is the currency AWS prices are quoted in and the base that
+// exchange rates are expressed against.
+const BaseCurrency = "USD"
+
+// RatesFile is the name of the exchange rates file in the config directory.
+const RatesFile = "exchange-rates.json"
+
+// Locale describes how a locale groups digits and places the currency symbol.
+type Locale struct {
+	Name        string
+	Group       string // thousands separator
+	Decimal     string // decimal separator
+	SymbolAfter bool   // "1.234,56 €" rather than "€1,234.56"
+	Space       bool   // no-break space between the symbol and the number
+}
+
+// locales holds the supported locales keyed by BCP 47 tag.
+var locales = map[string]Locale{
+	"en-US": {Name: "en-US", Group: ",", Decimal: "."},
+	"en-GB": {Name: "en-GB", Group: ",", Decimal: "."},
+	"en-IE": {Name: "en-IE", Group: ",", Decimal: "."},
+	"de-DE": {Name: "de-DE", Group: ".", Decimal: ",", SymbolAfter: true, Space: true},
+	"fr-FR": {Name: "fr-FR", Group: "\u202f", Decimal: ",", SymbolAfter: true, Space: true},
+	"es-ES": {Name: "es-ES", Group: ".", Decimal: ",", SymbolAfter: true, Space: true},
+	"it-IT": {Name: "it-IT", Group: ".", Decimal: ",", SymbolAfter: true, Space: true},
+	"nl-NL": {Name: "nl-NL", Group: ".", Decimal: ",", Space: true},
+	"de-CH": {Name: "de-CH", Group: "’", Decimal: ".", Space: true},
+	"zh-CN": {Name: "zh-CN", Group: ",", Decimal: "."},
+	"ja-JP": {Name: "ja-JP", Group: ",", Decimal: "."},
+}
+
+// symbols maps currency codes to their display symbol.
+var symbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"CNY": "¥",
+	"JPY": "¥",
+	"CHF": "CHF",
+	"CAD": "CA$",
+	"AUD": "A$",
+	"INR": "₹",
+}
+
+// defaultLocales maps currency codes to the locale used when none is set.
+var defaultLocales = map[string]string{
+	"USD": "en-US",
+	"EUR": "de-DE",
+	"GBP": "en-GB",
+	"CNY": "zh-CN",
+	"JPY": "ja-JP",
+	"CHF": "de-CH",
+}
+
+// Rates holds exchange rates as units of each currency per 1 USD.
+type Rates struct {
+	AsOf  string             // optional date the rates were taken, for export metadata
+	Rates map[string]float64 // currency code -> units per 1 USD
+}
+
+// ratesFile is the on-disk format of the exchange rates file. Rates may be
+// quoted against any base as long as the file also includes USD.
+type ratesFile struct {
+	Base  string             `json:"base"`
+	AsOf  string             `json:"as_of"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// LoadRates reads an exchange rates file and rebases it to USD.
+// A missing file yields empty rates and no error.
+func LoadRates(path string) (Rates, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Rates{}, nil
+	}
+	if err != nil {
+		return Rates{}, fmt.Errorf("reading exchange rates: %w", err)
+	}
+
+	var f ratesFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Rates{}, fmt.Errorf("parsing exchange rates: %w", err)
+	}
+
+	base := strings.ToUpper(f.Base)
+	if base == "" {
+		base = BaseCurrency
+	}
+
+	// Rebase onto USD: units of X per USD = (X per base) / (USD per base).
+	perBase := map[string]float64{base: 1}
+	for code, rate := range f.Rates {
+		perBase[strings.ToUpper(code)] = rate
+	}
+	usd, ok := perBase[BaseCurrency]
+	if !ok || usd <= 0 {
+		return Rates{}, fmt.Errorf("exchange rates quoted in %s must include %s", base, BaseCurrency)
+	}
+
+	out := Rates{AsOf: f.AsOf, Rates: make(map[string]float64)}
+	for code, rate := range perBase {
+		if rate > 0 {
+			out.Rates[code] = rate / usd
+		}
+	}
+	return out, nil
+}
+
+// Merge returns a copy of r with overrides applied on top.
+func (r Rates) Merge(overrides map[string]float64) Rates {
+	out := Rates{AsOf: r.AsOf, Rates: make(map[string]float64)}
+	for code, rate := range r.Rates {
+		out.Rates[code] = rate
+	}
+	for code, rate := range overrides {
+		if rate > 0 {
+			out.Rates[strings.ToUpper(code)] = rate
+		}
+	}
+	return out
+}
+
+// Codes returns USD followed by every other currency with a known rate,
+// in a stable order.
+func (r Rates) Codes() []string {
+	codes := []string{BaseCurrency}
+	for _, code := range []string{"EUR", "GBP", "CHF", "CAD", "AUD", "JPY", "CNY", "INR"} {
+		if r.Rates[code] > 0 {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Formatter converts amounts into a currency and formats them for a locale.
+// Amounts are in USD unless Base says otherwise, as for AWS China prices,
+// which are in CNY. The zero value formats USD for en-US.
+type Formatter struct {
+	Code   string  // ISO 4217 code
+	Rate   float64 // units of Code per 1 USD
+	AsOf   string
+	Locale Locale
+
+	Base     string  // ISO 4217 code of the amounts; empty means USD
+	BaseRate float64 // units of Base per 1 USD
+}
+
+// USD returns the default formatter: US dollars in the en-US locale.
+func USD() Formatter {
+	return Formatter{Code: BaseCurrency, Rate: 1, Locale: locales["en-US"]}
+}
+
+// New returns a formatter for the given currency and locale. An empty code
+// means USD and an empty locale picks the currency's default locale. Returns
+// an error if no exchange rate is known for a non-USD currency or the locale
+// is not supported.
+func New(code, locale string, rates Rates) (Formatter, error) {
+	code = strings.ToUpper(code)
+	if code == "" {
+		code = BaseCurrency
+	}
+
+	rate := 1.0
+	if code != BaseCurrency {
+		rate = rates.Rates[code]
+		if rate <= 0 {
+			return USD(), fmt.Errorf("no exchange rate for %s", code)
+		}
+	}
+
+	if locale == "" {
+		locale = defaultLocales[code]
+		if locale == "" {
+			locale = "en-US"
+		}
+	}
+	loc, ok := locales[locale]
+	if !ok {
+		return USD(), fmt.Errorf("unsupported locale %q", locale)
+	}
+
+	return Formatter{Code: code, Rate: rate, AsOf: rates.AsOf, Locale: loc}, nil
+}
+
+// Symbol returns the display symbol for the formatter's currency.
+func (f Formatter) Symbol() string {
+	if s, ok := symbols[f.code()]; ok {
+		return s
+	}
+	return f.code()
+}
+
+func (f Formatter) code() string {
+	if f.Code == "" {
+		return BaseCurrency
+	}
+	return f.Code
+}
+
+func (f Formatter) rate() float64 {
+	if f.Rate <= 0 {
+		return 1
+	}
+	return f.Rate
+}
+
+func (f Formatter) base() string {
+	if f.Base == "" {
+		return BaseCurrency
+	}
+	return f.Base
+}
+
+// factor returns the units of Code per unit of Base.
+func (f Formatter) factor() float64 {
+	if f.base() == f.code() {
+		return 1
+	}
+	baseRate := f.BaseRate
+	if baseRate <= 0 {
+		baseRate = 1
+	}
+	return f.rate() / baseRate
+}
+
+// WithBase returns f for amounts in base rather than USD. An empty base
+// means USD. If no exchange rate is known for base, amounts cannot be
+// converted, so it returns a formatter that shows them in base itself,
+// with f's locale, and an error.
+func (f Formatter) WithBase(base string, rates Rates) (Formatter, error) {
+	base = strings.ToUpper(base)
+	if base == "" {
+		base = BaseCurrency
+	}
+
+	f.Base = base
+	switch {
+	case base == BaseCurrency:
+		f.BaseRate = 1
+	case base == f.code():
+		f.BaseRate = f.rate()
+	case rates.Rates[base] > 0:
+		f.BaseRate = rates.Rates[base]
+	default:
+		return Formatter{Code: base, Locale: f.Locale, Base: base}, fmt.Errorf("no exchange rate for %s", base)
+	}
+	return f, nil
+}
+
+func (f Formatter) locale() Locale {
+	if f.Locale.Name == "" {
+		return locales["en-US"]
+	}
+	return f.Locale
+}
+
+// Convert converts an amount in the base currency into the formatter's
+// currency.
+func (f Formatter) Convert(amount float64) float64 {
+	return amount * f.factor()
+}
+
+// ToUSD converts an amount in the formatter's currency back into USD.
+func (f Formatter) ToUSD(v float64) float64 {
+	return v / f.rate()
+}
+
+// ToBase converts an amount in the formatter's currency back into the base
+// currency. Without a Base, it is ToUSD.
+func (f Formatter) ToBase(v float64) float64 {
+	return v / f.factor()
+}
+
+// Format converts an amount in the base currency and formats it with two
+// decimals, e.g. "$1,234.56" or "1.234,56 €".
+func (f Formatter) Format(amount float64) string {
+	return f.format(f.Convert(amount), 2, false)
+}
+
+// FormatSigned is like Format but always includes a sign for non-zero
+// amounts, e.g. "+$1,234.56" or "-1.234,56 €".
+func (f Formatter) FormatSigned(amount float64) string {
+	return f.format(f.Convert(amount), 2, true)
+}
+
+// FormatRate converts a unit rate in the base currency and formats it with
+// the given number of decimals, for showing hourly prices such as
+// "$0.030000".
+func (f Formatter) FormatRate(amount float64, decimals int) string {
+	return f.format(f.Convert(amount), decimals, false)
+}
+
+func (f Formatter) format(v float64, decimals int, signed bool) string {
+	loc := f.locale()
+
+	sign := ""
+	if v < 0 && math.Abs(v) >= 0.5*math.Pow10(-decimals) {
+		sign = "-"
+	} else if signed && v > 0 && v >= 0.5*math.Pow10(-decimals) {
+		sign = "+"
+	}
+
+	s := fmt.Sprintf("%.*f", decimals, math.Abs(v))
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	intPart = group(intPart, loc.Group)
+
+	number := intPart
+	if decimals > 0 {
+		number += loc.Decimal + fracPart
+	}
+
+	sep := ""
+	if loc.Space {
+		sep = "\u00a0" // no-break space keeps the amount on one line
+	}
+	if loc.SymbolAfter {
+		return sign + number + sep + f.Symbol()
+	}
+	return sign + f.Symbol() + sep + number
+}
+
+// group inserts sep between every group of three digits.
+func group(digits, sep string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,264 @@
+package currency
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestZeroValueFormatsUSD(t *testing.T) {
+	var f Formatter
+	if got := f.Format(1234.5); got != "$1,234.50" {
+		t.Errorf("got %q, want %q", got, "$1,234.50")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	rates := Rates{Rates: map[string]float64{"EUR": 0.5, "GBP": 2, "CHF": 1}}
+
+	tests := []struct {
+		code, locale string
+		usd          float64
+		want         string
+	}{
+		{"USD", "", 1234567.891, "$1,234,567.89"},
+		{"USD", "", 0, "$0.00"},
+		{"USD", "", 999, "$999.00"},
+		{"USD", "", -1234.5, "-$1,234.50"},
+		{"EUR", "", 2469.12, "1.234,56\u00a0€"},
+		{"EUR", "fr-FR", 2469.12, "1\u202f234,56\u00a0€"},
+		{"EUR", "en-IE", 2469.12, "€1,234.56"},
+		{"EUR", "nl-NL", 2469.12, "€\u00a01.234,56"},
+		{"GBP", "", 617.28, "£1,234.56"},
+		{"CHF", "", 1234.56, "CHF\u00a01’234.56"},
+	}
+
+	for _, tt := range tests {
+		f, err := New(tt.code, tt.locale, rates)
+		if err != nil {
+			t.Fatalf("New(%q, %q): %v", tt.code, tt.locale, err)
+		}
+		if got := f.Format(tt.usd); got != tt.want {
+			t.Errorf("%s/%s Format(%v): got %q, want %q", tt.code, tt.locale, tt.usd, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSigned(t *testing.T) {
+	eur, _ := New("EUR", "", Rates{Rates: map[string]float64{"EUR": 1}})
+
+	tests := []struct {
+		f    Formatter
+		v    float64
+		want string
+	}{
+		{USD(), 1234.5, "+$1,234.50"},
+		{USD(), -1234.5, "-$1,234.50"},
+		{USD(), 0, "$0.00"},
+		{USD(), -0.001, "$0.00"},
+		{eur, -1234.5, "-1.234,50\u00a0€"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.f.FormatSigned(tt.v); got != tt.want {
+			t.Errorf("FormatSigned(%v): got %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	eur, _ := New("EUR", "", Rates{Rates: map[string]float64{"EUR": 0.5}})
+
+	if got := USD().FormatRate(0.03, 6); got != "$0.030000" {
+		t.Errorf("got %q, want %q", got, "$0.030000")
+	}
+	if got := eur.FormatRate(0.03, 6); got != "0,015000\u00a0€" {
+		t.Errorf("got %q, want %q", got, "0,015000\u00a0€")
+	}
+	if got := USD().FormatRate(1234, 0); got != "$1,234" {
+		t.Errorf("got %q, want %q", got, "$1,234")
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	f, _ := New("GBP", "", Rates{Rates: map[string]float64{"GBP": 0.8}})
+
+	if got := f.Convert(100); got != 80 {
+		t.Errorf("Convert: got %v, want 80", got)
+	}
+	if got := f.ToUSD(80); got != 100 {
+		t.Errorf("ToUSD: got %v, want 100", got)
+	}
+}
+
+func TestWithBase(t *testing.T) {
+	rates := Rates{Rates: map[string]float64{"CNY": 7, "EUR": 0.9}}
+	usd, _ := New("USD", "", rates)
+	eur, _ := New("EUR", "en-IE", rates)
+	cny, _ := New("CNY", "", rates)
+
+	tests := []struct {
+		f    Formatter
+		base string
+		want string
+	}{
+		{usd, "cny", "$100.00"},
+		{eur, "CNY", "€90.00"},
+		{cny, "CNY", "¥700.00"},
+		{eur, "", "€630.00"},
+		{Formatter{Code: "EUR"}, "", "€700.00"}, // no rate converts 1:1
+	}
+	for _, tt := range tests {
+		f, err := tt.f.WithBase(tt.base, rates)
+		if err != nil {
+			t.Fatalf("%s from %s: %v", tt.f.Code, tt.base, err)
+		}
+		if got := f.Format(700); got != tt.want {
+			t.Errorf("%s from %q: got %q, want %q", tt.f.Code, tt.base, got, tt.want)
+		}
+		if got := f.ToBase(f.Convert(700)); math.Abs(got-700) > 1e-9 {
+			t.Errorf("%s from %q: ToBase got %v, want 700", tt.f.Code, tt.base, got)
+		}
+	}
+
+	// Without an exchange rate, amounts stay in the base currency.
+	f, err := usd.WithBase("CNY", Rates{})
+	if err == nil || f.Format(700) != "¥700.00" || f.Code != "CNY" {
+		t.Errorf("expected CNY shown unconverted with an error, got %q, %v", f.Format(700), err)
+	}
+}
+
+func TestSymbolUnknownCurrency(t *testing.T) {
+	f, err := New("sek", "", Rates{Rates: map[string]float64{"SEK": 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Symbol() != "SEK" {
+		t.Errorf("Symbol: got %q, want SEK", f.Symbol())
+	}
+	if f.Locale.Name != "en-US" {
+		t.Errorf("Locale: got %q, want en-US fallback", f.Locale.Name)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New("EUR", "", Rates{}); err == nil {
+		t.Error("expected error for missing exchange rate")
+	}
+	if _, err := New("USD", "xx-XX", Rates{}); err == nil {
+		t.Error("expected error for unsupported locale")
+	}
+
+	f, err := New("", "", Rates{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Code != "USD" || f.Rate != 1 {
+		t.Errorf("empty code: got %+v, want USD at 1", f)
+	}
+}
+
+func writeRates(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), RatesFile)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRatesUSDBase(t *testing.T) {
+	path := writeRates(t, `{"base":"USD","as_of":"2026-10-01","rates":{"eur":0.92,"GBP":0.79}}`)
+
+	r, err := LoadRates(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.AsOf != "2026-10-01" {
+		t.Errorf("AsOf: got %q", r.AsOf)
+	}
+	if r.Rates["EUR"] != 0.92 || r.Rates["GBP"] != 0.79 || r.Rates["USD"] != 1 {
+		t.Errorf("Rates: got %v", r.Rates)
+	}
+}
+
+func TestLoadRatesRebases(t *testing.T) {
+	// Quoted per EUR: 1 EUR = 1.25 USD = 0.8 GBP
+	path := writeRates(t, `{"base":"EUR","rates":{"USD":1.25,"GBP":0.8}}`)
+
+	r, err := LoadRates(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Rates["EUR"] != 0.8 {
+		t.Errorf("EUR: got %v, want 0.8", r.Rates["EUR"])
+	}
+	if r.Rates["GBP"] != 0.64 {
+		t.Errorf("GBP: got %v, want 0.64", r.Rates["GBP"])
+	}
+}
+
+func TestLoadRatesDefaultsToUSDBase(t *testing.T) {
+	path := writeRates(t, `{"rates":{"EUR":0.9,"XXX":0}}`)
+
+	r, err := LoadRates(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Rates["EUR"] != 0.9 {
+		t.Errorf("EUR: got %v", r.Rates["EUR"])
+	}
+	if _, ok := r.Rates["XXX"]; ok {
+		t.Error("non-positive rates should be dropped")
+	}
+}
+
+func TestLoadRatesMissingFile(t *testing.T) {
+	r, err := LoadRates(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Rates) != 0 {
+		t.Errorf("expected no rates, got %v", r.Rates)
+	}
+}
+
+func TestLoadRatesErrors(t *testing.T) {
+	if _, err := LoadRates(writeRates(t, `not json`)); err == nil || !strings.Contains(err.Error(), "parsing") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+	if _, err := LoadRates(writeRates(t, `{"base":"EUR","rates":{"GBP":0.8}}`)); err == nil {
+		t.Error("expected error when non-USD base lacks USD")
+	}
+	if _, err := LoadRates(t.TempDir()); err == nil || !strings.Contains(err.Error(), "reading") {
+		t.Errorf("expected read error, got %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	r := Rates{AsOf: "2026-10-01", Rates: map[string]float64{"EUR": 0.9, "GBP": 0.8}}
+	m := r.Merge(map[string]float64{"eur": 0.95, "JPY": 0})
+
+	if m.Rates["EUR"] != 0.95 || m.Rates["GBP"] != 0.8 {
+		t.Errorf("got %v", m.Rates)
+	}
+	if _, ok := m.Rates["JPY"]; ok {
+		t.Error("non-positive overrides should be ignored")
+	}
+	if r.Rates["EUR"] != 0.9 {
+		t.Error("Merge must not modify the receiver")
+	}
+	if m.AsOf != "2026-10-01" {
+		t.Errorf("AsOf: got %q", m.AsOf)
+	}
+}
+
+func TestCodes(t *testing.T) {
+	r := Rates{Rates: map[string]float64{"GBP": 0.8, "EUR": 0.9, "SEK": 10}}
+	got := strings.Join(r.Codes(), ",")
+	if got != "USD,EUR,GBP" {
+		t.Errorf("got %q, want USD,EUR,GBP", got)
+	}
+}
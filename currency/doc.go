@@ -0,0 +1,16 @@
+// Package currency converts USD amounts into a display currency using
+// user-provided exchange rates and formats them for a locale.
+//
+//	rates, err := currency.LoadRates("exchange-rates.json")
+//	f, err := currency.New("EUR", "", rates)
+//	f.Format(1234.56) // "1.135,80 €" at 0.92 EUR per USD
+//
+// Formatter.WithBase formats amounts in another currency, such as AWS China
+// prices in CNY, converting through USD.
+//
+// # Compatibility
+//
+// This package follows semantic versioning. Within a major version exported
+// functions keep their signatures and fields are only added to structs.
+// Supported locales and currency symbols may be added.
+package currency
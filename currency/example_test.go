@@ -0,0 +1,26 @@
+package currency_test
+
+import (
+	"fmt"
+
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+)
+
+func ExampleNew() {
+	rates := currency.Rates{Rates: map[string]float64{"EUR": 0.5, "GBP": 0.8}}
+
+	for _, code := range []string{"USD", "GBP"} {
+		f, err := currency.New(code, "", rates)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(f.Format(12345.678))
+	}
+
+	f, _ := currency.New("EUR", "en-IE", rates)
+	fmt.Println(f.FormatSigned(-2469.12))
+	// Output:
+	// $12,345.68
+	// £9,876.54
+	// -€1,234.56
+}
@@ -0,0 +1,27 @@
+package export_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/export"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// These assignments fail to compile if a public signature changes. Update
+// them only together with a major version bump.
+var (
+	_ func([]export.Scenario, string) error    = export.ToCSV
+	_ func(io.Writer, []export.Scenario) error = export.WriteCSV
+)
+
+func TestAPIScenarioFields(t *testing.T) {
+	_ = export.Scenario{
+		Input:     calculator.ScenarioInput{},
+		Breakdown: calculator.CostBreakdown{},
+		Currency:  currency.Formatter{},
+		Rates:     pricing.RateSheet{},
+	}
+}
@@ -0,0 +1,12 @@
+// Package export writes calculated scenarios to CSV.
+//
+// Each row is one metric of one scenario: scenario, capability, metric, value
+// and formatted. value is always machine-readable; formatted holds money
+// amounts formatted for the scenario's currency and locale.
+//
+// # Compatibility
+//
+// This package follows semantic versioning. Within a major version the
+// column layout and existing metric names are stable. New metrics may be
+// added, so readers should select rows by metric name rather than position.
+package export
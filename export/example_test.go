@@ -0,0 +1,37 @@
+package export_test
+
+import (
+	"os"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/export"
+)
+
+func ExampleWriteCSV() {
+	input := calculator.ScenarioInput{
+		Name:          "prod",
+		Capability:    calculator.CapabilityKro,
+		NumClusters:   2,
+		HoursPerMonth: 730,
+		BasePerHour:   0.005,
+	}
+	scenario := export.Scenario{Input: input, Breakdown: calculator.Calculate(input)}
+
+	_ = export.WriteCSV(os.Stdout, []export.Scenario{scenario})
+	// Output:
+	// scenario,capability,metric,value,formatted
+	// prod,kro,currency,USD,
+	// prod,kro,exchange_rate_per_usd,1,
+	// prod,kro,clusters,2,
+	// prod,kro,resources_per_cluster,0,
+	// prod,kro,total_resources,0,
+	// prod,kro,hours_per_month,730,
+	// prod,kro,base_monthly,7.30,$7.30
+	// prod,kro,per_resource_monthly,0.00,$0.00
+	// prod,kro,capability_subtotal_monthly,7.30,$7.30
+	// prod,kro,total_monthly,7.30,$7.30
+	// prod,kro,total_annual,87.60,$87.60
+	// prod,kro,self_managed_compute_monthly,0.00,$0.00
+	// prod,kro,self_managed_monthly,0.00,$0.00
+	// prod,kro,difference_monthly,7.30,+$7.30
+}
@@ -0,0 +1,200 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// osCreateFile abstracts os.Create for testing. Returns an io.WriteCloser.
+var osCreateFile = func(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+
+// Scenario pairs an input with its calculated breakdown and the currency
+// amounts are exported in. The zero Currency exports USD. Amounts are in
+// the breakdown's currency; if Currency is based on another, they are
+// exported unconverted. Rates are the pricing rates the input was built
+// from; the provenance of each is exported so a spreadsheet can say where
+// its numbers came from.
+type Scenario struct {
+	Input     calculator.ScenarioInput
+	Breakdown calculator.CostBreakdown
+	Currency  currency.Formatter
+	Rates     pricing.RateSheet
+}
+
+// ToCSV writes the scenarios to a CSV file at the given path.
+func ToCSV(scenarios []Scenario, path string) (retErr error) {
+	f, err := osCreateFile(path)
+	if err != nil {
+		return fmt.Errorf("creating csv file: %w", err)
+	}
+	defer func() {
+		if cErr := f.Close(); cErr != nil && retErr == nil {
+			retErr = cErr
+		}
+	}()
+
+	return WriteCSV(f, scenarios)
+}
+
+// WriteCSV writes the scenarios as CSV to w: a header row followed by one
+// row per metric per scenario.
+func WriteCSV(w io.Writer, scenarios []Scenario) error {
+	cw := csv.NewWriter(w)
+
+	// csv.Writer buffers writes internally; errors surface via Flush/Error.
+	cw.Write([]string{"scenario", "capability", "metric", "value", "formatted"}) //nolint:errcheck // errors checked via cw.Error()
+
+	for _, s := range scenarios {
+		for _, row := range scenarioRows(s) {
+			cw.Write(row) //nolint:errcheck // errors checked via cw.Error()
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// scenarioRows returns the CSV rows for one scenario. Money rows carry the
+// amount converted into the scenario's currency as a plain number in value,
+// and the same amount formatted for the scenario's locale in formatted.
+func scenarioRows(s Scenario) [][]string {
+	cap := s.Input.Capability.String()
+	cur := s.Currency
+	base := currencyCode(s.Breakdown.Currency)
+	if currencyCode(cur.Base) != base {
+		cur, _ = cur.WithBase(base, currency.Rates{})
+	}
+
+	row := func(metric, value string) []string {
+		return []string{s.Input.Name, cap, metric, value, ""}
+	}
+	money := func(metric string, usd float64) []string {
+		return []string{s.Input.Name, cap, metric, fmt.Sprintf("%.2f", cur.Convert(usd)), cur.Format(usd)}
+	}
+
+	code := currencyCode(cur.Code)
+	rate := cur.Rate
+	if rate <= 0 {
+		rate = 1
+	}
+
+	rows := [][]string{row("currency", code)}
+	// Amounts shown unconverted in a currency without an exchange rate
+	// have none to export.
+	if code == currency.BaseCurrency || cur.Rate > 0 {
+		rows = append(rows, row("exchange_rate_per_usd", strconv.FormatFloat(rate, 'f', -1, 64)))
+	}
+	if base != currency.BaseCurrency {
+		rows = append(rows, row("rates_currency", base))
+		if cur.BaseRate > 0 {
+			rows = append(rows, row("rates_currency_per_usd", strconv.FormatFloat(cur.BaseRate, 'f', -1, 64)))
+		}
+	}
+	if cur.AsOf != "" {
+		rows = append(rows, row("exchange_rate_as_of", cur.AsOf))
+	}
+	if cur.Locale.Name != "" {
+		rows = append(rows, row("locale", cur.Locale.Name))
+	}
+
+	rows = append(rows,
+		row("clusters", fmt.Sprintf("%d", s.Input.NumClusters)),
+		row("resources_per_cluster", fmt.Sprintf("%d", s.Input.ResourcesPerCluster)),
+		row("total_resources", fmt.Sprintf("%d", s.Breakdown.TotalResources)),
+		row("hours_per_month", fmt.Sprintf("%.0f", s.Input.HoursPerMonth)),
+		money("base_monthly", s.Breakdown.BaseCapabilityMonthly),
+		money("per_resource_monthly", s.Breakdown.PerResourceMonthly),
+	)
+	for i, tc := range s.Breakdown.PerResourceTiers {
+		prefix := fmt.Sprintf("per_resource_tier:%d:", i+1)
+		to := "inf"
+		if tc.Tier.To > 0 {
+			to = strconv.FormatFloat(tc.Tier.To, 'f', -1, 64)
+		}
+		rows = append(rows,
+			row(prefix+"from_hours", strconv.FormatFloat(tc.Tier.From, 'f', -1, 64)),
+			row(prefix+"to_hours", to),
+			row(prefix+strings.ToLower(base)+"_per_hour", strconv.FormatFloat(tc.Tier.PerHour, 'f', -1, 64)),
+			row(prefix+"resource_hours", strconv.FormatFloat(tc.ResourceHours, 'f', -1, 64)),
+			money(prefix+"monthly", tc.Monthly),
+		)
+	}
+	rows = append(rows,
+		money("capability_subtotal_monthly", s.Breakdown.CapabilitySubtotalMonthly),
+		money("total_monthly", s.Breakdown.TotalMonthly),
+		money("total_annual", s.Breakdown.TotalAnnual),
+		money("self_managed_compute_monthly", s.Breakdown.SelfManagedComputeMonthly),
+	)
+	if s.Input.Capability == calculator.CapabilityArgoCD {
+		rows = append(rows,
+			money("self_managed_alb_monthly", s.Breakdown.SelfManagedALBMonthly),
+			money("self_managed_lcu_monthly", s.Breakdown.SelfManagedLCUMonthly),
+			money("self_managed_ebs_monthly", s.Breakdown.SelfManagedEBSMonthly),
+			money("self_managed_logs_monthly", s.Breakdown.SelfManagedLogsMonthly),
+		)
+	}
+	rows = append(rows,
+		money("self_managed_monthly", s.Breakdown.SelfManagedTotalMonthly),
+		[]string{s.Input.Name, cap, "difference_monthly",
+			fmt.Sprintf("%.2f", cur.Convert(s.Breakdown.ManagedVsSelfManaged)), cur.FormatSigned(s.Breakdown.ManagedVsSelfManaged)},
+	)
+	if len(s.Breakdown.Chargeback) > 0 {
+		rows = append(rows, row("allocation_policy", s.Input.Allocation.String()))
+	}
+	for _, c := range s.Breakdown.Chargeback {
+		prefix := "tenant:" + c.Tenant + ":"
+		rows = append(rows,
+			row(prefix+"resources", fmt.Sprintf("%d", c.Resources)),
+			money(prefix+"base_monthly", c.BaseMonthly),
+			money(prefix+"per_resource_monthly", c.ResourceMonthly),
+			money(prefix+"total_monthly", c.TotalMonthly),
+		)
+	}
+	for _, f := range pricing.Fields() {
+		p, ok := s.Rates.Provenance[f]
+		if !ok {
+			continue
+		}
+		prefix := "rate:" + string(f) + ":"
+		rows = append(rows,
+			row(prefix+strings.ToLower(s.Rates.CurrencyCode()), strconv.FormatFloat(s.Rates.Get(f), 'f', -1, 64)),
+			row(prefix+"source", p.Source),
+		)
+		if p.Region != "" {
+			rows = append(rows, row(prefix+"region", p.Region))
+		}
+		if p.UsageType != "" {
+			rows = append(rows, row(prefix+"usage_type", p.UsageType))
+		}
+		if p.Unit != "" {
+			rows = append(rows, row(prefix+"unit", p.Unit))
+		}
+		if p.Endpoint != "" {
+			rows = append(rows, row(prefix+"endpoint", p.Endpoint))
+		}
+		if !p.FetchedAt.IsZero() {
+			rows = append(rows, row(prefix+"fetched_at", p.FetchedAt.UTC().Format(time.RFC3339)))
+		}
+	}
+
+	return rows
+}
+
+// currencyCode returns code, or USD if it is empty.
+func currencyCode(code string) string {
+	if code == "" {
+		return currency.BaseCurrency
+	}
+	return code
+}
@@ -0,0 +1,461 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+func testScenario() Scenario {
+	input := calculator.ScenarioInput{
+		Name:                "Test",
+		Capability:          calculator.CapabilityArgoCD,
+		NumClusters:         1,
+		ResourcesPerCluster: 5,
+		HoursPerMonth:       730,
+	}
+	return Scenario{
+		Input:     input,
+		Breakdown: calculator.Calculate(input),
+	}
+}
+
+func TestToCSV(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.csv")
+
+	scenarios := []Scenario{testScenario()}
+	if err := ToCSV(scenarios, path); err != nil {
+		t.Fatalf("ToCSV: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading csv: %v", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, "scenario,capability,metric,value") {
+		t.Error("missing CSV header")
+	}
+	if !strings.Contains(content, "Test,ArgoCD,clusters,1") {
+		t.Error("missing clusters row")
+	}
+	if !strings.Contains(content, "Test,ArgoCD,resources_per_cluster,5") {
+		t.Error("missing resources_per_cluster row")
+	}
+	if !strings.Contains(content, "Test,ArgoCD,total_resources,5") {
+		t.Error("missing total_resources row")
+	}
+	if !strings.Contains(content, "Test,ArgoCD,total_monthly,") {
+		t.Error("missing total_monthly row")
+	}
+	if !strings.Contains(content, "Test,ArgoCD,base_monthly,") {
+		t.Error("missing base_monthly row")
+	}
+}
+
+func TestToCSVMultipleScenarios(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "multi.csv")
+
+	s1 := testScenario()
+	s2 := testScenario()
+	s2.Input.Name = "Second"
+	s2.Input.NumClusters = 3
+
+	if err := ToCSV([]Scenario{s1, s2}, path); err != nil {
+		t.Fatalf("ToCSV: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading csv: %v", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, "Test,") {
+		t.Error("missing first scenario")
+	}
+	if !strings.Contains(content, "Second,") {
+		t.Error("missing second scenario")
+	}
+}
+
+func TestToCSVInvalidPath(t *testing.T) {
+	err := ToCSV([]Scenario{testScenario()}, "/nonexistent/dir/file.csv")
+	if err == nil {
+		t.Error("expected error for invalid path")
+	}
+}
+
+func TestToCSVACK(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ack.csv")
+
+	input := calculator.ScenarioInput{
+		Name:                "ACK Test",
+		Capability:          calculator.CapabilityACK,
+		NumClusters:         2,
+		ResourcesPerCluster: 10,
+		HoursPerMonth:       730,
+	}
+	s := Scenario{Input: input, Breakdown: calculator.Calculate(input)}
+
+	if err := ToCSV([]Scenario{s}, path); err != nil {
+		t.Fatalf("ToCSV: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading csv: %v", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, "ACK Test,ACK,") {
+		t.Error("missing ACK capability in CSV")
+	}
+	if strings.Contains(content, "self_managed_alb_monthly") {
+		t.Error("ancillary rows should only be exported for ArgoCD")
+	}
+}
+
+func TestWriteCSVAncillaryRows(t *testing.T) {
+	s := testScenario()
+	s.Input.SelfManagedALBsPerCluster = 1
+	s.Input.SelfManagedALBCostPerHour = 0.0225
+	s.Input.SelfManagedLogsGBPerCluster = 10
+	s.Input.SelfManagedLogsCostPerGB = 0.50
+	s.Breakdown = calculator.Calculate(s.Input)
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Scenario{s}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	content := buf.String()
+	for _, want := range []string{
+		"Test,ArgoCD,self_managed_compute_monthly,",
+		"Test,ArgoCD,self_managed_alb_monthly,16.43",
+		"Test,ArgoCD,self_managed_lcu_monthly,0.00",
+		"Test,ArgoCD,self_managed_ebs_monthly,0.00",
+		"Test,ArgoCD,self_managed_logs_monthly,5.00",
+		"Test,ArgoCD,self_managed_monthly,21.43",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q in CSV:\n%s", want, content)
+		}
+	}
+}
+
+// failWriter returns an error on every Write call.
+type failWriter struct{}
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriteCSVWriteError(t *testing.T) {
+	err := WriteCSV(&failWriter{}, []Scenario{testScenario()})
+	if err == nil {
+		t.Error("expected error from write")
+	}
+}
+
+func TestWriteCSVSuccess(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []Scenario{testScenario()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "scenario,capability,metric,value") {
+		t.Error("missing CSV header in output")
+	}
+}
+
+// errorCloser wraps a writer and returns an error on Close.
+type errorCloser struct {
+	io.Writer
+}
+
+func (e *errorCloser) Close() error {
+	return errors.New("close error")
+}
+
+func TestToCSVCloseError(t *testing.T) {
+	orig := osCreateFile
+	defer func() { osCreateFile = orig }()
+
+	osCreateFile = func(name string) (io.WriteCloser, error) {
+		return &errorCloser{Writer: &bytes.Buffer{}}, nil
+	}
+
+	err := ToCSV([]Scenario{testScenario()}, "anything.csv")
+	if err == nil {
+		t.Error("expected error from close")
+	}
+	if err.Error() != "close error" {
+		t.Errorf("expected close error, got: %v", err)
+	}
+}
+
+func TestToCSVCreateError(t *testing.T) {
+	orig := osCreateFile
+	defer func() { osCreateFile = orig }()
+
+	osCreateFile = func(name string) (io.WriteCloser, error) {
+		return nil, errors.New("create error")
+	}
+
+	err := ToCSV([]Scenario{testScenario()}, "anything.csv")
+	if err == nil {
+		t.Error("expected error from create")
+	}
+	if !strings.Contains(err.Error(), "creating csv file") {
+		t.Errorf("expected wrapped create error, got: %v", err)
+	}
+}
+
+func TestWriteCSVChargebackRows(t *testing.T) {
+	s := testScenario()
+	s.Input.BasePerHour = 0.03
+	s.Input.ResourcePerHour = 0.0015
+	s.Input.Tenants = []calculator.Tenant{{Name: "payments", Resources: 3}}
+	s.Input.Allocation = calculator.AllocatePlatform
+	s.Breakdown = calculator.Calculate(s.Input)
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Scenario{s}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	content := buf.String()
+	for _, want := range []string{
+		"Test,ArgoCD,allocation_policy,Platform team",
+		"Test,ArgoCD,tenant:payments:resources,3",
+		"Test,ArgoCD,tenant:payments:base_monthly,0.00",
+		"Test,ArgoCD,tenant:payments:per_resource_monthly,3.28",
+		"Test,ArgoCD,tenant:platform:resources,2",
+		"Test,ArgoCD,tenant:platform:base_monthly,21.90",
+		"Test,ArgoCD,tenant:platform:total_monthly,24.09",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q in CSV:\n%s", want, content)
+		}
+	}
+}
+
+func TestWriteCSVResourceTierRows(t *testing.T) {
+	s := testScenario()
+	s.Input.NumClusters = 100
+	s.Input.ResourceTiers = []calculator.RateTier{
+		{From: 0, To: 100000, PerHour: 0.002},
+		{From: 100000, PerHour: 0.001},
+	}
+	s.Breakdown = calculator.Calculate(s.Input)
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Scenario{s}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	content := buf.String()
+	for _, want := range []string{
+		"Test,ArgoCD,per_resource_monthly,465.00",
+		"Test,ArgoCD,per_resource_tier:1:from_hours,0",
+		"Test,ArgoCD,per_resource_tier:1:to_hours,100000",
+		"Test,ArgoCD,per_resource_tier:1:usd_per_hour,0.002",
+		"Test,ArgoCD,per_resource_tier:1:resource_hours,100000",
+		"Test,ArgoCD,per_resource_tier:1:monthly,200.00",
+		"Test,ArgoCD,per_resource_tier:2:to_hours,inf",
+		"Test,ArgoCD,per_resource_tier:2:resource_hours,265000",
+		"Test,ArgoCD,per_resource_tier:2:monthly,265.00",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q in CSV:\n%s", want, content)
+		}
+	}
+}
+
+func TestWriteCSVNoChargebackRows(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Scenario{testScenario()}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	if strings.Contains(buf.String(), "tenant:") || strings.Contains(buf.String(), "allocation_policy") {
+		t.Error("chargeback rows should be omitted without tenants")
+	}
+}
+
+func TestWriteCSVRateProvenanceRows(t *testing.T) {
+	s := testScenario()
+	s.Rates = pricing.RateSheet{
+		Rates: pricing.Rates{
+			ArgoCDBasePerHour: 0.03,
+			EBSGBMonth:        0.08,
+			ALBPerHour:        0.0225, // no provenance: not exported
+		},
+		Provenance: map[pricing.Field]pricing.Provenance{
+			pricing.FieldArgoCDBasePerHour: {
+				Source:    "cache",
+				Region:    "us-east-1",
+				UsageType: "USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability",
+				Unit:      "Hrs",
+				Endpoint:  "eu-central-1",
+				FetchedAt: time.Date(2026, 10, 18, 14, 2, 0, 0, time.FixedZone("CEST", 2*3600)),
+			},
+			pricing.FieldEBSGBMonth: {Source: "defaults"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Scenario{s}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	content := buf.String()
+	for _, want := range []string{
+		"Test,ArgoCD,rate:ArgoCDBasePerHour:usd,0.03,\n",
+		"Test,ArgoCD,rate:ArgoCDBasePerHour:source,cache,\n",
+		"Test,ArgoCD,rate:ArgoCDBasePerHour:region,us-east-1,\n",
+		"Test,ArgoCD,rate:ArgoCDBasePerHour:usage_type,USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability,\n",
+		"Test,ArgoCD,rate:ArgoCDBasePerHour:unit,Hrs,\n",
+		"Test,ArgoCD,rate:ArgoCDBasePerHour:endpoint,eu-central-1,\n",
+		"Test,ArgoCD,rate:ArgoCDBasePerHour:fetched_at,2026-10-18T12:02:00Z,\n",
+		"Test,ArgoCD,rate:EBSGBMonth:usd,0.08,\n",
+		"Test,ArgoCD,rate:EBSGBMonth:source,defaults,\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q in CSV:\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{"rate:EBSGBMonth:region", "rate:EBSGBMonth:fetched_at", "rate:EBSGBMonth:endpoint", "rate:EBSGBMonth:unit", "rate:ALBPerHour"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("unexpected %q in CSV:\n%s", unwanted, content)
+		}
+	}
+}
+
+func TestWriteCSVNoRateRowsWithoutProvenance(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Scenario{testScenario()}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	if strings.Contains(buf.String(), "rate:") {
+		t.Error("rate rows should be omitted without provenance")
+	}
+}
+
+func TestWriteCSVDefaultCurrencyMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Scenario{testScenario()}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	content := buf.String()
+	for _, want := range []string{
+		"scenario,capability,metric,value,formatted\n",
+		"Test,ArgoCD,currency,USD,\n",
+		"Test,ArgoCD,exchange_rate_per_usd,1,\n",
+		"Test,ArgoCD,clusters,1,\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q in CSV:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "exchange_rate_as_of") {
+		t.Error("as_of row should be omitted when unknown")
+	}
+}
+
+func TestWriteCSVConvertsCurrency(t *testing.T) {
+	cur, err := currency.New("EUR", "", currency.Rates{AsOf: "2026-10-01", Rates: map[string]float64{"EUR": 0.5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := testScenario()
+	s.Input.BasePerHour = 4
+	s.Breakdown = calculator.Calculate(s.Input)
+	s.Currency = cur
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Scenario{s}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	// Base: 4 x 730 = 2920 USD = 1460 EUR
+	content := buf.String()
+	for _, want := range []string{
+		"Test,ArgoCD,currency,EUR,\n",
+		"Test,ArgoCD,exchange_rate_per_usd,0.5,\n",
+		"Test,ArgoCD,exchange_rate_as_of,2026-10-01,\n",
+		"Test,ArgoCD,locale,de-DE,\n",
+		"Test,ArgoCD,base_monthly,1460.00,\"1.460,00\u00a0€\"\n",
+		"Test,ArgoCD,clusters,1,\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q in CSV:\n%s", want, content)
+		}
+	}
+}
+
+func TestWriteCSVRatesCurrency(t *testing.T) {
+	s := testScenario()
+	s.Input.Currency = "CNY"
+	s.Input.BasePerHour = 1
+	s.Input.ResourceTiers = []calculator.RateTier{{PerHour: 0.01}}
+	s.Breakdown = calculator.Calculate(s.Input)
+	s.Rates = pricing.RateSheet{
+		Rates:      pricing.Rates{Currency: "CNY", ArgoCDBasePerHour: 1},
+		Provenance: map[pricing.Field]pricing.Provenance{pricing.FieldArgoCDBasePerHour: {Source: "api"}},
+	}
+
+	// A USD formatter cannot convert CNY amounts, so they are exported as is.
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Scenario{s}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	content := buf.String()
+	for _, want := range []string{
+		"Test,ArgoCD,currency,CNY,\n",
+		"Test,ArgoCD,rates_currency,CNY,\n",
+		"Test,ArgoCD,base_monthly,730.00,¥730.00\n",
+		"Test,ArgoCD,per_resource_tier:1:cny_per_hour,0.01,\n",
+		"Test,ArgoCD,rate:ArgoCDBasePerHour:cny,1,\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q in CSV:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "exchange_rate_per_usd") || strings.Contains(content, "$") {
+		t.Errorf("unexpected USD in CSV:\n%s", content)
+	}
+
+	// A formatter based on CNY converts them.
+	s.Currency, _ = currency.USD().WithBase("CNY", currency.Rates{Rates: map[string]float64{"CNY": 7.3}})
+	buf.Reset()
+	if err := WriteCSV(&buf, []Scenario{s}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	content = buf.String()
+	for _, want := range []string{
+		"Test,ArgoCD,currency,USD,\n",
+		"Test,ArgoCD,exchange_rate_per_usd,1,\n",
+		"Test,ArgoCD,rates_currency_per_usd,7.3,\n",
+		"Test,ArgoCD,base_monthly,100.00,$100.00\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q in CSV:\n%s", want, content)
+		}
+	}
+}
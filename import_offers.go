@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// newCache is a seam so tests can redirect the cache directory.
+var newCache = pricing.NewCache
+
+// importOffers reads AWS Price List bulk offer files and writes the rates
+// for every region they contain to the pricing cache.
+func importOffers(paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("import-offers requires at least one offer file\n\n%s", usage)
+	}
+
+	offers, err := pricing.LoadOffers(paths...)
+	if err != nil {
+		return err
+	}
+
+	regions, err := offers.Populate(newCache())
+	if err != nil {
+		return err
+	}
+	if len(regions) == 0 {
+		return fmt.Errorf("no EKS capability, Fargate or ancillary rates found in %s", strings.Join(paths, ", "))
+	}
+
+	if _, err := fmt.Fprintf(stdout, "Cached rates for %d region(s): %s\n", len(regions), strings.Join(regions, ", ")); err != nil {
+		return err
+	}
+	for _, region := range regions {
+		rates, _ := offers.Lookup(context.Background(), region)
+		if err := reportSuspect(region, rates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
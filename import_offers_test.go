@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+const testEKSOffer = `{
+	"offerCode": "AmazonEKS",
+	"products": {
+		"SKU1": {"sku": "SKU1", "productFamily": "EKS Capabilities", "attributes": {
+			"servicecode": "AmazonEKS", "regionCode": "eu-west-1",
+			"usagetype": "EU-AmazonEKSCapabilities-ArgoCD-Hours:perCapability"}}
+	},
+	"terms": {"OnDemand": {"SKU1": {"SKU1.T": {"priceDimensions": {"D": {"unit": "Hours", "pricePerUnit": {"USD": "0.033"}}}}}}}
+}`
+
+func withTestCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	old := newCache
+	t.Cleanup(func() { newCache = old })
+	newCache = func() *pricing.Cache { return pricing.NewCacheDir(dir) }
+	return dir
+}
+
+func writeOffer(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "offer.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportOffers(t *testing.T) {
+	dir := withTestCache(t)
+	oldOut := stdout
+	defer func() { stdout = oldOut }()
+	var buf bytes.Buffer
+	stdout = &buf
+
+	if err := run([]string{"import-offers", writeOffer(t, testEKSOffer)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "1 region(s): eu-west-1") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	cached := pricing.NewCacheDir(dir).Load("eu-west-1")
+	if cached == nil || cached.ArgoCDBasePerHour != 0.033 {
+		t.Errorf("expected cached eu-west-1 rates, got %+v", cached)
+	}
+}
+
+func TestImportOffersNoFiles(t *testing.T) {
+	if err := importOffers(nil); err == nil || !strings.Contains(err.Error(), "at least one offer file") {
+		t.Errorf("expected usage error, got %v", err)
+	}
+}
+
+func TestImportOffersBadFile(t *testing.T) {
+	withTestCache(t)
+	if err := importOffers([]string{writeOffer(t, "{")}); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestImportOffersNoRates(t *testing.T) {
+	withTestCache(t)
+	err := importOffers([]string{writeOffer(t, `{"products": {}, "terms": {}}`)})
+	if err == nil || !strings.Contains(err.Error(), "no EKS capability") {
+		t.Errorf("expected no rates error, got %v", err)
+	}
+}
+
+func TestImportOffersCacheError(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	_ = os.WriteFile(blocker, nil, 0o600)
+	old := newCache
+	defer func() { newCache = old }()
+	newCache = func() *pricing.Cache { return pricing.NewCacheDir(filepath.Join(blocker, "sub")) }
+
+	if err := importOffers([]string{writeOffer(t, testEKSOffer)}); err == nil {
+		t.Error("expected cache write error")
+	}
+}
+
+func TestImportOffersSuspectRates(t *testing.T) {
+	withTestCache(t)
+	oldOut := stdout
+	defer func() { stdout = oldOut }()
+	var buf bytes.Buffer
+	stdout = &buf
+
+	offer := strings.Replace(testEKSOffer, `"0.033"`, `"3.3"`, 1)
+	if err := run([]string{"import-offers", writeOffer(t, offer)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Suspect rate in eu-west-1: ArgoCDBasePerHour is 3.3, 110x the default 0.03") {
+		t.Errorf("expected the suspect rate reported, got %q", buf.String())
+	}
+
+	for ok := range 2 {
+		stdout = &failingWriter{ok: ok}
+		if err := run([]string{"import-offers", writeOffer(t, offer)}); err == nil || err.Error() != "write failed" {
+			t.Errorf("after %d writes: expected the write error, got %v", ok, err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const configSubdir = "aws-eks-calculator"
@@ -14,6 +15,64 @@ var jsonMarshal = json.Marshal
 // Prefs holds user preferences that persist across sessions.
 type Prefs struct {
 	Region string `json:"region,omitempty"`
+
+	// Currency is the ISO 4217 code money is displayed and exported in
+	// (default "USD"). Locale controls grouping, decimal separators and
+	// symbol placement (default depends on the currency).
+	Currency string `json:"currency,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+
+	// ExchangeRates maps currency codes to units per 1 USD. Entries here take
+	// precedence over the exchange rates file.
+	ExchangeRates map[string]float64 `json:"exchange_rates,omitempty"`
+
+	// RateSources lists the pricing sources to consult, highest precedence
+	// first (see pricing.BuildChain). Empty means pricing.DefaultSourceOrder.
+	// OfferFiles are the bulk offer files read by the "offers" source.
+	RateSources []string `json:"rate_sources,omitempty"`
+	OfferFiles  []string `json:"offer_files,omitempty"`
+
+	// WarmRegions lists the regions whose rates are fetched in the
+	// background after startup, so that switching to them is instant.
+	// Empty means every region. DisableWarming turns this off.
+	WarmRegions    []string `json:"warm_regions,omitempty"`
+	DisableWarming bool     `json:"disable_warming,omitempty"`
+
+	// CacheTTL is how long cached rates stay valid, as a Go duration such
+	// as "12h" (default 24h). With StaleWhileRevalidate, expired rates are
+	// shown while fresh ones are fetched.
+	CacheTTL             string `json:"cache_ttl,omitempty"`
+	StaleWhileRevalidate bool   `json:"stale_while_revalidate,omitempty"`
+
+	// AWSProfile selects the shared config profile Pricing API calls use.
+	// Empty means AWS_PROFILE or the default profile. AWSRoleARN is a role
+	// to assume with the profile's credentials.
+	AWSProfile string `json:"aws_profile,omitempty"`
+	AWSRoleARN string `json:"aws_role_arn,omitempty"`
+
+	// PricingEndpoint is the region of the Pricing API endpoint to try
+	// first, such as "eu-central-1". Empty means us-east-1. The other
+	// endpoints are tried when it cannot be reached or denies the call.
+	PricingEndpoint string `json:"pricing_endpoint,omitempty"`
+
+	// Offline keeps the calculator from calling AWS: rates come from the
+	// cache, offer files, overrides and defaults.
+	Offline bool `json:"offline,omitempty"`
+
+	// RejectSuspectRates replaces rates that fail the sanity checks, such
+	// as one ten times its built-in default, with the next source's rate
+	// instead of using them with a warning.
+	RejectSuspectRates bool `json:"reject_suspect_rates,omitempty"`
+}
+
+// CacheTTLDuration parses CacheTTL. It returns zero, meaning the default,
+// if CacheTTL is empty, invalid or not positive.
+func (p Prefs) CacheTTLDuration() time.Duration {
+	d, err := time.ParseDuration(p.CacheTTL)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
 }
 
 // overrideDir allows tests to redirect prefs to a temporary directory.
@@ -90,3 +149,21 @@ func Save(p Prefs) error {
 	}
 	return (&store{dir: d}).save(p)
 }
+
+// Update loads the saved preferences, applies fn and saves the result, so
+// that changing one setting does not discard the others.
+func Update(fn func(*Prefs)) error {
+	p := Load()
+	fn(&p)
+	return Save(p)
+}
+
+// Path returns the path of a file with the given name in the user config
+// directory, or "" if the directory cannot be determined.
+func Path(name string) string {
+	d := defaultDir()
+	if d == "" {
+		return ""
+	}
+	return filepath.Join(d, name)
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func newTestStore(t *testing.T) *store {
@@ -206,3 +208,101 @@ func TestSaveJsonMarshalError(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestUpdatePreservesOtherFields(t *testing.T) {
+	SetDir(t.TempDir())
+	t.Cleanup(func() { SetDir("") })
+
+	if err := Save(Prefs{Region: "eu-west-1", Currency: "EUR"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := Update(func(p *Prefs) { p.Region = "us-west-2" }); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	got := Load()
+	if got.Region != "us-west-2" {
+		t.Errorf("expected us-west-2, got %q", got.Region)
+	}
+	if got.Currency != "EUR" {
+		t.Errorf("expected currency preserved as EUR, got %q", got.Currency)
+	}
+}
+
+func TestSaveAndLoadExchangeRates(t *testing.T) {
+	s := newTestStore(t)
+	p := Prefs{Currency: "GBP", Locale: "en-GB", ExchangeRates: map[string]float64{"GBP": 0.79}}
+
+	if err := s.save(p); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	got := s.load()
+	if got.Currency != "GBP" || got.Locale != "en-GB" || got.ExchangeRates["GBP"] != 0.79 {
+		t.Errorf("unexpected prefs: %+v", got)
+	}
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	SetDir(dir)
+	t.Cleanup(func() { SetDir("") })
+
+	if got := Path("exchange-rates.json"); got != filepath.Join(dir, "exchange-rates.json") {
+		t.Errorf("unexpected path %q", got)
+	}
+}
+
+func TestPathEmptyWhenDefaultDirEmpty(t *testing.T) {
+	unsetHOME(t)
+	SetDir("")
+
+	if got := Path("exchange-rates.json"); got != "" {
+		t.Errorf("expected empty path, got %q", got)
+	}
+}
+
+func TestUpdateReturnsNilWhenDefaultDirEmpty(t *testing.T) {
+	unsetHOME(t)
+	SetDir("")
+
+	if err := Update(func(p *Prefs) { p.Region = "us-east-1" }); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
+
+func TestCacheTTLDuration(t *testing.T) {
+	tests := []struct {
+		ttl  string
+		want time.Duration
+	}{
+		{"", 0},
+		{"12h", 12 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"soon", 0},
+		{"-1h", 0},
+	}
+	for _, tt := range tests {
+		if got := (Prefs{CacheTTL: tt.ttl}).CacheTTLDuration(); got != tt.want {
+			t.Errorf("CacheTTLDuration(%q) = %v, want %v", tt.ttl, got, tt.want)
+		}
+	}
+}
+
+func TestSaveAndLoadAWSCredentials(t *testing.T) {
+	s := newTestStore(t)
+	p := Prefs{AWSProfile: "prod", AWSRoleARN: "arn:aws:iam::123456789012:role/pricing", PricingEndpoint: "eu-central-1"}
+
+	if err := s.save(p); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(s.path())
+	if !strings.Contains(string(data), `"aws_profile":"prod"`) {
+		t.Errorf("expected the aws_profile key, got %s", data)
+	}
+	if got := s.load(); got.AWSProfile != p.AWSProfile || got.AWSRoleARN != p.AWSRoleARN || got.PricingEndpoint != p.PricingEndpoint {
+		t.Errorf("unexpected prefs: %+v", got)
+	}
+}
@@ -1,8 +1,11 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -16,16 +19,41 @@ var createProgram = func(model tea.Model, opts ...tea.ProgramOption) programRunn
 	return tea.NewProgram(model, opts...)
 }
 
-// Run starts the TUI application.
-func Run() error {
-	return RunWithIO(nil, nil)
+// removeAll is a seam so tests can fail the scratch cache cleanup.
+var removeAll = os.RemoveAll
+
+// Run starts the TUI application, configured by opts in place of the
+// matching preferences.
+func Run(opts Options) error {
+	return runSession(NewModelWithOptions(opts))
 }
 
 // RunWithIO starts the TUI with custom input and output.
 // Pass nil for defaults (stdin/stdout with alt screen).
 func RunWithIO(in io.Reader, out io.Writer) error {
-	model := NewModel()
+	return runModel(NewModel(), in, out)
+}
+
+// RunAsOf starts the TUI using the rates recorded in the local rate history
+// at asOf, configured by opts like Run.
+func RunAsOf(asOf time.Time, opts Options) error {
+	return runSession(NewModelAsOf(asOf, opts))
+}
+
+// runSession runs m, then removes its scratch cache if it has one,
+// reporting a cache it could not remove.
+func runSession(m Model) (err error) {
+	if m.scratchDir != "" {
+		defer func() {
+			if rmErr := removeAll(m.scratchDir); rmErr != nil {
+				err = errors.Join(err, fmt.Errorf("removing replay cache: %w", rmErr))
+			}
+		}()
+	}
+	return runModel(m, nil, nil)
+}
 
+func runModel(model Model, in io.Reader, out io.Writer) error {
 	var opts []tea.ProgramOption
 	if out != nil {
 		opts = append(opts, tea.WithOutput(out))
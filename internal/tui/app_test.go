@@ -3,10 +3,15 @@ package tui
 import (
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/josegonzalez/aws-eks-calculator/internal/prefs"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
 )
 
 type mockProgram struct {
@@ -63,11 +68,73 @@ func TestRunWithIONilInput(t *testing.T) {
 }
 
 func TestRun(t *testing.T) {
-	defer withMockProgram(nil)()
-	err := Run()
-	if err != nil {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	_ = prefs.Save(prefs.Prefs{AWSProfile: "dev"})
+
+	old := createProgram
+	defer func() { createProgram = old }()
+	var started Model
+	createProgram = func(model tea.Model, opts ...tea.ProgramOption) programRunner {
+		started = model.(Model)
+		return &mockProgram{}
+	}
+
+	creds := pricing.Credentials{Profile: "prod", RoleARN: "arn:aws:iam::123456789012:role/pricing"}
+	if err := Run(Options{Credentials: creds, Offline: true}); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
+	if got := started.client.Credentials(); got != creds || !started.offline {
+		t.Errorf("expected the options to replace the preferences, got %+v, offline %v", got, started.offline)
+	}
+}
+
+func TestRunReplayRemovesScratchCache(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	old := createProgram
+	defer func() { createProgram = old }()
+	var started Model
+	createProgram = func(model tea.Model, opts ...tea.ProgramOption) programRunner {
+		started = model.(Model)
+		return &mockProgram{}
+	}
+
+	if err := Run(Options{Replay: t.TempDir()}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(started.scratchDir); started.scratchDir == "" || !os.IsNotExist(err) {
+		t.Errorf("expected the scratch cache %q removed, got %v", started.scratchDir, err)
+	}
+
+	oldRemove := removeAll
+	defer func() { removeAll = oldRemove }()
+	removeAll = func(string) error { return fmt.Errorf("device busy") }
+	if err := Run(Options{Replay: t.TempDir()}); err == nil || err.Error() != "removing replay cache: device busy" {
+		t.Errorf("expected the cleanup failure reported, got %v", err)
+	}
+	_ = os.RemoveAll(started.scratchDir)
+}
+
+func TestRunAsOf(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	old := createProgram
+	defer func() { createProgram = old }()
+	var started Model
+	createProgram = func(model tea.Model, opts ...tea.ProgramOption) programRunner {
+		started = model.(Model)
+		return &mockProgram{}
+	}
+
+	if err := RunAsOf(time.Now(), Options{Offline: true}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !started.offline || started.asOf.IsZero() {
+		t.Errorf("expected an offline historical session, got offline %v, as of %v", started.offline, started.asOf)
+	}
 }
 
 func TestCreateProgramDefault(t *testing.T) {
@@ -3,6 +3,8 @@ package tui
 import (
 	"context"
 	"fmt"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -10,12 +12,13 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
-	"github.com/josegonzalez/aws-eks-calculator/internal/calculator"
-	"github.com/josegonzalez/aws-eks-calculator/internal/export"
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/export"
 	"github.com/josegonzalez/aws-eks-calculator/internal/prefs"
-	"github.com/josegonzalez/aws-eks-calculator/internal/pricing"
 	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
 	"github.com/josegonzalez/aws-eks-calculator/internal/tui/views"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
 )
 
 // viewState represents the current view in the TUI.
@@ -26,40 +29,63 @@ const (
 	viewCalculator
 	viewHelp
 	viewRegions
+	viewChargeback
+	viewPlanner
+	viewProfiles
+	viewCompare
 )
 
-// allRegions is the list of AWS regions available in the region picker.
-var allRegions = []string{
-	"us-east-1", "us-east-2", "us-west-1", "us-west-2",
-	"eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1", "eu-central-2", "eu-north-1", "eu-south-1", "eu-south-2",
-	"ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "ap-northeast-2", "ap-northeast-3", "ap-south-1", "ap-east-1",
-	"sa-east-1", "ca-central-1", "me-south-1", "af-south-1",
-}
-
 // clearExportMsg is sent after a delay to clear the export status message.
 type clearExportMsg struct{}
 
-// pricingMsg carries the result of an async pricing fetch.
+// pricingMsg carries the result of an async pricing fetch. id identifies
+// the request, so that responses to superseded requests can be dropped.
 type pricingMsg struct {
-	rates pricing.Rates
-	err   error
+	id        uint64
+	region    string
+	rates     pricing.RateSheet
+	anomalies []pricing.Anomaly
+	err       error
+}
+
+// staleRatesMsg carries expired cached rates to show while request id
+// fetches fresh ones.
+type staleRatesMsg struct {
+	id    uint64
+	rates pricing.RateSheet
 }
 
-// cacheWarmMsg is sent when background cache warming completes.
-type cacheWarmMsg struct{}
+// pricingFetch identifies the latest pricing request and the context it
+// runs under. Starting another cancels it; responses carrying an older id
+// are out of date.
+type pricingFetch struct {
+	id     uint64
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// regionsMsg carries the discovered region list.
+type regionsMsg struct {
+	regions []pricing.Region
+}
 
 // capabilityState holds per-capability TUI state.
 type capabilityState struct {
 	Inputs     []textinput.Model
 	FocusIndex int
 	Breakdown  calculator.CostBreakdown
+
+	// Chargeback tenants and the focused field within the tenant editor.
+	Tenants     []views.TenantRow
+	TenantFocus int
+	Allocation  calculator.AllocationPolicy
 }
 
 // Model represents the main TUI application state.
 type Model struct {
 	width  int
 	height int
-	view viewState
+	view   viewState
 
 	// Per-capability state
 	activeCapability calculator.Capability
@@ -69,59 +95,310 @@ type Model struct {
 	capSelectorCursor int
 
 	// Pricing state
-	rates         pricing.Rates
+	rates         pricing.RateSheet
 	ratesLoading  bool
 	ratesLoaded   bool
 	ratesErr      error
 	pricingRegion string
-	cacheWarmed   bool
+	fetch         pricingFetch
+
+	// anomalies are the suspect rates met resolving the current region.
+	// With rejectSuspect they were replaced by the next source's rates.
+	anomalies     []pricing.Anomaly
+	rejectSuspect bool
+
+	// With staleWhileRevalidate, expired cached rates are shown while a
+	// fetch runs; revalidating is set while they are on screen.
+	staleWhileRevalidate bool
+	revalidating         bool
+
+	// Background cache warming: started once, after the first successful
+	// fetch. warmRegions limits it to the listed regions.
+	cacheWarmed  bool
+	warm         warmState
+	warmRegions  []string
+	warmDisabled bool
 
 	// Region picker state
 	regionCursor int
-	allRegions   []string
+	regionFilter textinput.Model
+	allRegions   []pricing.Region
+
+	// client calls the Pricing API for the chain and region discovery. The
+	// profile picker switches its credentials.
+	client *pricing.Client
+
+	// Profile picker state. profiles lists the shared config profiles,
+	// after "" for the default credential chain.
+	profiles      []string
+	profileCursor int
+	profilesErr   error
+	listProfiles  func() ([]string, error)
+
+	// Budget planner
+	budgetInput textinput.Model
+
+	// Region comparison order
+	compareSort views.CompareSort
+
+	// Rows of the region picker, region comparison and budget planner, set
+	// by refreshRows so that rendering does not price every region.
+	pickerRows     []views.RegionRow
+	compareCosts   []regionCost
+	compareOther   []string
+	planCapRows    []views.PlanRow
+	planRegionRows []views.PlanRow
+
+	// Display currency. Amounts are calculated in the currency of the rates
+	// and converted for rendering and export: currency is the chosen
+	// formatter, money the one for the current rates.
+	currency      currency.Formatter
+	money         currency.Formatter
+	exchangeRates currency.Rates
+	locale        string
 
 	// Export
 	exportDir string // directory for export files; empty means current dir
 	exportMsg string
 
-	// priceFetcher abstracts the pricing fetch for testing.
-	priceFetcher func(ctx context.Context, region string) (pricing.Rates, error)
+	// provider supplies live rates; swapped out in tests.
+	provider pricing.Provider
+
+	// overridesErr reports an unreadable or invalid rate overrides file.
+	overridesErr error
+
+	// cachedRates looks up previously fetched rates for a region without
+	// calling AWS. Returns nil if the region has not been fetched.
+	cachedRates func(region string) *pricing.RateSheet
+
+	// cacheEntry returns a region's cached rates whatever their age.
+	cacheEntry func(region string) (pricing.CacheEntry, bool)
+
+	// listRegions discovers the region list; swapped out in tests.
+	listRegions func(ctx context.Context) ([]pricing.Region, error)
+
+	// rateHistory returns the recorded rate snapshots for a region.
+	rateHistory func(region string) ([]pricing.Snapshot, error)
+
+	// rateChanges lists how the current rates differ from the last
+	// snapshot recorded before today, taken at changesSince.
+	rateChanges  []pricing.RateChange
+	changesSince time.Time
+
+	// asOf is set when the calculator uses historical rates.
+	asOf time.Time
+
+	// offline is set when the calculator must not call AWS.
+	offline bool
+
+	// fixtures tags the title when Pricing API responses are replayed or
+	// recorded. scratchDir holds the cache of a replayed session.
+	fixtures   string
+	scratchDir string
 
 	quitting bool
 }
 
-// NewModel creates a new TUI model with default values.
+// Options configure a session. They take the place of the matching
+// preferences.
+type Options struct {
+	// Credentials select the AWS credentials for live pricing.
+	Credentials pricing.Credentials
+
+	// Endpoint is the region of the Pricing API endpoint to try first.
+	// Empty means pricing.DefaultEndpoint.
+	Endpoint string
+
+	// Offline keeps the calculator from calling AWS. Rates come from the
+	// cache, whatever its age, offer files, overrides and the defaults.
+	Offline bool
+
+	// Replay is a directory of Pricing API responses saved with Record.
+	// Rates and the region list are replayed from it instead of calling
+	// AWS, starting from an empty cache that is removed on exit.
+	Replay string
+
+	// Record is a directory to save every Pricing API response to, for a
+	// later Replay.
+	Record string
+}
+
+// PricingAPI returns the Pricing API to call in place of client: a
+// Replayer or Recorder for the directory in Replay or Record, or client
+// itself.
+func (o Options) PricingAPI(client *pricing.Client) pricing.PricingAPI {
+	switch {
+	case o.Replay != "":
+		return pricing.NewReplayer(o.Replay)
+	case o.Record != "":
+		return pricing.NewRecorder(client, o.Record)
+	}
+	return client
+}
+
+// NewModel creates a new TUI model with default values, configured by the
+// preferences.
 func NewModel() Model {
+	p := prefs.Load()
+	return newModel(p, Options{
+		Credentials: pricing.Credentials{Profile: p.AWSProfile, RoleARN: p.AWSRoleARN},
+		Endpoint:    p.PricingEndpoint,
+		Offline:     p.Offline,
+	})
+}
+
+// NewModelWithOptions creates a new TUI model configured by the preferences
+// and opts.
+func NewModelWithOptions(opts Options) Model {
+	return newModel(prefs.Load(), opts)
+}
+
+func newModel(p prefs.Prefs, opts Options) Model {
 	capStates := make(map[calculator.Capability]*capabilityState)
 	for _, cap := range calculator.AllCapabilities {
 		capStates[cap] = newCapabilityState(cap)
 	}
 
+	// Until discovery completes, the picker offers the cached region list
+	// or, failing that, the built-in one. Offline, there is no discovery.
+	// A replayed session must not see, or add to, the user's cache.
+	cache := pricing.NewCache()
+	var scratchDir string
+	if opts.Replay != "" {
+		if dir, err := os.MkdirTemp("", "aws-eks-calculator-replay-"); err == nil {
+			cache, scratchDir = pricing.NewCacheDir(dir), dir
+		}
+	}
+	cache.SetTTL(p.CacheTTLDuration())
+	regions := cache.LoadRegions()
+	if regions == nil {
+		regions = pricing.BuiltinRegions()
+	}
+
 	region := "us-east-1"
-	if p := prefs.Load(); p.Region != "" && containsRegion(allRegions, p.Region) {
+	if p.Region != "" && containsRegion(regions, p.Region) {
 		region = p.Region
 	}
 
+	// A missing or unreadable rates file leaves only the prefs overrides;
+	// an unknown currency or locale falls back to USD.
+	fileRates, _ := currency.LoadRates(prefs.Path(currency.RatesFile))
+	exchangeRates := fileRates.Merge(p.ExchangeRates)
+	cur, _ := currency.New(p.Currency, p.Locale, exchangeRates)
+
+	// An invalid rate source configuration falls back to the default order.
+	// Sources fail silently when a later one covers for them, so the
+	// overrides file is checked up front to report mistakes in it.
+	client := pricing.NewClient(opts.Credentials)
+	client.SetEndpoint(opts.Endpoint)
+	api := opts.PricingAPI(client)
+	chainCfg := pricing.ChainConfig{
+		Cache:         cache,
+		Client:        api,
+		OfferFiles:    p.OfferFiles,
+		OverridesFile: prefs.Path(pricing.OverridesFile),
+		Offline:       opts.Offline,
+		RejectSuspect: p.RejectSuspectRates,
+	}
+	chain, err := pricing.BuildChain(p.RateSources, chainCfg)
+	if err != nil {
+		chain, _ = pricing.BuildChain(nil, chainCfg)
+	}
+	_, overridesErr := pricing.LoadOverrides(chainCfg.OverridesFile)
+
 	m := Model{
 		activeCapability: calculator.CapabilityArgoCD,
 		capStates:        capStates,
-		allRegions:       allRegions,
-		rates:            pricing.DefaultRates(),
+		allRegions:       regions,
+		rates:            pricing.RateSheet{Rates: pricing.DefaultRates()},
 		ratesLoading:     true,
 		pricingRegion:    region,
 		view:             viewCapabilitySelector,
-		priceFetcher:     pricing.FetchRates,
+		provider:         chain,
+		overridesErr:     overridesErr,
+		cachedRates:      freshRates(cache),
+		cacheEntry:       cache.Entry,
+		listRegions: func(ctx context.Context) ([]pricing.Region, error) {
+			return pricing.Regions(ctx, api, cache)
+		},
+		client:        client,
+		listProfiles:  pricing.Profiles,
+		rateHistory:   cache.History,
+		warmRegions:   p.WarmRegions,
+		warmDisabled:  p.DisableWarming || opts.Offline,
+		budgetInput:   newFloatInput("1000"),
+		regionFilter:  newTextInput(""),
+		currency:      cur,
+		exchangeRates: exchangeRates,
+		locale:        p.Locale,
+
+		staleWhileRevalidate: p.StaleWhileRevalidate && !opts.Offline,
+		offline:              opts.Offline,
+		fixtures:             fixturesTag(opts),
+		scratchDir:           scratchDir,
+		rejectSuspect:        p.RejectSuspectRates,
 	}
 
+	// Init issues the startup fetch but cannot record it on the model, so
+	// its context is created here for quitting or a new fetch to cancel.
+	m.fetch.ctx, m.fetch.cancel = context.WithCancel(context.Background())
+
+	m.applyCurrency()
 	m.applyLiveRates()
 	m.recalculate()
 
 	return m
 }
 
-func containsRegion(regions []string, region string) bool {
+// NewModelAsOf creates a TUI model configured by the preferences and opts
+// that uses the rates recorded in the local rate history at asOf, falling
+// back to the built-in defaults for regions without history.
+func NewModelAsOf(asOf time.Time, opts Options) Model {
+	m := NewModelWithOptions(opts)
+	cache := pricing.NewCache()
+	m.asOf = asOf
+	chain := pricing.NewChain(pricing.HistorySource(cache, asOf), pricing.DefaultsSource())
+	chain.SetRejectSuspect(m.rejectSuspect)
+	m.provider = chain
+	m.warmDisabled = true // history is local; there is nothing to fetch
+	m.staleWhileRevalidate = false
+	m.cachedRates = func(region string) *pricing.RateSheet {
+		history, _ := cache.History(region)
+		s, ok := pricing.SnapshotAt(history, asOf)
+		if !ok {
+			return nil
+		}
+		return &s.Rates
+	}
+	return m
+}
+
+// freshRates returns a lookup of the rates cache holds for a region, with
+// their provenance, or nil if they have expired.
+func freshRates(cache *pricing.Cache) func(region string) *pricing.RateSheet {
+	return func(region string) *pricing.RateSheet {
+		entry, ok := cache.Entry(region)
+		if !ok || entry.Expired {
+			return nil
+		}
+		return &entry.Rates
+	}
+}
+
+// fixturesTag returns the title tag for the fixture mode of opts, or "".
+func fixturesTag(opts Options) string {
+	switch {
+	case opts.Replay != "":
+		return "[replay]"
+	case opts.Record != "":
+		return "[recording]"
+	}
+	return ""
+}
+
+func containsRegion(regions []pricing.Region, region string) bool {
 	for _, r := range regions {
-		if r == region {
+		if r.Code == region {
 			return true
 		}
 	}
@@ -165,6 +442,18 @@ func newCapabilityState(cap calculator.Capability) *capabilityState {
 	inputs[idx] = newFloatInput(fmt.Sprintf("%.4f", defaults.SelfManagedVCPUCostPerHour))
 	idx++
 	inputs[idx] = newFloatInput(fmt.Sprintf("%.4f", defaults.SelfManagedMemGBCostPerHour))
+	idx++
+
+	// ArgoCD-only: ancillary self-managed infrastructure
+	if cap == calculator.CapabilityArgoCD {
+		inputs[idx] = newIntInput(fmt.Sprintf("%d", defaults.SelfManagedALBsPerCluster))
+		idx++
+		inputs[idx] = newFloatInput(fmt.Sprintf("%.1f", defaults.SelfManagedLCUsPerALB))
+		idx++
+		inputs[idx] = newFloatInput(fmt.Sprintf("%.0f", defaults.SelfManagedEBSGBPerCluster))
+		idx++
+		inputs[idx] = newFloatInput(fmt.Sprintf("%.1f", defaults.SelfManagedLogsGBPerCluster))
+	}
 
 	inputs[0].Focus()
 	inputs[0].TextStyle = styles.FocusedInputStyle
@@ -182,6 +471,14 @@ func newIntInput(value string) textinput.Model {
 	return ti
 }
 
+func newTextInput(value string) textinput.Model {
+	ti := textinput.New()
+	ti.SetValue(value)
+	ti.Width = 16
+	ti.CharLimit = 32
+	return ti
+}
+
 func newFloatInput(value string) textinput.Model {
 	ti := textinput.New()
 	ti.SetValue(value)
@@ -192,35 +489,94 @@ func newFloatInput(value string) textinput.Model {
 
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(textinput.Blink, m.fetchPricingCmd(m.pricingRegion))
+	fetchCmd := m.fetchPricingCmd(m.fetch.ctx, m.fetch.id, m.pricingRegion)
+	return tea.Batch(textinput.Blink, fetchCmd, m.staleRatesCmd(m.fetch.id, m.pricingRegion), m.fetchRegionsCmd())
 }
 
-func (m Model) fetchPricingCmd(region string) tea.Cmd {
-	fetcher := m.priceFetcher
+// fetchRegionsCmd discovers the regions where the EKS capabilities are sold.
+// A failed discovery leaves the current list in place. Offline, and with
+// historical rates, it does nothing: the cached or built-in list is used.
+func (m Model) fetchRegionsCmd() tea.Cmd {
+	if m.offline || !m.asOf.IsZero() {
+		return nil
+	}
+	listRegions := m.listRegions
+	timeout := pricing.CallTimeout(m.client.Endpoint())
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
-		rates, err := fetcher(ctx, region)
-		return pricingMsg{rates: rates, err: err}
+		regions, err := listRegions(ctx)
+		if err != nil {
+			return regionsMsg{}
+		}
+		return regionsMsg{regions: regions}
 	}
 }
 
-// warmCacheCmd fetches pricing for all regions except skip, populating the
-// on-disk cache so that future region switches are instant.
-func (m Model) warmCacheCmd(regions []string, skip string) tea.Cmd {
-	fetcher := m.priceFetcher
+// fetchPricing starts fetching the rates for region, superseding any fetch
+// still in flight: it is cancelled, and its response dropped should it
+// arrive anyway.
+func (m *Model) fetchPricing(region string) tea.Cmd {
+	m.cancelFetch()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.fetch = pricingFetch{id: m.fetch.id + 1, ctx: ctx, cancel: cancel}
+	m.revalidating = false
+	return m.fetchPricingCmd(ctx, m.fetch.id, region)
+}
+
+// staleRatesCmd returns a command delivering region's cached rates for
+// request id if stale-while-revalidate is on and they have expired, and nil
+// otherwise. Fresh cached rates need no stand-in: the fetch returns them at
+// once.
+func (m Model) staleRatesCmd(id uint64, region string) tea.Cmd {
+	if !m.staleWhileRevalidate {
+		return nil
+	}
+	entry, ok := m.cacheEntry(region)
+	if !ok || !entry.Expired {
+		return nil
+	}
+	rates := entry.Cached()
+	return func() tea.Msg { return staleRatesMsg{id: id, rates: rates} }
+}
+
+// cancelFetch cancels the pricing fetch in flight, if any.
+func (m *Model) cancelFetch() {
+	if m.fetch.cancel != nil {
+		m.fetch.cancel()
+	}
+}
+
+// fetchPricingCmd fetches the rates for region under ctx, tagging the
+// response with id. The fetch is given long enough to retry and fail over
+// across the Pricing API endpoints.
+func (m Model) fetchPricingCmd(ctx context.Context, id uint64, region string) tea.Cmd {
+	provider := m.provider
 	return func() tea.Msg {
-		for _, region := range regions {
-			if region == skip {
-				continue
-			}
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			_, _ = fetcher(ctx, region)
-			cancel()
+		ctx, cancel := context.WithTimeout(ctx, pricing.CallTimeout(region))
+		defer cancel()
+
+		rates, anomalies, err := resolveRates(ctx, provider, region)
+		return pricingMsg{id: id, region: region, rates: rates, anomalies: anomalies, err: err}
+	}
+}
+
+// resolveRates returns the provider's rates for region and the suspect
+// rates among them. A chain always completes with the built-in defaults, so
+// its error alone would not report a failed fetch; the fetch error behind
+// any fallback is returned instead.
+func resolveRates(ctx context.Context, provider pricing.Provider, region string) (pricing.RateSheet, []pricing.Anomaly, error) {
+	if chain, ok := provider.(*pricing.Chain); ok {
+		res, err := chain.Resolve(ctx, region)
+		if err == nil {
+			err = res.FallbackErr()
 		}
-		return cacheWarmMsg{}
+		return res.Rates, res.Anomalies, err
 	}
+	rates, err := provider.Rates(ctx, region)
+	sheet := pricing.RateSheet{Rates: rates}
+	return sheet, pricing.CheckRates(sheet), err
 }
 
 // activeState returns the capabilityState for the currently active capability.
@@ -241,27 +597,85 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case pricingMsg:
-		m.ratesLoading = false
+		if msg.id != m.fetch.id {
+			return m, nil // superseded by a later region switch
+		}
+		revalidating := m.revalidating
+		m.ratesLoading, m.revalidating = false, false
+		if msg.err != nil && revalidating {
+			// The expired rates on screen beat the defaults a failed
+			// fetch falls back to.
+			m.ratesErr = msg.err
+			return m, nil
+		}
 		m.rates = msg.rates
+		m.anomalies = msg.anomalies
+		m.applyCurrency()
 		m.applyLiveRates()
 		m.recalculate()
+		m.refreshRows()
+		m.rateChanges, m.changesSince = m.recentRateChanges(time.Now())
 		if msg.err == nil {
 			m.ratesLoaded = true
 			m.ratesErr = nil
 			if !m.cacheWarmed {
 				m.cacheWarmed = true
-				return m, m.warmCacheCmd(m.allRegions, m.pricingRegion)
+				return m, m.startWarming()
 			}
 		} else {
 			m.ratesErr = msg.err
 		}
 		return m, nil
 
+	case staleRatesMsg:
+		// Only stand in while the request they belong to is still loading.
+		if msg.id != m.fetch.id || !m.ratesLoading {
+			return m, nil
+		}
+		m.ratesLoading, m.revalidating = false, true
+		m.rates = msg.rates
+		m.anomalies = pricing.CheckRates(msg.rates)
+		m.applyCurrency()
+		m.applyLiveRates()
+		m.recalculate()
+		m.refreshRows()
+		return m, nil
+
+	case warmProgressMsg:
+		m.warm.done++
+		if msg.err != nil {
+			m.warm.failed++
+		}
+		// Every other region would fail the same way.
+		if pricing.Classify(msg.err) == pricing.ErrorAuth {
+			m.stopWarming()
+		}
+		// Another region's rates are now cached.
+		m.refreshRows()
+		return m, waitForWarm(m.warm.updates)
+
 	case cacheWarmMsg:
+		m.stopWarming()
+		m.warm.updates = nil
+		return m, nil
+
+	case regionsMsg:
+		if len(msg.regions) > 0 {
+			m.allRegions = msg.regions
+			if n := len(m.filteredRegions()); m.regionCursor >= n {
+				m.regionCursor = max(n-1, 0)
+			}
+			m.refreshRows()
+		}
 		return m, nil
 
 	case tea.KeyMsg:
-		return m.handleKeyPress(msg)
+		next, cmd := m.handleKeyPress(msg)
+		if nm := next.(Model); nm.quitting {
+			nm.cancelFetch()
+			nm.stopWarming()
+		}
+		return next, cmd
 	}
 
 	// Update focused text input
@@ -288,6 +702,14 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleHelpKeys(msg)
 	case viewRegions:
 		return m.handleRegionKeys(msg)
+	case viewProfiles:
+		return m.handleProfileKeys(msg)
+	case viewChargeback:
+		return m.handleChargebackKeys(msg)
+	case viewPlanner:
+		return m.handlePlannerKeys(msg)
+	case viewCompare:
+		return m.handleCompareKeys(msg)
 	}
 	return m, nil
 }
@@ -360,10 +782,36 @@ func (m Model) handleCalculatorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "r":
-		m.view = viewRegions
-		m.regionCursor = 0
+		cmd := m.openRegions()
+		return m, cmd
+
+	case "p":
+		m.openProfiles()
+		return m, nil
+
+	case "c":
+		m.view = viewChargeback
+		cmd := m.updateTenantFocus()
+		return m, cmd
+
+	case "b":
+		m.view = viewPlanner
+		m.refreshRows()
+		cmd := m.budgetInput.Focus()
+		return m, cmd
+
+	case "R":
+		m.view = viewCompare
+		m.refreshRows()
 		return m, nil
 
+	case "$":
+		m.cycleCurrency()
+		return m, nil
+
+	case "!":
+		return m, m.toggleSuspectRates()
+
 	case "e":
 		return m.doExport()
 
@@ -379,6 +827,47 @@ func (m Model) handleCalculatorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// cycleCurrency switches to the next currency with a known exchange rate
+// and remembers the choice.
+func (m *Model) cycleCurrency() {
+	codes := m.exchangeRates.Codes()
+	next := codes[0]
+	for i, code := range codes {
+		if code == m.currency.Code {
+			next = codes[(i+1)%len(codes)]
+			break
+		}
+	}
+
+	cur, err := currency.New(next, m.locale, m.exchangeRates)
+	if err != nil {
+		return
+	}
+	m.currency = cur
+	m.applyCurrency()
+	_ = prefs.Update(func(p *prefs.Prefs) { p.Currency = next })
+}
+
+// toggleSuspectRates switches between using and rejecting suspect rates,
+// remembers the choice and resolves the current region again with it.
+func (m *Model) toggleSuspectRates() tea.Cmd {
+	m.rejectSuspect = !m.rejectSuspect
+	if chain, ok := m.provider.(*pricing.Chain); ok {
+		chain.SetRejectSuspect(m.rejectSuspect)
+	}
+	reject := m.rejectSuspect
+	_ = prefs.Update(func(p *prefs.Prefs) { p.RejectSuspectRates = reject })
+	m.ratesLoading = true
+	return m.fetchPricing(m.pricingRegion)
+}
+
+// applyCurrency sets the formatter for the current rates: the chosen
+// currency, converted from the currency the rates are in. Without an
+// exchange rate for that currency, amounts are shown in it unconverted.
+func (m *Model) applyCurrency() {
+	m.money, _ = m.currency.WithBase(m.rates.Currency, m.exchangeRates)
+}
+
 func (m *Model) switchCapability(cap calculator.Capability) {
 	m.activeCapability = cap
 	m.recalculate()
@@ -394,30 +883,212 @@ func capabilityIndex(cap calculator.Capability) int {
 }
 
 func (m Model) handleRegionKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	regions := m.filteredRegions()
 	switch msg.String() {
-	case "esc", "q":
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
 		m.view = viewCalculator
+		m.regionFilter.Blur()
 		return m, nil
-	case "up", "k":
+	case "up":
 		if m.regionCursor > 0 {
 			m.regionCursor--
 		}
 		return m, nil
-	case "down", "j":
-		if m.regionCursor < len(m.allRegions)-1 {
+	case "down":
+		if m.regionCursor < len(regions)-1 {
 			m.regionCursor++
 		}
 		return m, nil
 	case "enter":
-		selected := m.allRegions[m.regionCursor]
+		if len(regions) == 0 {
+			return m, nil
+		}
+		selected := regions[m.regionCursor].Code
 		m.view = viewCalculator
+		m.regionFilter.Blur()
 		if selected != m.pricingRegion {
 			m.pricingRegion = selected
 			m.ratesLoading = true
-			_ = prefs.Save(prefs.Prefs{Region: selected})
-			return m, m.fetchPricingCmd(selected)
+			_ = prefs.Update(func(p *prefs.Prefs) { p.Region = selected })
+			fetch := m.fetchPricing(selected)
+			return m, tea.Batch(fetch, m.staleRatesCmd(m.fetch.id, selected))
+		}
+		return m, nil
+	}
+
+	// Anything else edits the filter. The cursor stays on its region while
+	// the region still matches.
+	var current string
+	if m.regionCursor < len(regions) {
+		current = regions[m.regionCursor].Code
+	}
+	var cmd tea.Cmd
+	m.regionFilter, cmd = m.regionFilter.Update(msg)
+	m.regionCursor = 0
+	for i, r := range m.filteredRegions() {
+		if r.Code == current {
+			m.regionCursor = i
+		}
+	}
+	m.refreshRows()
+	return m, cmd
+}
+
+// openProfiles shows the profile picker with the cursor on the profile in
+// use.
+func (m *Model) openProfiles() {
+	m.view = viewProfiles
+	names, err := m.listProfiles()
+	m.profiles = append([]string{""}, names...)
+	m.profilesErr = err
+	m.profileCursor = 0
+	current := m.client.Credentials().Profile
+	for i, name := range m.profiles {
+		if name == current {
+			m.profileCursor = i
+		}
+	}
+}
+
+func (m Model) handleProfileKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.view = viewCalculator
+		return m, nil
+	case "up", "k":
+		if m.profileCursor > 0 {
+			m.profileCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.profileCursor < len(m.profiles)-1 {
+			m.profileCursor++
+		}
+		return m, nil
+	case "enter":
+		selected := m.profiles[m.profileCursor]
+		m.view = viewCalculator
+		creds := m.client.Credentials()
+		if selected == creds.Profile {
+			return m, nil
+		}
+		// Rates already fetched stay valid: prices do not depend on the
+		// caller. Refetching retries a fetch the old profile failed.
+		creds.Profile = selected
+		m.client.SetCredentials(creds)
+		_ = prefs.Update(func(p *prefs.Prefs) { p.AWSProfile = selected })
+		m.ratesLoading = true
+		fetch := m.fetchPricing(m.pricingRegion)
+		return m, tea.Batch(fetch, m.fetchRegionsCmd())
+	}
+	return m, nil
+}
+
+func (m Model) handleChargebackKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	cs := m.activeState()
+	fields := 2 * len(cs.Tenants)
+
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+
+	case "esc":
+		m.view = viewCalculator
+		return m, nil
+
+	case "tab", "down":
+		if fields > 0 {
+			cs.TenantFocus = (cs.TenantFocus + 1) % fields
+		}
+		return m, m.updateTenantFocus()
+
+	case "shift+tab", "up":
+		if fields > 0 {
+			cs.TenantFocus = (cs.TenantFocus - 1 + fields) % fields
+		}
+		return m, m.updateTenantFocus()
+
+	case "ctrl+n":
+		cs.Tenants = append(cs.Tenants, views.TenantRow{
+			Name:      newTextInput(fmt.Sprintf("team-%d", len(cs.Tenants)+1)),
+			Resources: newIntInput("0"),
+		})
+		cs.TenantFocus = 2 * (len(cs.Tenants) - 1)
+		m.recalculate()
+		return m, m.updateTenantFocus()
+
+	case "ctrl+d":
+		if fields == 0 {
+			return m, nil
+		}
+		idx := cs.TenantFocus / 2
+		cs.Tenants = append(cs.Tenants[:idx], cs.Tenants[idx+1:]...)
+		if cs.TenantFocus >= 2*len(cs.Tenants) {
+			cs.TenantFocus = max(0, 2*len(cs.Tenants)-2)
 		}
+		m.recalculate()
+		return m, m.updateTenantFocus()
+
+	case "ctrl+p":
+		policies := calculator.AllAllocationPolicies
+		cs.Allocation = policies[(int(cs.Allocation)+1)%len(policies)]
+		m.recalculate()
+		return m, nil
+	}
+
+	if fields == 0 {
+		return m, nil
+	}
+
+	// Pass key to focused tenant input
+	var cmd tea.Cmd
+	row := &cs.Tenants[cs.TenantFocus/2]
+	if cs.TenantFocus%2 == 0 {
+		row.Name, cmd = row.Name.Update(msg)
+	} else {
+		row.Resources, cmd = row.Resources.Update(msg)
+	}
+	m.recalculate()
+	return m, cmd
+}
+
+func (m Model) handlePlannerKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.view = viewCalculator
+		m.budgetInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.budgetInput, cmd = m.budgetInput.Update(msg)
+	m.refreshRows()
+	return m, cmd
+}
+
+func (m Model) handleCompareKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.view = viewCalculator
 		return m, nil
+	case "s":
+		m.compareSort = m.compareSort.Next()
+		m.refreshRows()
+		return m, nil
+	case "f":
+		return m, m.fetchMissingRegions()
+	case "e":
+		return m.exportComparison()
 	}
 	return m, nil
 }
@@ -449,6 +1120,24 @@ func (m *Model) updateFocus() tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+func (m *Model) updateTenantFocus() tea.Cmd {
+	cs := m.activeState()
+	var cmds []tea.Cmd
+	for i := range cs.Tenants {
+		row := &cs.Tenants[i]
+		for j, ti := range []*textinput.Model{&row.Name, &row.Resources} {
+			if 2*i+j == cs.TenantFocus {
+				cmds = append(cmds, ti.Focus())
+				ti.TextStyle = styles.FocusedInputStyle
+			} else {
+				ti.Blur()
+				ti.TextStyle = styles.BlurredInputStyle
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
 func (m *Model) recalculate() {
 	cs := m.activeState()
 	input := m.buildInput()
@@ -456,19 +1145,28 @@ func (m *Model) recalculate() {
 }
 
 func (m *Model) buildInput() calculator.ScenarioInput {
-	cs := m.activeState()
-	cap := m.activeCapability
-	base, resource := m.rates.ForCapability(cap)
+	return m.buildInputFor(m.activeCapability, m.pricingRegion, m.rates)
+}
+
+// buildInputFor builds the scenario for a capability from its inputs, priced
+// with the given region's rates.
+func (m *Model) buildInputFor(cap calculator.Capability, region string, rates pricing.RateSheet) calculator.ScenarioInput {
+	cs := m.capStates[cap]
+	base, resource := rates.ForCapability(cap)
 
 	input := calculator.ScenarioInput{
-		Name:            "Custom",
-		Capability:      cap,
-		NumClusters:     parseInt(cs.Inputs[0].Value()),
+		Name:                "Custom",
+		Capability:          cap,
+		NumClusters:         parseInt(cs.Inputs[0].Value()),
 		ResourcesPerCluster: parseInt(cs.Inputs[1].Value()),
-		HoursPerMonth:   parseFloat(cs.Inputs[2].Value()),
-		Region:          m.pricingRegion,
-		BasePerHour:     base,
-		ResourcePerHour: resource,
+		HoursPerMonth:       parseFloat(cs.Inputs[2].Value()),
+		Region:              region,
+		Currency:            rates.Currency,
+		BasePerHour:         base,
+		ResourcePerHour:     resource,
+	}
+	if _, resField := pricing.FieldsForCapability(cap); rates.Tiers[resField] != nil {
+		input.ResourceTiers = rates.Tiers[resField].RateTiers()
 	}
 
 	if cap == calculator.CapabilityArgoCD {
@@ -476,18 +1174,234 @@ func (m *Model) buildInput() calculator.ScenarioInput {
 		input.ClustersPerTemplate = parseInt(cs.Inputs[4].Value())
 		input.SelfManagedVCPUPerCluster = parseFloat(cs.Inputs[5].Value())
 		input.SelfManagedMemGBPerCluster = parseFloat(cs.Inputs[6].Value())
-		input.SelfManagedVCPUCostPerHour = parseFloat(cs.Inputs[7].Value())
-		input.SelfManagedMemGBCostPerHour = parseFloat(cs.Inputs[8].Value())
+		input.SelfManagedVCPUCostPerHour = m.selfManagedRate(cs.Inputs[7], region, m.rates.FargateVCPUPerHour, rates.FargateVCPUPerHour)
+		input.SelfManagedMemGBCostPerHour = m.selfManagedRate(cs.Inputs[8], region, m.rates.FargateMemGBPerHour, rates.FargateMemGBPerHour)
+		input.SelfManagedALBsPerCluster = parseInt(cs.Inputs[9].Value())
+		input.SelfManagedLCUsPerALB = parseFloat(cs.Inputs[10].Value())
+		input.SelfManagedEBSGBPerCluster = parseFloat(cs.Inputs[11].Value())
+		input.SelfManagedLogsGBPerCluster = parseFloat(cs.Inputs[12].Value())
+		input.SelfManagedALBCostPerHour = rates.ALBPerHour
+		input.SelfManagedLCUCostPerHour = rates.ALBLCUPerHour
+		input.SelfManagedEBSCostPerGBMonth = rates.EBSGBMonth
+		input.SelfManagedLogsCostPerGB = rates.CloudWatchLogsPerGB
 	} else {
 		input.SelfManagedVCPUPerCluster = parseFloat(cs.Inputs[3].Value())
 		input.SelfManagedMemGBPerCluster = parseFloat(cs.Inputs[4].Value())
-		input.SelfManagedVCPUCostPerHour = parseFloat(cs.Inputs[5].Value())
-		input.SelfManagedMemGBCostPerHour = parseFloat(cs.Inputs[6].Value())
+		input.SelfManagedVCPUCostPerHour = m.selfManagedRate(cs.Inputs[5], region, m.rates.FargateVCPUPerHour, rates.FargateVCPUPerHour)
+		input.SelfManagedMemGBCostPerHour = m.selfManagedRate(cs.Inputs[6], region, m.rates.FargateMemGBPerHour, rates.FargateMemGBPerHour)
+	}
+
+	input.Allocation = cs.Allocation
+	for i, row := range cs.Tenants {
+		name := strings.TrimSpace(row.Name.Value())
+		if name == "" {
+			name = fmt.Sprintf("team-%d", i+1)
+		}
+		input.Tenants = append(input.Tenants, calculator.Tenant{
+			Name:      name,
+			Resources: parseInt(row.Resources.Value()),
+		})
 	}
 
 	return input
 }
 
+// selfManagedRate returns the Fargate rate from input in to price region
+// with. The inputs are filled with current, the current region's rate, so
+// other regions use their own rate, regional, unless the user has edited
+// the input.
+func (m *Model) selfManagedRate(in textinput.Model, region string, current, regional float64) float64 {
+	if region != m.pricingRegion && in.Value() == fmt.Sprintf("%.4f", current) {
+		return regional
+	}
+	return parseFloat(in.Value())
+}
+
+// planRows evaluates the budget against every capability in the current
+// region and against the active capability in every region, using cached
+// rates for regions other than the current one. Regions whose rates are in
+// another currency are left out.
+func (m *Model) planRows() (capRows, regionRows []views.PlanRow) {
+	// The budget is entered in the display currency; plans are in the
+	// currency of the rates.
+	budget := m.money.ToBase(parseFloat(m.budgetInput.Value()))
+
+	for _, cap := range calculator.AllCapabilities {
+		input := m.buildInputFor(cap, m.pricingRegion, m.rates)
+		capRows = append(capRows, views.PlanRow{
+			Label:    cap.String(),
+			Capacity: calculator.Plan(input, budget),
+			Current:  cap == m.activeCapability,
+		})
+	}
+
+	for _, region := range m.allRegions {
+		row := views.PlanRow{Label: region.Code, Current: region.Code == m.pricingRegion}
+		rates := m.rates
+		if !row.Current {
+			cached := m.cachedRates(region.Code)
+			if cached == nil {
+				row.Missing = true
+				regionRows = append(regionRows, row)
+				continue
+			}
+			if cached.CurrencyCode() != m.rates.CurrencyCode() {
+				continue
+			}
+			rates = *cached
+		}
+		row.Capacity = calculator.Plan(m.buildInputFor(m.activeCapability, region.Code, rates), budget)
+		regionRows = append(regionRows, row)
+	}
+
+	return capRows, regionRows
+}
+
+// regionCost is the active scenario priced in one region.
+type regionCost struct {
+	views.CompareRow
+	input calculator.ScenarioInput
+	rates pricing.RateSheet
+}
+
+// compareRegions prices the active scenario in every known region, with
+// the current rates for the current region and cached rates for the
+// others, ordered by compareSort. Regions priced in another currency
+// cannot be ranked with the rest; their codes are returned apart.
+func (m *Model) compareRegions() (costs []regionCost, otherCurrency []string) {
+	for _, region := range m.allRegions {
+		c, currency := m.priceRegion(region)
+		if currency != "" {
+			otherCurrency = append(otherCurrency, region.Code)
+			continue
+		}
+		costs = append(costs, c)
+	}
+
+	slices.SortStableFunc(costs, func(a, b regionCost) int {
+		return m.compareSort.Compare(a.CompareRow, b.CompareRow)
+	})
+	return costs, otherCurrency
+}
+
+// priceRegion prices the active scenario in region, with the current rates
+// for the current region and cached rates for the others. A region without
+// rates is Missing. A region priced in another currency than the current
+// rates is not priced, and its currency is returned.
+func (m *Model) priceRegion(region pricing.Region) (c regionCost, currency string) {
+	c = regionCost{CompareRow: views.CompareRow{Region: region.Code, Current: region.Code == m.pricingRegion}}
+	rates := m.rates
+	if !c.Current {
+		code := m.rates.CurrencyCode()
+		cached := m.cachedRates(region.Code)
+		switch {
+		case cached != nil && cached.CurrencyCode() != code:
+			return c, cached.CurrencyCode()
+		case cached == nil && region.Partition().Currency != code:
+			return c, region.Partition().Currency
+		case cached == nil:
+			c.Missing = true
+			return c, ""
+		}
+		rates = *cached
+	}
+
+	baseField, resField := pricing.FieldsForCapability(m.activeCapability)
+	c.input = m.buildInputFor(m.activeCapability, region.Code, rates)
+	c.Breakdown = calculator.Calculate(c.input)
+	c.Defaults = rates.Provenance[baseField].Source == pricing.SourceDefaults ||
+		rates.Provenance[resField].Source == pricing.SourceDefaults
+	c.rates = rates
+	return c, ""
+}
+
+// filteredRegions returns the regions whose code or name contains the
+// region picker's filter, ignoring case.
+func (m Model) filteredRegions() []pricing.Region {
+	filter := strings.ToLower(strings.TrimSpace(m.regionFilter.Value()))
+	if filter == "" {
+		return m.allRegions
+	}
+	var regions []pricing.Region
+	for _, r := range m.allRegions {
+		if strings.Contains(strings.ToLower(r.Code), filter) || strings.Contains(strings.ToLower(r.Name), filter) {
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
+
+// refreshRows prices the rows of the view on screen: the region picker,
+// the region comparison or the budget planner. It runs when the rates, the
+// inputs, the filter, the budget or the cache change, not on every render.
+func (m *Model) refreshRows() {
+	switch m.view {
+	case viewRegions:
+		m.pickerRows = m.regionRows()
+	case viewCompare:
+		m.compareCosts, m.compareOther = m.compareRegions()
+	case viewPlanner:
+		m.planCapRows, m.planRegionRows = m.planRows()
+	}
+}
+
+// regionRows prices the active scenario in each region the picker lists.
+func (m *Model) regionRows() []views.RegionRow {
+	regions := m.filteredRegions()
+	rows := make([]views.RegionRow, len(regions))
+	for i, region := range regions {
+		c, currency := m.priceRegion(region)
+		rows[i] = views.RegionRow{Region: region, Monthly: c.Breakdown.TotalMonthly, Missing: c.Missing, Currency: currency, Current: c.Current}
+	}
+	return rows
+}
+
+// regionPickerRows is the number of regions the picker lists at once, so
+// that it fits the terminal; 0 lists them all.
+func (m Model) regionPickerRows() int {
+	if m.height == 0 {
+		return 0
+	}
+	// Title, filter, scroll markers, box borders and padding, and hints.
+	return max(m.height-14, 3)
+}
+
+// openRegions shows the region picker, unfiltered, with the cursor on the
+// region being priced.
+func (m *Model) openRegions() tea.Cmd {
+	m.view = viewRegions
+	m.regionFilter.SetValue("")
+	m.regionCursor = 0
+	for i, r := range m.allRegions {
+		if r.Code == m.pricingRegion {
+			m.regionCursor = i
+		}
+	}
+	m.refreshRows()
+	return m.regionFilter.Focus()
+}
+
+// canFetchRegions reports whether rates for other regions can be fetched:
+// not offline, and not when the rates come from history.
+func (m Model) canFetchRegions() bool {
+	return !m.offline && m.asOf.IsZero()
+}
+
+// fetchMissingRegions fetches, in the background, the rates of the regions
+// the comparison has none for, unless warming is already running.
+func (m *Model) fetchMissingRegions() tea.Cmd {
+	if !m.canFetchRegions() || m.warm.active() {
+		return nil
+	}
+	var missing []string
+	for _, c := range m.compareCosts {
+		if c.Missing {
+			missing = append(missing, c.Region)
+		}
+	}
+	return m.warmCmd(missing)
+}
+
 func (m *Model) applyLiveRates() {
 	for _, cap := range calculator.AllCapabilities {
 		cs := m.capStates[cap]
@@ -501,6 +1415,31 @@ func (m *Model) applyLiveRates() {
 	}
 }
 
+// recentRateChanges compares the current rates with the last snapshot
+// recorded for the region before the day of now. Overridden and default
+// rates are ignored, as are historical rates.
+func (m Model) recentRateChanges(now time.Time) ([]pricing.RateChange, time.Time) {
+	if !m.asOf.IsZero() {
+		return nil, time.Time{}
+	}
+	history, _ := m.rateHistory(m.pricingRegion)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	prev, ok := pricing.SnapshotAt(history, today.Add(-time.Nanosecond))
+	if !ok {
+		return nil, time.Time{}
+	}
+
+	var changes []pricing.RateChange
+	for _, c := range pricing.CompareRates(prev.Rates.Rates, m.rates.Rates) {
+		switch m.rates.Provenance[c.Field].Source {
+		case pricing.SourceOverrides, pricing.SourceDefaults:
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes, prev.FetchedAt
+}
+
 func (m Model) exportPath(name string) string {
 	if m.exportDir != "" {
 		return m.exportDir + "/" + name
@@ -511,11 +1450,33 @@ func (m Model) exportPath(name string) string {
 func (m Model) doExport() (Model, tea.Cmd) {
 	input := m.buildInput()
 	cs := m.activeState()
-	scenario := export.Scenario{Input: input, Breakdown: cs.Breakdown}
+	scenario := export.Scenario{Input: input, Breakdown: cs.Breakdown, Currency: m.money, Rates: m.rates}
 
 	filename := fmt.Sprintf("%s-cost-estimate.csv", strings.ToLower(m.activeCapability.String()))
+	return m.writeExport(filename, []export.Scenario{scenario})
+}
+
+// exportComparison exports the region comparison in its current order, one
+// scenario per region with rates, named after the region.
+func (m Model) exportComparison() (Model, tea.Cmd) {
+	var scenarios []export.Scenario
+	for _, c := range m.compareCosts {
+		if c.Missing {
+			continue
+		}
+		c.input.Name = c.Region
+		scenarios = append(scenarios, export.Scenario{Input: c.input, Breakdown: c.Breakdown, Currency: m.money, Rates: c.rates})
+	}
+
+	filename := fmt.Sprintf("%s-region-comparison.csv", strings.ToLower(m.activeCapability.String()))
+	return m.writeExport(filename, scenarios)
+}
+
+// writeExport writes scenarios to filename in the export directory and
+// reports the outcome for a few seconds.
+func (m Model) writeExport(filename string, scenarios []export.Scenario) (Model, tea.Cmd) {
 	path := m.exportPath(filename)
-	if err := export.ToCSV([]export.Scenario{scenario}, path); err != nil {
+	if err := export.ToCSV(scenarios, path); err != nil {
 		m.exportMsg = fmt.Sprintf("Export failed: %v", err)
 	} else {
 		m.exportMsg = fmt.Sprintf("Exported to %s", path)
@@ -536,6 +1497,12 @@ func (m Model) View() string {
 	var b strings.Builder
 
 	b.WriteString(styles.TitleStyle.Render("AWS EKS Capabilities Cost Calculator"))
+	if m.offline {
+		b.WriteString("  " + styles.WarningStyle.Render("[offline]"))
+	}
+	if m.fixtures != "" {
+		b.WriteString("  " + styles.WarningStyle.Render(m.fixtures))
+	}
 	b.WriteString("\n\n")
 
 	if m.ratesLoading {
@@ -551,9 +1518,38 @@ func (m Model) View() string {
 
 			b.WriteString(views.RenderTabBar(m.activeCapability))
 			b.WriteString("\n\n")
-			b.WriteString(views.RenderCalculator(m.activeCapability, cs.Inputs, cs.FocusIndex, input, cs.Breakdown, m.width, m.height))
+			b.WriteString(views.RenderCalculator(m.activeCapability, cs.Inputs, cs.FocusIndex, input, cs.Breakdown, m.rates, m.money, m.width, m.height))
 			b.WriteString("\n\n")
 
+			if !m.asOf.IsZero() {
+				b.WriteString(styles.MutedStyle.Render(fmt.Sprintf("Using rates recorded as of %s", m.asOf.Format("2006-01-02"))))
+				b.WriteString("\n")
+			}
+			if m.offline {
+				b.WriteString(styles.MutedStyle.Render("Offline: rates come from the cache, offer files, overrides and defaults, not AWS"))
+				b.WriteString("\n")
+			}
+			if status := views.RenderRateStatus(m.activeCapability, m.rates, time.Now()); status != "" {
+				b.WriteString(status)
+				b.WriteString("\n")
+			}
+			if warning := views.RenderAnomalies(m.anomalies, m.rejectSuspect); warning != "" {
+				b.WriteString(warning)
+				b.WriteString("\n")
+			}
+			if notice := views.RenderRateChanges(m.rateChanges, m.changesSince, m.money); notice != "" {
+				b.WriteString(notice)
+				b.WriteString("\n")
+			}
+			if m.revalidating {
+				b.WriteString(styles.MutedStyle.Render("Cached rates have expired; refreshing..."))
+				b.WriteString("\n")
+			}
+			if m.warm.active() {
+				b.WriteString(views.RenderWarmProgress(m.warm.done, m.warm.total, m.warm.failed))
+				b.WriteString("\n")
+			}
+
 			hints := views.InputHintsForCapability(m.activeCapability)
 			if cs.FocusIndex >= 0 && cs.FocusIndex < len(hints) {
 				b.WriteString(styles.MutedStyle.Render(hints[cs.FocusIndex]))
@@ -563,7 +1559,37 @@ func (m Model) View() string {
 			b.WriteString(views.RenderHelp())
 
 		case viewRegions:
-			b.WriteString(views.RenderRegions(m.allRegions, m.regionCursor))
+			b.WriteString(views.RenderRegions(m.regionFilter, m.pickerRows, m.regionCursor, m.regionPickerRows(), m.money))
+
+		case viewProfiles:
+			creds := m.client.Credentials()
+			b.WriteString(views.RenderProfiles(m.profiles, m.profileCursor, creds.Profile, creds.RoleARN, m.profilesErr))
+
+		case viewChargeback:
+			cs := m.activeState()
+			b.WriteString(views.RenderTabBar(m.activeCapability))
+			b.WriteString("\n\n")
+			b.WriteString(views.RenderChargeback(m.activeCapability, cs.Tenants, cs.TenantFocus, cs.Allocation, cs.Breakdown, m.money))
+			b.WriteString("\n")
+
+		case viewPlanner:
+			b.WriteString(views.RenderTabBar(m.activeCapability))
+			b.WriteString("\n\n")
+			b.WriteString(views.RenderPlanner(m.buildInput(), m.budgetInput, m.planCapRows, m.planRegionRows, m.money))
+
+		case viewCompare:
+			rows := make([]views.CompareRow, len(m.compareCosts))
+			for i, c := range m.compareCosts {
+				rows[i] = c.CompareRow
+			}
+			b.WriteString(views.RenderTabBar(m.activeCapability))
+			b.WriteString("\n\n")
+			b.WriteString(views.RenderCompare(m.activeCapability, rows, m.compareSort, m.compareOther, m.canFetchRegions(), m.money))
+			if m.warm.active() {
+				b.WriteString("\n")
+				b.WriteString(views.RenderWarmProgress(m.warm.done, m.warm.total, m.warm.failed))
+				b.WriteString("\n")
+			}
 		}
 
 		var hint string
@@ -571,11 +1597,19 @@ func (m Model) View() string {
 		case viewCapabilitySelector:
 			hint = "↑/↓ navigate  enter select  q quit"
 		case viewCalculator:
-			hint = "↑/↓/tab navigate  [/] capability  r region  e export  ? help  q quit"
+			hint = "↑/↓/tab navigate  [/] capability  r region  p profile  c chargeback  b budget  R compare  $ currency  e export  ? help  q quit"
 		case viewHelp:
 			hint = "esc back  q quit"
 		case viewRegions:
+			hint = "type to filter  ↑/↓ navigate  enter select  esc cancel"
+		case viewProfiles:
 			hint = "↑/↓ navigate  enter select  esc cancel"
+		case viewChargeback:
+			hint = "↑/↓/tab navigate  ctrl+n add tenant  ctrl+d delete  ctrl+p base fee policy  esc back"
+		case viewPlanner:
+			hint = "type a monthly budget  esc back  q quit"
+		case viewCompare:
+			hint = "s sort  f fetch missing  e export  esc back  q quit"
 		}
 		if hint != "" {
 			b.WriteString("\n")
@@ -588,20 +1622,44 @@ func (m Model) View() string {
 		b.WriteString(styles.SuccessStyle.Render(m.exportMsg))
 	}
 
+	if m.overridesErr != nil {
+		b.WriteString("\n\n")
+		b.WriteString(styles.WarningStyle.Render(
+			fmt.Sprintf("⚠ Ignoring rate overrides: %v", m.overridesErr)))
+	}
+
 	if m.ratesErr != nil {
 		b.WriteString("\n\n")
+		reason := fetchErrorReason(m.ratesErr)
 		if m.ratesLoaded {
 			b.WriteString(styles.WarningStyle.Render(
-				fmt.Sprintf("⚠ Unable to fetch rates for %s. Using previously fetched rates.", m.pricingRegion)))
+				fmt.Sprintf("⚠ Unable to fetch rates for %s%s. Using previously fetched rates.", m.pricingRegion, reason)))
 		} else {
 			b.WriteString(styles.WarningStyle.Render(
-				fmt.Sprintf("⚠ Unable to fetch rates for %s. Using default rates.", m.pricingRegion)))
+				fmt.Sprintf("⚠ Unable to fetch rates for %s%s. Using default rates.", m.pricingRegion, reason)))
 		}
 	}
 
 	return b.String()
 }
 
+// fetchErrorReason explains a failed rate fetch, as a clause to append to
+// the warning, or "" if the cause is not known.
+func fetchErrorReason(err error) string {
+	switch pricing.Classify(err) {
+	case pricing.ErrorAuth:
+		return ": " + pricing.Diagnose(err)
+	case pricing.ErrorThrottled:
+		return ": the Pricing API is throttling requests, try again shortly"
+	case pricing.ErrorNetwork:
+		return ": the Pricing API could not be reached"
+	case pricing.ErrorNotFound:
+		return ": the Pricing API has no EKS capability products for the region"
+	default:
+		return ""
+	}
+}
+
 func parseInt(s string) int {
 	v, _ := strconv.Atoi(s)
 	if v < 0 {
@@ -3,16 +3,20 @@ package tui
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
-	"sync"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
-	"github.com/josegonzalez/aws-eks-calculator/internal/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
 	"github.com/josegonzalez/aws-eks-calculator/internal/prefs"
-	"github.com/josegonzalez/aws-eks-calculator/internal/pricing"
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui/views"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
 )
 
 // newReadyModel returns a NewModel with ratesLoading cleared and already
@@ -35,8 +39,8 @@ func TestNewModel(t *testing.T) {
 		t.Errorf("expected 3 capability states, got %d", len(m.capStates))
 	}
 	argoState := m.capStates[calculator.CapabilityArgoCD]
-	if len(argoState.Inputs) != 9 {
-		t.Errorf("ArgoCD: expected 9 inputs, got %d", len(argoState.Inputs))
+	if len(argoState.Inputs) != 13 {
+		t.Errorf("ArgoCD: expected 13 inputs, got %d", len(argoState.Inputs))
 	}
 	ackState := m.capStates[calculator.CapabilityACK]
 	if len(ackState.Inputs) != 7 {
@@ -67,6 +71,11 @@ func TestInit(t *testing.T) {
 	if cmd == nil {
 		t.Error("Init should return a batch command")
 	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if err := updated.(Model).fetch.ctx.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the startup fetch cancelled on quit, got %v", err)
+	}
 }
 
 func TestUpdateWindowSize(t *testing.T) {
@@ -101,7 +110,7 @@ func TestUpdatePricingMsg(t *testing.T) {
 		FargateMemGBPerHour: 0.005,
 	}
 
-	updated, cmd := m.Update(pricingMsg{rates: rates})
+	updated, cmd := m.Update(pricingMsg{rates: pricing.RateSheet{Rates: rates}})
 	model := updated.(Model)
 
 	if !model.ratesLoaded {
@@ -141,7 +150,7 @@ func TestUpdatePricingMsgSuccess(t *testing.T) {
 	m := NewModel()
 	defaults := pricing.DefaultRates()
 
-	updated, cmd := m.Update(pricingMsg{rates: defaults, err: nil})
+	updated, cmd := m.Update(pricingMsg{rates: pricing.RateSheet{Rates: defaults}, err: nil})
 	model := updated.(Model)
 
 	if model.ratesErr != nil {
@@ -168,13 +177,13 @@ func TestUpdatePricingMsgError(t *testing.T) {
 		FargateVCPUPerHour:  0.05,
 		FargateMemGBPerHour: 0.005,
 	}
-	updated, _ := m.Update(pricingMsg{rates: goodRates})
+	updated, _ := m.Update(pricingMsg{rates: pricing.RateSheet{Rates: goodRates}})
 	m = updated.(Model)
 
 	// Now send a failed fetch with default rates
 	badRates := pricing.DefaultRates()
 	fetchErr := errors.New("network timeout")
-	updated, _ = m.Update(pricingMsg{rates: badRates, err: fetchErr})
+	updated, _ = m.Update(pricingMsg{rates: pricing.RateSheet{Rates: badRates}, err: fetchErr})
 	model := updated.(Model)
 
 	// Rates are always applied now — msg.rates are default rates (safe fallback)
@@ -194,7 +203,7 @@ func TestUpdatePricingMsgErrorFirstFetch(t *testing.T) {
 	defaults := pricing.DefaultRates()
 
 	fetchErr := errors.New("no credentials")
-	updated, _ := m.Update(pricingMsg{rates: defaults, err: fetchErr})
+	updated, _ := m.Update(pricingMsg{rates: pricing.RateSheet{Rates: defaults}, err: fetchErr})
 	model := updated.(Model)
 
 	if model.ratesLoaded {
@@ -227,7 +236,7 @@ func TestUpdatePricingMsgPartialError(t *testing.T) {
 	}
 	partialErr := errors.New("EKS ACK: zero rates for region us-east-1, using defaults")
 
-	updated, _ := m.Update(pricingMsg{rates: partialRates, err: partialErr})
+	updated, _ := m.Update(pricingMsg{rates: pricing.RateSheet{Rates: partialRates}, err: partialErr})
 	model := updated.(Model)
 
 	// Rates should be applied despite the error
@@ -569,10 +578,10 @@ func TestRegionPickerNavigation(t *testing.T) {
 		t.Errorf("down should move cursor to 1, got %d", model.regionCursor)
 	}
 
-	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
 	model = updated.(Model)
 	if model.regionCursor != 2 {
-		t.Errorf("j should move cursor to 2, got %d", model.regionCursor)
+		t.Errorf("down should move cursor to 2, got %d", model.regionCursor)
 	}
 
 	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
@@ -581,10 +590,10 @@ func TestRegionPickerNavigation(t *testing.T) {
 		t.Errorf("up should move cursor to 1, got %d", model.regionCursor)
 	}
 
-	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
 	model = updated.(Model)
 	if model.regionCursor != 0 {
-		t.Errorf("k should move cursor to 0, got %d", model.regionCursor)
+		t.Errorf("up should move cursor to 0, got %d", model.regionCursor)
 	}
 
 	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
@@ -616,13 +625,132 @@ func TestRegionPickerEsc(t *testing.T) {
 	}
 }
 
-func TestRegionPickerQ(t *testing.T) {
+func TestRegionPickerCtrlC(t *testing.T) {
 	m := newReadyModel()
 	m.view = viewRegions
-	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
-	model := updated.(Model)
-	if model.view != viewCalculator {
-		t.Error("q should close region picker")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if !updated.(Model).quitting {
+		t.Error("ctrl+c should quit from the region picker")
+	}
+}
+
+func TestRegionPickerFilter(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	m := newReadyModel()
+	m.allRegions = []pricing.Region{
+		{Code: "us-east-1", Name: "US East (N. Virginia)"},
+		{Code: "eu-west-1", Name: "Europe (Ireland)"},
+		{Code: "eu-central-1", Name: "Europe (Frankfurt)"},
+		{Code: "ap-south-1", Name: "Asia Pacific (Mumbai)"},
+	}
+	m.pricingRegion = "eu-west-1"
+	typeText := func(m Model, text string) Model {
+		for _, r := range text {
+			updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			m = updated.(Model)
+		}
+		return m
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = updated.(Model)
+	if m.regionCursor != 1 || cmd == nil {
+		t.Fatalf("expected the picker to open on eu-west-1 with the filter focused, got cursor %d", m.regionCursor)
+	}
+
+	// Typing narrows by code or name, ignoring case, and keeps the cursor
+	// on its region.
+	m = typeText(m, "EU")
+	if got := m.filteredRegions(); len(got) != 2 || m.regionCursor != 0 {
+		t.Errorf("expected the two eu regions with eu-west-1 selected, got %v (cursor %d)", got, m.regionCursor)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(Model)
+	m = typeText(m, "rope (f")
+	if got := m.filteredRegions(); len(got) != 1 || got[0].Code != "eu-central-1" || m.regionCursor != 0 {
+		t.Errorf("expected eu-central-1 by name, got %v", got)
+	}
+
+	// A filter matching nothing leaves nothing to select.
+	m = typeText(m, "x")
+	if !strings.Contains(m.View(), "No regions match") {
+		t.Error("expected no matches shown")
+	}
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if updated.(Model).view != viewRegions || cmd != nil {
+		t.Error("expected enter to do nothing without matches")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = updated.(Model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.view != viewCalculator || m.pricingRegion != "eu-central-1" {
+		t.Errorf("expected eu-central-1 selected, got %s", m.pricingRegion)
+	}
+
+	// Reopening clears the filter.
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = updated.(Model)
+	if m.regionFilter.Value() != "" || m.regionCursor != 2 {
+		t.Errorf("expected an unfiltered picker on eu-central-1, got %q (cursor %d)", m.regionFilter.Value(), m.regionCursor)
+	}
+}
+
+func TestRegionPickerRates(t *testing.T) {
+	m := newReadyModel()
+	m.allRegions = []pricing.Region{{Code: "us-east-1"}, {Code: "eu-west-1"}, {Code: "ap-south-1"}, {Code: "cn-north-1"}}
+	m.cachedRates = func(region string) *pricing.RateSheet {
+		if region == "eu-west-1" {
+			rates := pricing.RateSheet{Rates: pricing.DefaultRates()}
+			rates.ArgoCDBasePerHour = 1
+			return &rates
+		}
+		return nil
+	}
+	m.view = viewRegions
+	m.refreshRows()
+
+	rows := m.pickerRows
+	want := calculator.Calculate(m.buildInputFor(m.activeCapability, "eu-west-1", *m.cachedRates("eu-west-1"))).TotalMonthly
+	if !rows[0].Current || rows[0].Monthly != m.activeState().Breakdown.TotalMonthly {
+		t.Errorf("expected the current region at the current total, got %+v", rows[0])
+	}
+	if rows[1].Monthly != want || rows[1].Missing {
+		t.Errorf("expected the cached eu-west-1 total %v, got %+v", want, rows[1])
+	}
+	if !rows[2].Missing || rows[3].Currency != "CNY" {
+		t.Errorf("expected ap-south-1 missing and cn-north-1 in CNY, got %+v", rows[2:])
+	}
+
+	output := m.View()
+	if !strings.Contains(output, "not fetched") || !strings.Contains(output, "type to filter") {
+		t.Error("expected the picker with rates and hints")
+	}
+}
+
+func TestRegionPickerScrolls(t *testing.T) {
+	m := newReadyModel()
+	if m.regionPickerRows() != 0 {
+		t.Error("expected every region listed before the terminal size is known")
+	}
+	m.height = 20
+	if m.regionPickerRows() != 6 {
+		t.Errorf("expected 6 rows, got %d", m.regionPickerRows())
+	}
+	m.height = 10
+	if m.regionPickerRows() != 3 {
+		t.Errorf("expected at least 3 rows, got %d", m.regionPickerRows())
+	}
+
+	m.view = viewRegions
+	m.refreshRows()
+	m.regionCursor = len(m.allRegions) - 1
+	output := m.View()
+	if !strings.Contains(output, m.allRegions[len(m.allRegions)-1].Code) || strings.Contains(output, m.allRegions[0].Code+" ") {
+		t.Error("expected the list scrolled to the cursor")
 	}
 }
 
@@ -674,6 +802,222 @@ func TestRegionPickerSelectSameRegion(t *testing.T) {
 	}
 }
 
+func TestRegionPickerOutOfOrderResponses(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	// Each region's fetch blocks until released, reporting whether its
+	// context was cancelled first.
+	release := map[string]chan struct{}{"us-east-2": make(chan struct{}), "eu-west-1": make(chan struct{})}
+	cancelled := make(chan string, 2)
+	m := newReadyModel()
+	m.allRegions = []pricing.Region{{Code: "us-east-1"}, {Code: "us-east-2"}, {Code: "eu-west-1"}}
+	m.provider = pricing.ProviderFunc(func(ctx context.Context, region string) (pricing.Rates, error) {
+		select {
+		case <-release[region]:
+		case <-ctx.Done():
+			cancelled <- region
+		}
+		rates := pricing.DefaultRates()
+		if region == "us-east-2" {
+			rates.ArgoCDBasePerHour = 0.5
+		}
+		return rates, ctx.Err()
+	})
+
+	selectRegion := func(m Model, cursor int) (Model, tea.Cmd) {
+		m.view = viewRegions
+		m.regionCursor = cursor
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		return updated.(Model), cmd
+	}
+	m, first := selectRegion(m, 1)
+	m, second := selectRegion(m, 2)
+
+	// The superseded fetch sees its context cancelled.
+	firstMsg := make(chan tea.Msg)
+	go func() { firstMsg <- first() }()
+	if region := <-cancelled; region != "us-east-2" {
+		t.Fatalf("expected the us-east-2 fetch cancelled, got %s", region)
+	}
+
+	close(release["eu-west-1"])
+	updated, _ := m.Update(second())
+	m = updated.(Model)
+
+	// The first response arrives last and is dropped.
+	stale := (<-firstMsg).(pricingMsg)
+	if stale.region != "us-east-2" {
+		t.Fatalf("expected the us-east-2 response, got %s", stale.region)
+	}
+	updated, _ = m.Update(stale)
+	m = updated.(Model)
+
+	if m.pricingRegion != "eu-west-1" {
+		t.Errorf("expected region eu-west-1, got %s", m.pricingRegion)
+	}
+	if m.rates.ArgoCDBasePerHour != pricing.DefaultRates().ArgoCDBasePerHour || m.ratesErr != nil {
+		t.Errorf("expected eu-west-1 rates without error, got %v (err %v)", m.rates.ArgoCDBasePerHour, m.ratesErr)
+	}
+	if m.ratesLoading {
+		t.Error("expected loading to finish with the current response")
+	}
+}
+
+// withStaleEntry turns on stale-while-revalidate with an expired cache
+// entry for every region.
+func withStaleEntry(m Model, rates pricing.RateSheet) Model {
+	m.staleWhileRevalidate = true
+	m.cacheEntry = func(region string) (pricing.CacheEntry, bool) {
+		return pricing.CacheEntry{Region: region, Rates: rates, Expired: true}, true
+	}
+	return m
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	stale := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	stale.ArgoCDBasePerHour = 0.5
+	fetched := pricing.Provenance{Source: pricing.SourceAPI, Region: "us-east-2", FetchedAt: time.Now().Add(-72 * time.Hour)}
+	stale.Provenance = map[pricing.Field]pricing.Provenance{
+		pricing.FieldArgoCDBasePerHour: fetched, pricing.FieldArgoCDAppPerHour: fetched,
+	}
+	m := withStaleEntry(newReadyModel(), stale)
+	m.width, m.height = 120, 40
+	m.view = viewRegions
+	m.regionCursor = 1
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if cmd == nil || !m.ratesLoading {
+		t.Fatal("expected a fetch to start")
+	}
+
+	// The expired rates stand in until the fetch returns.
+	updated, _ = m.Update(m.staleRatesCmd(m.fetch.id, "us-east-2")())
+	m = updated.(Model)
+	if m.ratesLoading || !m.revalidating || m.rates.ArgoCDBasePerHour != 0.5 {
+		t.Fatalf("expected the stale rates shown, got loading=%v revalidating=%v %v", m.ratesLoading, m.revalidating, m.rates.ArgoCDBasePerHour)
+	}
+	if !strings.Contains(m.View(), "refreshing") {
+		t.Error("expected the refresh noted while revalidating")
+	}
+	m.view = viewCalculator
+	if output := m.View(); !strings.Contains(output, "Rates for us-east-2: cache, fetched") || strings.Contains(output, "live Pricing API") {
+		t.Errorf("expected the rates attributed to the cache:\n%s", output)
+	}
+
+	// A failed refresh keeps them rather than falling back to defaults.
+	updated, _ = m.Update(pricingMsg{id: m.fetch.id, rates: pricing.RateSheet{Rates: pricing.DefaultRates()}, err: errors.New("offline")})
+	failed := updated.(Model)
+	if failed.revalidating || failed.ratesErr == nil || failed.rates.ArgoCDBasePerHour != 0.5 {
+		t.Errorf("expected the stale rates kept with the error, got %v (err %v)", failed.rates.ArgoCDBasePerHour, failed.ratesErr)
+	}
+	if output := failed.View(); !strings.Contains(output, "Rates for us-east-2: cache, fetched") {
+		t.Errorf("expected the kept rates attributed to the cache:\n%s", output)
+	}
+
+	// A successful one replaces them.
+	updated, _ = m.Update(pricingMsg{id: m.fetch.id, rates: pricing.RateSheet{Rates: pricing.DefaultRates()}})
+	m = updated.(Model)
+	if m.revalidating || m.rates.ArgoCDBasePerHour != pricing.DefaultRates().ArgoCDBasePerHour {
+		t.Errorf("expected the fresh rates, got %v", m.rates.ArgoCDBasePerHour)
+	}
+	if strings.Contains(m.View(), "refreshing") {
+		t.Error("the refresh note should clear")
+	}
+}
+
+func TestStaleRatesIgnoredOnceLoaded(t *testing.T) {
+	m := withStaleEntry(newReadyModel(), pricing.RateSheet{Rates: pricing.DefaultRates()})
+	m.rates.ArgoCDBasePerHour = 0.7
+
+	updated, _ := m.Update(staleRatesMsg{id: m.fetch.id, rates: pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 0.5}}})
+	if got := updated.(Model).rates.ArgoCDBasePerHour; got != 0.7 {
+		t.Errorf("stale rates should not replace loaded ones, got %v", got)
+	}
+
+	m.ratesLoading = true
+	updated, _ = m.Update(staleRatesMsg{id: m.fetch.id + 1, rates: pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 0.5}}})
+	if got := updated.(Model).rates.ArgoCDBasePerHour; got != 0.7 {
+		t.Errorf("stale rates for another request should be dropped, got %v", got)
+	}
+}
+
+func TestStaleRatesCmd(t *testing.T) {
+	m := newReadyModel()
+	m.cacheEntry = func(region string) (pricing.CacheEntry, bool) {
+		switch region {
+		case "eu-west-1":
+			return pricing.CacheEntry{Region: region, Expired: true}, true
+		case "us-east-1":
+			return pricing.CacheEntry{Region: region}, true
+		}
+		return pricing.CacheEntry{}, false
+	}
+
+	if m.staleRatesCmd(0, "eu-west-1") != nil {
+		t.Error("expected nothing with stale-while-revalidate off")
+	}
+	m.staleWhileRevalidate = true
+	if m.staleRatesCmd(0, "us-east-1") != nil || m.staleRatesCmd(0, "ap-south-1") != nil {
+		t.Error("expected nothing for fresh or missing entries")
+	}
+	if cmd := m.staleRatesCmd(3, "eu-west-1"); cmd == nil || cmd().(staleRatesMsg).id != 3 {
+		t.Error("expected the expired entry delivered for the request")
+	}
+}
+
+func TestNewModelCachePrefs(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir) // the user cache dir on macOS
+
+	_ = pricing.NewCache().Save("us-east-1", pricing.DefaultRates())
+	_ = prefs.Save(prefs.Prefs{CacheTTL: "1ns", StaleWhileRevalidate: true})
+	time.Sleep(time.Millisecond)
+
+	m := NewModel()
+	if !m.staleWhileRevalidate {
+		t.Error("expected stale-while-revalidate loaded from prefs")
+	}
+	if m.cachedRates("us-east-1") != nil {
+		t.Error("expected the configured TTL applied")
+	}
+	if m.staleRatesCmd(0, "us-east-1") == nil {
+		t.Error("expected the expired entry offered")
+	}
+	if m := NewModelAsOf(time.Now(), Options{}); m.staleWhileRevalidate {
+		t.Error("historical rates should not be revalidated")
+	}
+}
+
+func TestQuitCancelsFetch(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	m := newReadyModel()
+	m.provider = pricing.ProviderFunc(func(ctx context.Context, _ string) (pricing.Rates, error) {
+		<-ctx.Done()
+		return pricing.Rates{}, ctx.Err()
+	})
+	m.view = viewRegions
+	m.regionCursor = 1
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	done := make(chan tea.Msg)
+	go func() { done <- cmd() }()
+	updated.(Model).Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	if pm := (<-done).(pricingMsg); !errors.Is(pm.err, context.Canceled) {
+		t.Errorf("expected the fetch cancelled on quit, got %v", pm.err)
+	}
+}
+
 func TestRegionPickerUnhandled(t *testing.T) {
 	m := newReadyModel()
 	m.view = viewRegions
@@ -787,6 +1131,7 @@ func TestViewHelp(t *testing.T) {
 func TestViewRegions(t *testing.T) {
 	m := newReadyModel()
 	m.view = viewRegions
+	m.refreshRows()
 	output := m.View()
 
 	if !strings.Contains(output, "Select Region") {
@@ -1046,7 +1391,7 @@ func TestViewLoadingClearedAfterPricingMsg(t *testing.T) {
 	m.width = 120
 	m.height = 40
 
-	updated, _ := m.Update(pricingMsg{rates: pricing.DefaultRates()})
+	updated, _ := m.Update(pricingMsg{rates: pricing.RateSheet{Rates: pricing.DefaultRates()}})
 	model := updated.(Model)
 	model.width = 120
 	model.height = 40
@@ -1060,7 +1405,7 @@ func TestViewLoadingClearedAfterPricingMsg(t *testing.T) {
 func TestCacheWarmTriggeredOnce(t *testing.T) {
 	m := NewModel()
 
-	updated, cmd := m.Update(pricingMsg{rates: pricing.DefaultRates()})
+	updated, cmd := m.Update(pricingMsg{rates: pricing.RateSheet{Rates: pricing.DefaultRates()}})
 	model := updated.(Model)
 	if cmd == nil {
 		t.Fatal("first success should return cache warm command")
@@ -1069,7 +1414,7 @@ func TestCacheWarmTriggeredOnce(t *testing.T) {
 		t.Fatal("cacheWarmed should be true")
 	}
 
-	updated, cmd = model.Update(pricingMsg{rates: pricing.DefaultRates()})
+	updated, cmd = model.Update(pricingMsg{rates: pricing.RateSheet{Rates: pricing.DefaultRates()}})
 	model = updated.(Model)
 	if cmd != nil {
 		t.Error("second success should not return cache warm command")
@@ -1080,7 +1425,7 @@ func TestCacheWarmNotTriggeredOnError(t *testing.T) {
 	m := NewModel()
 
 	fetchErr := errors.New("timeout")
-	updated, cmd := m.Update(pricingMsg{rates: pricing.DefaultRates(), err: fetchErr})
+	updated, cmd := m.Update(pricingMsg{rates: pricing.RateSheet{Rates: pricing.DefaultRates()}, err: fetchErr})
 	model := updated.(Model)
 
 	if cmd != nil {
@@ -1183,6 +1528,201 @@ func TestRegionPickerSameRegionDoesNotSave(t *testing.T) {
 	}
 }
 
+// Profile picker tests
+
+func newProfileModel(t *testing.T) Model {
+	t.Helper()
+	prefs.SetDir(t.TempDir())
+	t.Cleanup(func() { prefs.SetDir("") })
+
+	m := newReadyModel()
+	m.client = pricing.NewClient(pricing.Credentials{Profile: "prod", RoleARN: "arn:aws:iam::123456789012:role/pricing"})
+	m.listProfiles = func() ([]string, error) { return []string{"dev", "prod"}, nil }
+	return m
+}
+
+func TestProfilePickerOpen(t *testing.T) {
+	m := newProfileModel(t)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	model := updated.(Model)
+
+	if model.view != viewProfiles {
+		t.Fatal("p should open the profile picker")
+	}
+	if strings.Join(model.profiles, ",") != ",dev,prod" || model.profileCursor != 2 {
+		t.Errorf("expected the default chain then the profiles, on prod, got %q at %d", model.profiles, model.profileCursor)
+	}
+	output := model.View()
+	if !strings.Contains(output, "Select AWS Profile") || !strings.Contains(output, "Assuming role arn:aws:iam::123456789012:role/pricing") {
+		t.Errorf("expected the picker with the role, got %q", output)
+	}
+
+	model.view = viewCalculator
+	model.listProfiles = func() ([]string, error) { return nil, errors.New("permission denied") }
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	model = updated.(Model)
+	if len(model.profiles) != 1 || model.profileCursor != 0 || model.profilesErr == nil {
+		t.Errorf("expected only the default chain and the error, got %q, %v", model.profiles, model.profilesErr)
+	}
+}
+
+func TestProfilePickerNavigation(t *testing.T) {
+	m := newProfileModel(t)
+	m.openProfiles()
+	m.profileCursor = 0
+
+	keys := []struct {
+		msg  tea.KeyMsg
+		want int
+	}{
+		{tea.KeyMsg{Type: tea.KeyUp}, 0},
+		{tea.KeyMsg{Type: tea.KeyDown}, 1},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}}, 2},
+		{tea.KeyMsg{Type: tea.KeyDown}, 2},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}}, 1},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}, 1},
+	}
+	var model tea.Model = m
+	for _, k := range keys {
+		model, _ = model.Update(k.msg)
+		if got := model.(Model).profileCursor; got != k.want {
+			t.Errorf("%v: expected cursor %d, got %d", k.msg, k.want, got)
+		}
+	}
+
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyEsc}, {Type: tea.KeyRunes, Runes: []rune{'q'}}} {
+		updated, _ := model.(Model).Update(key)
+		if updated.(Model).view != viewCalculator {
+			t.Errorf("%v should close the profile picker", key)
+		}
+	}
+}
+
+func TestProfilePickerSelect(t *testing.T) {
+	m := newProfileModel(t)
+	m.provider = pricing.Static(pricing.DefaultRates())
+	m.openProfiles()
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model := updated.(Model); cmd != nil || model.view != viewCalculator || prefs.Load().AWSProfile != "" {
+		t.Error("selecting the profile in use should change nothing")
+	}
+
+	m.profileCursor = 1
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model := updated.(Model)
+	want := pricing.Credentials{Profile: "dev", RoleARN: "arn:aws:iam::123456789012:role/pricing"}
+	if got := model.client.Credentials(); got != want {
+		t.Errorf("expected the dev profile with the role kept, got %+v", got)
+	}
+	if prefs.Load().AWSProfile != "dev" {
+		t.Error("expected the profile saved to the preferences")
+	}
+	if cmd == nil || !model.ratesLoading || model.fetch.id != m.fetch.id+1 {
+		t.Error("expected the rates refetched")
+	}
+}
+
+func TestNewModelCredentialsPrefs(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	_ = prefs.Save(prefs.Prefs{AWSProfile: "prod", AWSRoleARN: "arn:aws:iam::123456789012:role/pricing", PricingEndpoint: "ap-south-1"})
+
+	m := NewModel()
+	want := pricing.Credentials{Profile: "prod", RoleARN: "arn:aws:iam::123456789012:role/pricing"}
+	if got := m.client.Credentials(); got != want {
+		t.Errorf("expected the credentials from prefs, got %+v", got)
+	}
+	if got := m.client.Endpoint(); got != "ap-south-1" {
+		t.Errorf("expected the endpoint from prefs, got %q", got)
+	}
+}
+
+func TestOfflineModel(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir) // the user cache dir on macOS
+	_ = prefs.Save(prefs.Prefs{StaleWhileRevalidate: true})
+
+	m := NewModelWithOptions(Options{Offline: true})
+	if names := strings.Join(m.provider.(*pricing.Chain).Names(), ","); names != "overrides,cache,defaults" {
+		t.Errorf("expected the api source left out, got %s", names)
+	}
+	if m.fetchRegionsCmd() != nil || m.startWarming() != nil || m.staleWhileRevalidate {
+		t.Error("expected no discovery, warming or revalidation offline")
+	}
+
+	m.ratesLoading = false
+	m.view = viewCalculator
+	output := m.View()
+	if !strings.Contains(output, "[offline]") || !strings.Contains(output, "Offline: rates come from the cache") {
+		t.Errorf("expected the offline notice, got %q", output)
+	}
+	if online := newReadyModel(); strings.Contains(online.View(), "offline") {
+		t.Error("the offline notice should only show offline")
+	}
+}
+
+func TestReplayModel(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir) // the user cache dir on macOS
+	_ = pricing.NewCache().Save("us-east-1", pricing.DefaultRates())
+
+	fixtures := t.TempDir()
+	m := NewModelWithOptions(Options{Replay: fixtures})
+	defer func() { _ = os.RemoveAll(m.scratchDir) }()
+	if m.scratchDir == "" || m.cachedRates("us-east-1") != nil {
+		t.Fatalf("expected an empty scratch cache, got %q", m.scratchDir)
+	}
+	if pm := m.fetchPricing("us-east-1")().(pricingMsg); !errors.Is(pm.err, pricing.ErrNoFixture) {
+		t.Errorf("expected the rates replayed, got %v", pm.err)
+	}
+	if _, err := m.listRegions(context.Background()); !errors.Is(err, pricing.ErrNoFixture) {
+		t.Errorf("expected the region list replayed, got %v", err)
+	}
+	m.ratesLoading = false
+	if !strings.Contains(m.View(), "[replay]") {
+		t.Error("expected the replay tag")
+	}
+
+	// Without a scratch directory, the user's cache is used.
+	t.Setenv("TMPDIR", filepath.Join(dir, "missing"))
+	if m := NewModelWithOptions(Options{Replay: fixtures}); m.scratchDir != "" || m.cachedRates("us-east-1") == nil {
+		t.Errorf("expected the user's cache, got %q", m.scratchDir)
+	}
+	if m := NewModelWithOptions(Options{Record: fixtures}); m.fixtures != "[recording]" || m.scratchDir != "" {
+		t.Errorf("expected a recording session, got %q", m.fixtures)
+	}
+}
+
+func TestOptionsPricingAPI(t *testing.T) {
+	client := pricing.NewClient(pricing.Credentials{})
+	if _, ok := (Options{Replay: "r", Record: "w"}).PricingAPI(client).(*pricing.Replayer); !ok {
+		t.Error("expected a Replayer")
+	}
+	if _, ok := (Options{Record: "w"}).PricingAPI(client).(*pricing.Recorder); !ok {
+		t.Error("expected a Recorder")
+	}
+	if (Options{}).PricingAPI(client) != client {
+		t.Error("expected the client")
+	}
+}
+
+func TestNewModelOfflinePref(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	_ = prefs.Save(prefs.Prefs{Offline: true})
+
+	if m := NewModel(); !m.offline {
+		t.Error("expected offline mode from prefs")
+	}
+}
+
 func TestCapabilityIndexUnknown(t *testing.T) {
 	idx := capabilityIndex(calculator.Capability(99))
 	if idx != 0 {
@@ -1192,11 +1732,11 @@ func TestCapabilityIndexUnknown(t *testing.T) {
 
 func TestFetchPricingCmdClosure(t *testing.T) {
 	m := NewModel()
-	m.priceFetcher = func(_ context.Context, region string) (pricing.Rates, error) {
+	m.provider = pricing.ProviderFunc(func(_ context.Context, region string) (pricing.Rates, error) {
 		return pricing.DefaultRates(), nil
-	}
+	})
 
-	cmd := m.fetchPricingCmd("us-east-1")
+	cmd := m.fetchPricing("us-east-1")
 	msg := cmd()
 
 	pm, ok := msg.(pricingMsg)
@@ -1214,11 +1754,11 @@ func TestFetchPricingCmdClosure(t *testing.T) {
 func TestFetchPricingCmdClosureError(t *testing.T) {
 	m := NewModel()
 	expectedErr := errors.New("mock fetch error")
-	m.priceFetcher = func(_ context.Context, region string) (pricing.Rates, error) {
+	m.provider = pricing.ProviderFunc(func(_ context.Context, region string) (pricing.Rates, error) {
 		return pricing.DefaultRates(), expectedErr
-	}
+	})
 
-	cmd := m.fetchPricingCmd("us-east-1")
+	cmd := m.fetchPricing("us-east-1")
 	msg := cmd()
 
 	pm, ok := msg.(pricingMsg)
@@ -1230,34 +1770,1092 @@ func TestFetchPricingCmdClosureError(t *testing.T) {
 	}
 }
 
-func TestWarmCacheCmdClosure(t *testing.T) {
-	var mu sync.Mutex
-	var calledRegions []string
+// apiError mimics the AWS SDK's API errors, which carry an error code.
+type apiError string
 
+func (e apiError) Error() string     { return "api error " + string(e) }
+func (e apiError) ErrorCode() string { return string(e) }
+
+func TestFetchPricingCmdChainFallback(t *testing.T) {
 	m := NewModel()
-	m.priceFetcher = func(_ context.Context, region string) (pricing.Rates, error) {
-		mu.Lock()
-		calledRegions = append(calledRegions, region)
-		mu.Unlock()
-		return pricing.DefaultRates(), nil
+	api := pricing.NewSource(pricing.SourceAPI, func(context.Context, string) (pricing.RateSheet, error) {
+		return pricing.RateSheet{}, apiError("ThrottlingException")
+	})
+	m.provider = pricing.NewChain(api, pricing.DefaultsSource())
+
+	pm := m.fetchPricing("us-east-1")().(pricingMsg)
+	if pricing.Classify(pm.err) != pricing.ErrorThrottled {
+		t.Errorf("expected the throttling error reported, got %v", pm.err)
+	}
+	if pm.rates.Provenance[pricing.FieldArgoCDBasePerHour].Source != pricing.SourceDefaults {
+		t.Errorf("expected default rates, got %+v", pm.rates.Provenance)
 	}
 
-	regions := []string{"us-east-1", "us-east-2", "eu-west-1"}
-	cmd := m.warmCacheCmd(regions, "us-east-1")
-	msg := cmd()
+	// A chain that cannot complete reports its own error.
+	m.provider = pricing.NewChain(api)
+	if pm := m.fetchPricing("us-east-1")().(pricingMsg); !errors.Is(pm.err, pricing.ErrIncomplete) {
+		t.Errorf("expected ErrIncomplete, got %v", pm.err)
+	}
+}
+
+func TestSuspectRates(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	api := pricing.NewSource(pricing.SourceAPI, func(context.Context, string) (pricing.RateSheet, error) {
+		return pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 3}}, nil
+	})
+	chain := pricing.NewChain(api, pricing.DefaultsSource())
+	m := newReadyModel()
+	m.width, m.height = 120, 40
+	m.provider = chain
+
+	updated, _ := m.Update(m.fetchPricing("us-east-1")())
+	m = updated.(Model)
+	if len(m.anomalies) != 1 || m.rates.ArgoCDBasePerHour != 3 {
+		t.Fatalf("expected the suspect rate used and reported, got %v and %+v", m.rates.ArgoCDBasePerHour, m.anomalies)
+	}
+	if !strings.Contains(m.View(), "Suspect rates (used; ! to reject)") {
+		t.Error("expected the suspect rates listed")
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	m = updated.(Model)
+	if cmd == nil || !m.ratesLoading || !m.rejectSuspect || !chain.RejectSuspect() || !prefs.Load().RejectSuspectRates {
+		t.Fatal("expected the choice applied, saved and the rates fetched again")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.rates.ArgoCDBasePerHour != pricing.DefaultRates().ArgoCDBasePerHour || !strings.Contains(m.View(), "replaced by the next source") {
+		t.Errorf("expected the suspect rate replaced, got %v", m.rates.ArgoCDBasePerHour)
+	}
+
+	// The choice is restored, and rates from other providers are checked too.
+	m = newReadyModel()
+	if !m.rejectSuspect || !m.provider.(*pricing.Chain).RejectSuspect() {
+		t.Error("expected the saved choice restored")
+	}
+	suspect := pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 3}, Provenance: map[pricing.Field]pricing.Provenance{
+		pricing.FieldArgoCDBasePerHour: {Source: pricing.SourceCache},
+	}}
+	m = withStaleEntry(m, suspect)
+	m.ratesLoading = true
+	updated, _ = m.Update(staleRatesMsg{id: m.fetch.id, rates: suspect})
+	if got := updated.(Model).anomalies; len(got) != 1 {
+		t.Errorf("expected the stale rates checked, got %+v", got)
+	}
+}
+
+func TestViewRatesErrorReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{apiError("ThrottlingException"), "Unable to fetch rates for us-east-1: the Pricing API is throttling requests"},
+		{apiError("ExpiredTokenException"), "Unable to fetch rates for us-east-1: AWS credentials have expired"},
+		{apiError("AccessDeniedException"), "Unable to fetch rates for us-east-1: AWS credentials are missing, expired or not allowed"},
+		{&pricing.CredentialsError{Profile: "prod", Err: errors.New("no credentials")}, `Unable to fetch rates for us-east-1: no usable AWS credentials for profile "prod"`},
+		{fmt.Errorf("operation error: %w", context.DeadlineExceeded), "Unable to fetch rates for us-east-1: the Pricing API could not be reached"},
+		{fmt.Errorf("x: %w", pricing.ErrNoRegions), "Unable to fetch rates for us-east-1: the Pricing API has no EKS capability products"},
+		{errors.New("boom"), "Unable to fetch rates for us-east-1. Using default rates."},
+	}
+	for _, tt := range tests {
+		m := newReadyModel()
+		m.width, m.height = 120, 40
+		m.ratesErr = tt.err
+		if output := m.View(); !strings.Contains(output, tt.want) {
+			t.Errorf("%v: expected %q in view", tt.err, tt.want)
+		}
+	}
+}
+
+func TestBuildInputAncillaryArgoCD(t *testing.T) {
+	m := newReadyModel()
+	m.rates.ALBPerHour = 0.03
+	m.rates.EBSGBMonth = 0.1
+	cs := m.capStates[calculator.CapabilityArgoCD]
+	cs.Inputs[9].SetValue("2")
+	cs.Inputs[10].SetValue("1.5")
+	cs.Inputs[11].SetValue("20")
+	cs.Inputs[12].SetValue("4")
+
+	input := m.buildInput()
+
+	if input.SelfManagedALBsPerCluster != 2 {
+		t.Errorf("SelfManagedALBsPerCluster: got %d, want 2", input.SelfManagedALBsPerCluster)
+	}
+	if input.SelfManagedLCUsPerALB != 1.5 {
+		t.Errorf("SelfManagedLCUsPerALB: got %f, want 1.5", input.SelfManagedLCUsPerALB)
+	}
+	if input.SelfManagedEBSGBPerCluster != 20 {
+		t.Errorf("SelfManagedEBSGBPerCluster: got %f, want 20", input.SelfManagedEBSGBPerCluster)
+	}
+	if input.SelfManagedLogsGBPerCluster != 4 {
+		t.Errorf("SelfManagedLogsGBPerCluster: got %f, want 4", input.SelfManagedLogsGBPerCluster)
+	}
+	if input.SelfManagedALBCostPerHour != 0.03 {
+		t.Errorf("SelfManagedALBCostPerHour: got %f, want 0.03 from rates", input.SelfManagedALBCostPerHour)
+	}
+	if input.SelfManagedEBSCostPerGBMonth != 0.1 {
+		t.Errorf("SelfManagedEBSCostPerGBMonth: got %f, want 0.1 from rates", input.SelfManagedEBSCostPerGBMonth)
+	}
+}
+
+// Chargeback tests
+
+func TestCalculatorKeysChargeback(t *testing.T) {
+	m := newReadyModel()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	model := updated.(Model)
+	if model.view != viewChargeback {
+		t.Error("c should switch to viewChargeback")
+	}
+}
+
+func newChargebackModel() Model {
+	m := newReadyModel()
+	m.view = viewChargeback
+	return m
+}
+
+func TestChargebackAddTenant(t *testing.T) {
+	m := newChargebackModel()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	updated, _ = updated.(Model).Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	model := updated.(Model)
+
+	cs := model.activeState()
+	if len(cs.Tenants) != 2 {
+		t.Fatalf("expected 2 tenants, got %d", len(cs.Tenants))
+	}
+	if cs.Tenants[1].Name.Value() != "team-2" {
+		t.Errorf("expected default name team-2, got %q", cs.Tenants[1].Name.Value())
+	}
+	if cs.TenantFocus != 2 {
+		t.Errorf("expected focus on new tenant name (2), got %d", cs.TenantFocus)
+	}
+	if !cs.Tenants[1].Name.Focused() {
+		t.Error("new tenant name input should be focused")
+	}
+
+	// Default scenario has 5 unassigned resources, charged to the platform
+	if len(cs.Breakdown.Chargeback) != 3 {
+		t.Errorf("expected 2 tenants + platform in chargeback, got %+v", cs.Breakdown.Chargeback)
+	}
+}
+
+func TestChargebackEditTenant(t *testing.T) {
+	m := newChargebackModel()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	model := updated.(Model)
+
+	// Typing in the name field, including 'q', must not quit
+	model.activeState().Tenants[0].Name.SetValue("")
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("qa")})
+	model = updated.(Model)
+	if model.quitting {
+		t.Fatal("q should be typed into the tenant name, not quit")
+	}
+	if got := model.activeState().Tenants[0].Name.Value(); got != "qa" {
+		t.Errorf("expected tenant name %q, got %q", "qa", got)
+	}
+
+	// Tab to resources and set 5 (all of the default scenario's resources)
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model = updated.(Model)
+	model.activeState().Tenants[0].Resources.SetValue("")
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'5'}})
+	model = updated.(Model)
+
+	charges := model.activeState().Breakdown.Chargeback
+	if len(charges) != 1 || charges[0].Tenant != "qa" || charges[0].Resources != 5 {
+		t.Errorf("unexpected chargeback: %+v", charges)
+	}
+}
+
+func TestChargebackNavigationWraps(t *testing.T) {
+	m := newChargebackModel()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	model := updated.(Model)
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	model = updated.(Model)
+	if model.activeState().TenantFocus != 1 {
+		t.Errorf("shift+tab from 0 should wrap to 1, got %d", model.activeState().TenantFocus)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = updated.(Model)
+	if model.activeState().TenantFocus != 0 {
+		t.Errorf("down from 1 should wrap to 0, got %d", model.activeState().TenantFocus)
+	}
+}
+
+func TestChargebackDeleteTenant(t *testing.T) {
+	m := newChargebackModel()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	updated, _ = updated.(Model).Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	model := updated.(Model)
+
+	// Focus is on the last tenant; delete it
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	model = updated.(Model)
+	cs := model.activeState()
+	if len(cs.Tenants) != 1 {
+		t.Fatalf("expected 1 tenant, got %d", len(cs.Tenants))
+	}
+	if cs.TenantFocus != 0 {
+		t.Errorf("focus should move to remaining tenant, got %d", cs.TenantFocus)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	model = updated.(Model)
+	if len(model.activeState().Tenants) != 0 {
+		t.Error("expected no tenants")
+	}
+	if model.activeState().Breakdown.Chargeback != nil {
+		t.Error("chargeback should be cleared without tenants")
+	}
+
+	// Deleting, navigating and typing with no tenants is a no-op
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyCtrlD}, {Type: tea.KeyTab}, {Type: tea.KeyRunes, Runes: []rune{'x'}}} {
+		updated, _ = model.Update(key)
+		model = updated.(Model)
+	}
+	if len(model.activeState().Tenants) != 0 || model.activeState().TenantFocus != 0 {
+		t.Error("keys with no tenants should be no-ops")
+	}
+}
+
+func TestChargebackCyclePolicy(t *testing.T) {
+	m := newChargebackModel()
+	for _, want := range []calculator.AllocationPolicy{
+		calculator.AllocateProportional,
+		calculator.AllocatePlatform,
+		calculator.AllocateEven,
+	} {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+		m = updated.(Model)
+		if m.activeState().Allocation != want {
+			t.Errorf("expected policy %v, got %v", want, m.activeState().Allocation)
+		}
+	}
+}
+
+func TestChargebackEscAndCtrlC(t *testing.T) {
+	m := newChargebackModel()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(Model).view != viewCalculator {
+		t.Error("esc should return to calculator")
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if !updated.(Model).quitting || cmd == nil {
+		t.Error("ctrl+c should quit")
+	}
+}
+
+func TestBuildInputTenantsBlankName(t *testing.T) {
+	m := newReadyModel()
+	cs := m.activeState()
+	cs.Tenants = []views.TenantRow{{Name: newTextInput("  "), Resources: newIntInput("3")}}
+	cs.Allocation = calculator.AllocatePlatform
+
+	input := m.buildInput()
+	if len(input.Tenants) != 1 || input.Tenants[0].Name != "team-1" || input.Tenants[0].Resources != 3 {
+		t.Errorf("unexpected tenants: %+v", input.Tenants)
+	}
+	if input.Allocation != calculator.AllocatePlatform {
+		t.Errorf("expected platform allocation, got %v", input.Allocation)
+	}
+}
+
+func TestBuildInputResourceTiers(t *testing.T) {
+	m := newReadyModel()
+	m.rates.Tiers = map[pricing.Field]pricing.Schedule{
+		pricing.FieldArgoCDAppPerHour: {{EndRange: 1000, Rate: 0.0015}, {BeginRange: 1000, Rate: 0.001}},
+	}
+
+	input := m.buildInput()
+	want := []calculator.RateTier{{From: 0, To: 1000, PerHour: 0.0015}, {From: 1000, PerHour: 0.001}}
+	if len(input.ResourceTiers) != 2 || input.ResourceTiers[0] != want[0] || input.ResourceTiers[1] != want[1] {
+		t.Errorf("ResourceTiers: got %+v, want %+v", input.ResourceTiers, want)
+	}
+
+	m.activeCapability = calculator.CapabilityACK
+	if input := m.buildInput(); input.ResourceTiers != nil {
+		t.Errorf("ACK has no tiers, got %+v", input.ResourceTiers)
+	}
+}
+
+func TestViewChargeback(t *testing.T) {
+	m := newChargebackModel()
+	m.width = 120
+	m.height = 40
+	output := m.View()
+
+	if !strings.Contains(output, "CHARGEBACK REPORT") {
+		t.Error("missing chargeback report")
+	}
+	if !strings.Contains(output, "ctrl+n add tenant") {
+		t.Error("missing chargeback hints")
+	}
+}
+
+func TestDoExportChargeback(t *testing.T) {
+	m := newReadyModel()
+	m.exportDir = t.TempDir()
+	m.activeState().Tenants = []views.TenantRow{{Name: newTextInput("payments"), Resources: newIntInput("5")}}
+	m.recalculate()
+
+	model, _ := m.doExport()
+	data, err := os.ReadFile(filepath.Join(m.exportDir, "argocd-cost-estimate.csv"))
+	if err != nil {
+		t.Fatalf("reading export (%s): %v", model.exportMsg, err)
+	}
+	if !strings.Contains(string(data), "tenant:payments:total_monthly") {
+		t.Error("export should include the chargeback report")
+	}
+}
+
+func TestDoExportRateProvenance(t *testing.T) {
+	m := newReadyModel()
+	m.exportDir = t.TempDir()
+	res, _ := pricing.NewChain(pricing.DefaultsSource()).Resolve(context.Background(), "us-east-1")
+	m.rates = res.Rates
+
+	model, _ := m.doExport()
+	data, err := os.ReadFile(filepath.Join(m.exportDir, "argocd-cost-estimate.csv"))
+	if err != nil {
+		t.Fatalf("reading export (%s): %v", model.exportMsg, err)
+	}
+	if !strings.Contains(string(data), "rate:ArgoCDBasePerHour:source,defaults") {
+		t.Error("export should include where each rate came from")
+	}
+}
+
+func TestViewCalculatorRateStatus(t *testing.T) {
+	m := newReadyModel()
+	m.width = 120
+	m.height = 40
+	res, _ := pricing.NewChain(pricing.DefaultsSource()).Resolve(context.Background(), "eu-west-1")
+	updated, _ := m.Update(pricingMsg{rates: res.Rates})
+
+	output := updated.(Model).View()
+	if !strings.Contains(output, "Rates for eu-west-1: built-in defaults") {
+		t.Error("calculator should show where the rates came from")
+	}
+}
+
+// Budget planner tests
+
+func TestCalculatorKeysPlanner(t *testing.T) {
+	m := newReadyModel()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	model := updated.(Model)
+	if model.view != viewPlanner {
+		t.Error("b should switch to viewPlanner")
+	}
+	if !model.budgetInput.Focused() {
+		t.Error("budget input should be focused")
+	}
+}
+
+func TestPlannerKeys(t *testing.T) {
+	m := newReadyModel()
+	m.view = viewPlanner
+	m.budgetInput.Focus()
+	m.budgetInput.SetValue("")
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("250")})
+	model := updated.(Model)
+	if model.budgetInput.Value() != "250" {
+		t.Errorf("expected budget 250, got %q", model.budgetInput.Value())
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = updated.(Model)
+	if model.view != viewCalculator {
+		t.Error("esc should return to calculator")
+	}
+	if model.budgetInput.Focused() {
+		t.Error("budget input should be blurred after esc")
+	}
+
+	m.view = viewPlanner
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if !updated.(Model).quitting || cmd == nil {
+		t.Error("q should quit from the planner")
+	}
+}
+
+func TestPlanRows(t *testing.T) {
+	m := newReadyModel()
+	m.allRegions = []pricing.Region{{Code: "us-east-1"}, {Code: "eu-west-1"}, {Code: "us-west-2"}}
+	m.budgetInput.SetValue("100")
+
+	cached := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	cached.ArgoCDBasePerHour = 0.3 // 10x base: 219/mo per cluster
+	m.cachedRates = func(region string) *pricing.RateSheet {
+		if region == "eu-west-1" {
+			return &cached
+		}
+		return nil
+	}
+
+	capRows, regionRows := m.planRows()
+
+	if len(capRows) != 3 {
+		t.Fatalf("expected 3 capability rows, got %d", len(capRows))
+	}
+	if !capRows[0].Current || capRows[1].Current {
+		t.Error("only the active capability (ArgoCD) should be current")
+	}
+	// Default ArgoCD: 0.03 x 730 + 5 x 0.0015 x 730 = 27.375/cluster -> 3 clusters
+	if capRows[0].Capacity.MaxClusters != 3 {
+		t.Errorf("ArgoCD MaxClusters: got %d, want 3", capRows[0].Capacity.MaxClusters)
+	}
+
+	if len(regionRows) != 3 {
+		t.Fatalf("expected 3 region rows, got %d", len(regionRows))
+	}
+	if !regionRows[0].Current || regionRows[0].Capacity.MaxClusters != 3 {
+		t.Errorf("current region should use live rates: %+v", regionRows[0])
+	}
+	if regionRows[1].Missing || regionRows[1].Capacity.MaxClusters != 0 {
+		t.Errorf("eu-west-1 should use cached rates and fit no clusters: %+v", regionRows[1])
+	}
+	if !regionRows[2].Missing {
+		t.Error("us-west-2 has no cached rates and should be marked missing")
+	}
+}
+
+func TestViewPlanner(t *testing.T) {
+	m := newReadyModel()
+	m.view = viewPlanner
+	m.cachedRates = func(string) *pricing.RateSheet { return nil }
+	output := m.View()
+
+	if !strings.Contains(output, "BUDGET PLANNER") {
+		t.Error("missing planner view")
+	}
+	if !strings.Contains(output, "type a monthly budget") {
+		t.Error("missing planner hints")
+	}
+}
+
+// Currency tests
+
+func TestNewModelLoadsCurrency(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+
+	rates := `{"base":"USD","as_of":"2026-10-01","rates":{"EUR":0.5,"GBP":0.8}}`
+	if err := os.WriteFile(prefs.Path(currency.RatesFile), []byte(rates), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_ = prefs.Save(prefs.Prefs{Currency: "GBP", ExchangeRates: map[string]float64{"GBP": 0.75}})
+
+	m := NewModel()
+	if m.currency.Code != "GBP" || m.currency.Rate != 0.75 {
+		t.Errorf("expected GBP at prefs rate 0.75, got %s at %v", m.currency.Code, m.currency.Rate)
+	}
+	if m.currency.AsOf != "2026-10-01" {
+		t.Errorf("expected as_of from rates file, got %q", m.currency.AsOf)
+	}
+	if m.money.Code != "GBP" {
+		t.Error("views should render in the saved currency")
+	}
+}
+
+func TestNewModelFallsBackToUSD(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+
+	_ = prefs.Save(prefs.Prefs{Currency: "EUR"})
+
+	m := NewModel()
+	if m.currency.Code != "USD" {
+		t.Errorf("expected USD without an EUR rate, got %s", m.currency.Code)
+	}
+}
+
+func TestNewModelUsesConfiguredRateSources(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+
+	_ = prefs.Save(prefs.Prefs{RateSources: []string{"defaults"}})
+
+	m := NewModel()
+	chain, ok := m.provider.(*pricing.Chain)
+	if !ok {
+		t.Fatalf("expected a *pricing.Chain provider, got %T", m.provider)
+	}
+	if got := chain.Names(); len(got) != 1 || got[0] != "defaults" {
+		t.Errorf("expected the configured chain, got %v", got)
+	}
+}
+
+func TestNewModelFallsBackToDefaultChain(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+
+	_ = prefs.Save(prefs.Prefs{RateSources: []string{"bogus"}})
+
+	m := NewModel()
+	chain, ok := m.provider.(*pricing.Chain)
+	if !ok {
+		t.Fatalf("expected a *pricing.Chain provider, got %T", m.provider)
+	}
+	if got := chain.Names(); strings.Join(got, ",") != strings.Join(pricing.DefaultSourceOrder, ",") {
+		t.Errorf("expected the default chain for an invalid configuration, got %v", got)
+	}
+}
+
+func TestNewModelReadsRateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+
+	_ = prefs.Save(prefs.Prefs{RateSources: []string{"overrides", "defaults"}})
+	_ = os.WriteFile(filepath.Join(dir, pricing.OverridesFile), []byte(`{"*": {"ArgoCD": {"base": 0.025}}}`), 0o644)
+
+	m := NewModel()
+	if m.overridesErr != nil {
+		t.Fatalf("unexpected overrides error: %v", m.overridesErr)
+	}
+	res, err := m.provider.(*pricing.Chain).Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates := res.Rates
+	if rates.ArgoCDBasePerHour != 0.025 || rates.Provenance[pricing.FieldArgoCDBasePerHour].Source != pricing.SourceOverrides {
+		t.Errorf("expected the override, got %v %+v", rates.ArgoCDBasePerHour, rates.Provenance[pricing.FieldArgoCDBasePerHour])
+	}
+
+	updated, _ := m.Update(pricingMsg{rates: rates})
+	model := updated.(Model)
+	model.view = viewCalculator
+	model.width, model.height = 120, 40
+	if output := model.View(); !strings.Contains(output, "(override)") || !strings.Contains(output, "base from override file") {
+		t.Errorf("calculator should mark the overridden rate:\n%s", output)
+	}
+}
+
+func TestNewModelWarnsAboutInvalidRateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+
+	_ = os.WriteFile(filepath.Join(dir, pricing.OverridesFile), []byte(`{"*": {"Flux": {"base": 1}}}`), 0o644)
+
+	m := NewModel()
+	if m.overridesErr == nil {
+		t.Fatal("expected an overrides error")
+	}
+	if output := m.View(); !strings.Contains(output, "Ignoring rate overrides") || !strings.Contains(output, `unknown capability "Flux"`) {
+		t.Errorf("expected a warning about the overrides file:\n%s", output)
+	}
+}
+
+func TestNewModelAsOf(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir) // the user cache dir on macOS
+
+	cache := pricing.NewCache()
+	old := pricing.DefaultRates()
+	old.ArgoCDBasePerHour = 0.02
+	_ = cache.Save("us-east-1", old)
+	_ = cache.Save("eu-west-1", old)
+
+	asOf := time.Now().Add(time.Hour)
+	m := NewModelAsOf(asOf, Options{})
+	chain, ok := m.provider.(*pricing.Chain)
+	if !ok || strings.Join(chain.Names(), ",") != "history,defaults" {
+		t.Fatalf("expected a history chain, got %T %v", m.provider, chain)
+	}
+	res, err := chain.Resolve(context.Background(), "us-east-1")
+	rates := res.Rates
+	if err != nil || rates.ArgoCDBasePerHour != 0.02 {
+		t.Errorf("expected the recorded rate, got %v, %v", rates.ArgoCDBasePerHour, err)
+	}
+	if cached := m.cachedRates("eu-west-1"); cached == nil || cached.ArgoCDBasePerHour != 0.02 {
+		t.Errorf("planner lookups should use the history, got %+v", cached)
+	}
+	if cached := m.cachedRates("ap-south-1"); cached != nil {
+		t.Errorf("expected nothing for a region without history, got %+v", cached)
+	}
+	if m.fetchRegionsCmd() != nil {
+		t.Error("historical rates need no region discovery")
+	}
+
+	updated, _ := m.Update(pricingMsg{rates: rates})
+	model := updated.(Model)
+	model.view = viewCalculator
+	model.width, model.height = 120, 40
+	output := model.View()
+	if !strings.Contains(output, "Using rates recorded as of "+asOf.Format("2006-01-02")) || !strings.Contains(output, "rate history, fetched") {
+		t.Errorf("calculator should say the rates are historical:\n%s", output)
+	}
+	if model.rateChanges != nil {
+		t.Errorf("historical rates should not be compared, got %+v", model.rateChanges)
+	}
+}
+
+func TestNewModelAsOfOffline(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir) // the user cache dir on macOS
+
+	m := NewModelAsOf(time.Now(), Options{Offline: true})
+	if !m.offline {
+		t.Error("expected the options applied")
+	}
+	m.listRegions = func(context.Context) ([]pricing.Region, error) {
+		t.Error("region discovery should not call the Pricing API")
+		return nil, nil
+	}
+
+	var msgs []tea.Msg
+	var run func(tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, c := range batch {
+				run(c)
+			}
+			return
+		}
+		msgs = append(msgs, msg)
+	}
+	run(m.Init())
+	for _, msg := range msgs {
+		if pm, ok := msg.(pricingMsg); ok && pm.err != nil {
+			t.Errorf("expected the rates from history and defaults, got %v", pm.err)
+		}
+	}
+}
+
+func TestPricingMsgReportsRateChanges(t *testing.T) {
+	m := newReadyModel()
+	m.width, m.height = 120, 40
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	prev := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	prev.ArgoCDBasePerHour = 0.025
+	m.rateHistory = func(region string) ([]pricing.Snapshot, error) {
+		if region != m.pricingRegion {
+			t.Errorf("unexpected region %q", region)
+		}
+		return []pricing.Snapshot{{Rates: prev, FetchedAt: yesterday}, {Rates: pricing.RateSheet{Rates: pricing.DefaultRates()}, FetchedAt: time.Now()}}, nil
+	}
+
+	rates := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	rates.ArgoCDBasePerHour = 0.027
+	rates.ACKBasePerHour = 0.031
+	rates.Provenance = map[pricing.Field]pricing.Provenance{
+		pricing.FieldArgoCDBasePerHour: {Source: pricing.SourceAPI},
+		pricing.FieldACKBasePerHour:    {Source: pricing.SourceOverrides},
+	}
+	updated, _ := m.Update(pricingMsg{rates: rates})
+	model := updated.(Model)
+
+	if len(model.rateChanges) != 1 || model.rateChanges[0].Field != pricing.FieldArgoCDBasePerHour {
+		t.Fatalf("expected only the fetched ArgoCD rate reported, got %+v", model.rateChanges)
+	}
+	if !model.changesSince.Equal(yesterday) {
+		t.Errorf("expected the change measured from yesterday, got %v", model.changesSince)
+	}
+	if output := model.View(); !strings.Contains(output, "Rates changed since "+yesterday.Format("2006-01-02")) {
+		t.Errorf("calculator should show the change notice:\n%s", output)
+	}
+
+	// Without a snapshot from before today there is nothing to compare.
+	model.rateHistory = func(string) ([]pricing.Snapshot, error) {
+		return []pricing.Snapshot{{Rates: prev, FetchedAt: time.Now()}}, nil
+	}
+	updated, _ = model.Update(pricingMsg{rates: rates})
+	if changes := updated.(Model).rateChanges; changes != nil {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestNewModelUsesCachedRegions(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir) // the user cache dir on macOS
+
+	if m := NewModel(); len(m.allRegions) != len(pricing.BuiltinRegions()) {
+		t.Errorf("expected the built-in regions without a cached list, got %d", len(m.allRegions))
+	}
+
+	_ = pricing.NewCache().SaveRegions([]pricing.Region{{Code: "us-east-1"}, {Code: "xx-new-1", Name: "New"}})
+	_ = prefs.Save(prefs.Prefs{Region: "xx-new-1"})
+	m := NewModel()
+	if len(m.allRegions) != 2 || m.pricingRegion != "xx-new-1" {
+		t.Errorf("expected the cached regions, got %v in %s", m.allRegions, m.pricingRegion)
+	}
+	if regions, err := m.listRegions(context.Background()); err != nil || len(regions) != 2 {
+		t.Errorf("discovery should use the cached list while fresh, got %v, %v", regions, err)
+	}
+}
+
+func TestFetchRegionsCmd(t *testing.T) {
+	m := newReadyModel()
+	discovered := []pricing.Region{{Code: "us-east-1"}, {Code: "ca-west-1"}}
+	m.listRegions = func(context.Context) ([]pricing.Region, error) { return discovered, nil }
+	if msg, ok := m.fetchRegionsCmd()().(regionsMsg); !ok || len(msg.regions) != 2 {
+		t.Errorf("expected the discovered regions, got %+v", msg)
+	}
+
+	m.listRegions = func(context.Context) ([]pricing.Region, error) {
+		return pricing.BuiltinRegions(), errors.New("offline")
+	}
+	if msg, ok := m.fetchRegionsCmd()().(regionsMsg); !ok || msg.regions != nil {
+		t.Errorf("a failed discovery should report no regions, got %+v", msg)
+	}
+
+	m.listRegions = func(ctx context.Context) ([]pricing.Region, error) {
+		if err := lastEndpointAnswers(ctx, m.client.Endpoint()); err != nil {
+			return nil, err
+		}
+		return discovered, nil
+	}
+	if msg, ok := m.fetchRegionsCmd()().(regionsMsg); !ok || len(msg.regions) != 2 {
+		t.Errorf("expected discovery to fail over, got %+v", msg)
+	}
+}
+
+func TestRegionsMsg(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	m := newReadyModel()
+	m.regionCursor = len(m.allRegions) - 1
+
+	updated, _ := m.Update(regionsMsg{})
+	if model := updated.(Model); len(model.allRegions) != len(m.allRegions) {
+		t.Error("an empty region list should be ignored")
+	}
+
+	discovered := []pricing.Region{{Code: "us-east-1", Name: "US East (N. Virginia)"}, {Code: "ca-west-1", Name: "Canada West (Calgary)"}}
+	updated, _ = m.Update(regionsMsg{regions: discovered})
+	model := updated.(Model)
+	if len(model.allRegions) != 2 || model.regionCursor != 1 {
+		t.Errorf("expected the discovered regions and a clamped cursor, got %v at %d", model.allRegions, model.regionCursor)
+	}
+
+	model.view = viewRegions
+	model.refreshRows()
+	if output := model.View(); !strings.Contains(output, "Canada West (Calgary)") {
+		t.Errorf("the picker should show region names:\n%s", output)
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := updated.(Model).pricingRegion; got != "ca-west-1" {
+		t.Errorf("expected ca-west-1 selected, got %s", got)
+	}
+}
+
+func TestCalculatorKeysCycleCurrency(t *testing.T) {
+	dir := t.TempDir()
+	prefs.SetDir(dir)
+	defer prefs.SetDir("")
+
+	_ = prefs.Save(prefs.Prefs{Region: "eu-west-1", ExchangeRates: map[string]float64{"EUR": 0.5, "GBP": 0.8}})
+
+	m := newReadyModel()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'$'}})
+	model := updated.(Model)
+
+	if model.currency.Code != "EUR" {
+		t.Fatalf("expected EUR after one press, got %s", model.currency.Code)
+	}
+	if !strings.Contains(model.View(), "€") {
+		t.Error("calculator should render amounts in EUR")
+	}
+
+	saved := prefs.Load()
+	if saved.Currency != "EUR" || saved.Region != "eu-west-1" {
+		t.Errorf("expected currency saved alongside region, got %+v", saved)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'$'}})
+	updated, _ = updated.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'$'}})
+	if code := updated.(Model).currency.Code; code != "USD" {
+		t.Errorf("expected cycle to wrap back to USD, got %s", code)
+	}
+}
+
+func TestCycleCurrencyInvalidLocale(t *testing.T) {
+
+	m := newReadyModel()
+	m.exchangeRates = currency.Rates{Rates: map[string]float64{"EUR": 0.5}}
+	m.locale = "xx-XX"
+	m.cycleCurrency()
+
+	if m.currency.Code != "USD" {
+		t.Errorf("unsupported locale should keep the current currency, got %s", m.currency.Code)
+	}
+}
+
+func TestCompareRegions(t *testing.T) {
+	m := newReadyModel()
+	m.width, m.height = 120, 40
+	m.provider = pricing.Static(pricing.DefaultRates())
+	m.exportDir = t.TempDir()
+	m.capStates[m.activeCapability].Inputs[11].SetValue("50")
+	m.allRegions = []pricing.Region{
+		{Code: "us-east-1"}, {Code: "eu-west-1"}, {Code: "ap-south-1"},
+		{Code: "us-west-2"}, {Code: "cn-north-1"}, {Code: "cn-northwest-1"},
+	}
+
+	cheap := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	cheap.ArgoCDBasePerHour = 0.01
+	dear := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	dear.ArgoCDBasePerHour = 0.3
+	dear.EBSGBMonth = 100 // self-managed costs far more
+	dear.Provenance = map[pricing.Field]pricing.Provenance{pricing.FieldArgoCDBasePerHour: {Source: pricing.SourceDefaults}}
+	cny := pricing.RateSheet{Rates: pricing.Rates{Currency: "CNY"}}
+	m.cachedRates = func(region string) *pricing.RateSheet {
+		switch region {
+		case "us-west-2":
+			return &cheap
+		case "eu-west-1":
+			return &dear
+		case "cn-northwest-1":
+			return &cny
+		}
+		return nil
+	}
+
+	regions := func(costs []regionCost) string {
+		var codes []string
+		for _, c := range costs {
+			codes = append(codes, c.Region)
+		}
+		return strings.Join(codes, ",")
+	}
+	costs, other := m.compareRegions()
+	if got := regions(costs); got != "us-west-2,us-east-1,eu-west-1,ap-south-1" {
+		t.Errorf("expected the regions by monthly total, got %s", got)
+	}
+	if !costs[1].Current || !costs[2].Defaults || costs[0].Defaults || !costs[3].Missing {
+		t.Errorf("unexpected rows %+v", costs)
+	}
+	if strings.Join(other, ",") != "cn-north-1,cn-northwest-1" {
+		t.Errorf("expected the CNY regions apart, got %v", other)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = updated.(Model)
+	if m.view != viewCompare || !strings.Contains(m.View(), "REGION COMPARISON (ArgoCD, by monthly total)") {
+		t.Fatal("expected the region comparison")
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = updated.(Model)
+	if got := regions(m.compareCosts); got != "eu-west-1,us-west-2,us-east-1,ap-south-1" {
+		t.Errorf("expected the regions by managed vs self-managed, got %s", got)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	m = updated.(Model)
+	data, err := os.ReadFile(filepath.Join(m.exportDir, "argocd-region-comparison.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "eu-west-1,ArgoCD,total_monthly,") || strings.Contains(string(data), "ap-south-1") {
+		t.Errorf("expected the regions with rates exported, got %s", data)
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	m = updated.(Model)
+	defer m.stopWarming()
+	if cmd == nil || m.warm.total != 1 || !strings.Contains(m.View(), "Caching other regions") {
+		t.Errorf("expected the missing region fetched, got %+v", m.warm)
+	}
+	if m.fetchMissingRegions() != nil {
+		t.Error("expected no second fetch while warming")
+	}
+	m.offline = true
+	m.warm = warmState{}
+	if m.fetchMissingRegions() != nil || strings.Contains(m.View(), "press f") {
+		t.Error("expected nothing fetched offline")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(Model).view != viewCalculator {
+		t.Error("expected esc to return to the calculator")
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if updated.(Model).view != viewCompare {
+		t.Error("expected other keys ignored")
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if !updated.(Model).quitting {
+		t.Error("expected q to quit")
+	}
+}
+
+func TestRowsComputedInUpdate(t *testing.T) {
+	m := newReadyModel()
+	m.allRegions = []pricing.Region{{Code: "us-east-1"}, {Code: "eu-west-1"}}
+
+	var lookups int
+	var cached *pricing.RateSheet
+	m.cachedRates = func(string) *pricing.RateSheet {
+		lookups++
+		return cached
+	}
+
+	for _, key := range []string{"R", "b", "r"} {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model := updated.(Model)
+		lookups = 0
+		model.View()
+		updated, _ = model.Update(struct{}{})
+		updated.(Model).View()
+		if lookups != 0 {
+			t.Errorf("%s: expected rendering to reuse the rows, got %d lookups", key, lookups)
+		}
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = updated.(Model)
+	if !m.compareCosts[1].Missing {
+		t.Fatalf("expected eu-west-1 missing, got %+v", m.compareCosts)
+	}
+	rates := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	cached = &rates
+	updated, _ = m.Update(warmProgressMsg{})
+	if m = updated.(Model); m.compareCosts[1].Missing {
+		t.Errorf("expected the comparison refreshed once the region was cached, got %+v", m.compareCosts)
+	}
+}
+
+func TestCompareRegionsSelfManagedRates(t *testing.T) {
+	m := newReadyModel()
+	m.allRegions = []pricing.Region{{Code: "us-east-1"}, {Code: "eu-west-1"}, {Code: "ap-south-1"}}
+
+	eu := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	eu.FargateVCPUPerHour, eu.FargateMemGBPerHour = 0.05, 0.006
+	ap := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	ap.FargateVCPUPerHour, ap.FargateMemGBPerHour = 0.03, 0.003
+	m.cachedRates = func(region string) *pricing.RateSheet {
+		switch region {
+		case "eu-west-1":
+			return &eu
+		case "ap-south-1":
+			return &ap
+		}
+		return nil
+	}
+
+	costs, _ := m.compareRegions()
+	vcpu := make(map[string]float64)
+	for _, c := range costs {
+		vcpu[c.Region] = c.input.SelfManagedVCPUCostPerHour
+		if c.Region == "eu-west-1" && c.input.SelfManagedMemGBCostPerHour != 0.006 {
+			t.Errorf("expected eu-west-1's memory rate, got %v", c.input.SelfManagedMemGBCostPerHour)
+		}
+	}
+	if vcpu["us-east-1"] != parseFloat(fmt.Sprintf("%.4f", pricing.DefaultRates().FargateVCPUPerHour)) || vcpu["eu-west-1"] != 0.05 || vcpu["ap-south-1"] != 0.03 {
+		t.Errorf("expected each region's own Fargate rates, got %v", vcpu)
+	}
+
+	// A rate the user entered applies everywhere.
+	m.capStates[calculator.CapabilityArgoCD].Inputs[7].SetValue("0.1")
+	m.capStates[calculator.CapabilityACK].Inputs[5].SetValue("0.2")
+	if costs, _ := m.compareRegions(); costs[0].input.SelfManagedVCPUCostPerHour != 0.1 || costs[1].input.SelfManagedVCPUCostPerHour != 0.1 {
+		t.Errorf("expected the entered rate everywhere, got %+v", costs)
+	}
+	if input := m.buildInputFor(calculator.CapabilityACK, "eu-west-1", eu); input.SelfManagedVCPUCostPerHour != 0.2 || input.SelfManagedMemGBCostPerHour != 0.006 {
+		t.Errorf("expected the entered vCPU rate and eu-west-1's memory rate, got %v and %v", input.SelfManagedVCPUCostPerHour, input.SelfManagedMemGBCostPerHour)
+	}
+}
+
+func TestPlanRowsConvertsBudget(t *testing.T) {
+	m := newReadyModel()
+	m.allRegions = []pricing.Region{{Code: m.pricingRegion}}
+	m.currency, _ = currency.New("EUR", "", currency.Rates{Rates: map[string]float64{"EUR": 0.5}})
+	m.applyCurrency()
+
+	// 50 EUR is 100 USD: 3 ArgoCD clusters at 32.85/mo each
+	m.budgetInput.SetValue("50")
+	capRows, _ := m.planRows()
+
+	if capRows[0].Capacity.Budget != 100 {
+		t.Errorf("expected budget converted to 100 USD, got %.2f", capRows[0].Capacity.Budget)
+	}
+}
+
+func TestExportUsesCurrency(t *testing.T) {
+	m := newReadyModel()
+	m.exportDir = t.TempDir()
+	m.currency, _ = currency.New("EUR", "", currency.Rates{AsOf: "2026-10-01", Rates: map[string]float64{"EUR": 0.5}})
+	m.applyCurrency()
+
+	m.doExport()
+
+	data, err := os.ReadFile(filepath.Join(m.exportDir, "argocd-cost-estimate.csv"))
+	if err != nil {
+		t.Fatalf("reading export: %v", err)
+	}
+	for _, want := range []string{"currency,EUR", "exchange_rate_per_usd,0.5", "exchange_rate_as_of,2026-10-01"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("export missing %q", want)
+		}
+	}
+}
+
+func TestCNYRates(t *testing.T) {
+
+	m := newReadyModel()
+	m.warmDisabled = true
+	m.pricingRegion = "cn-north-1"
+	rates := pricing.RateSheet{Rates: pricing.Rates{Currency: "CNY", ArgoCDBasePerHour: 0.2, ArgoCDAppPerHour: 0.01, FargateVCPUPerHour: 0.3}}
+	updated, _ := m.Update(pricingMsg{rates: rates})
+	m = updated.(Model)
+
+	// Without an exchange rate for CNY, amounts are shown in CNY.
+	if m.money.Code != "CNY" || m.buildInput().Currency != "CNY" {
+		t.Errorf("expected amounts in CNY, got %+v", m.money)
+	}
+	if output := m.View(); !strings.Contains(output, "vCPU ¥/hr") || !strings.Contains(output, "¥182.50") || strings.Contains(output, "$/hr") {
+		t.Errorf("expected CNY labels and amounts:\n%s", output)
+	}
 
-	if _, ok := msg.(cacheWarmMsg); !ok {
-		t.Fatalf("expected cacheWarmMsg, got %T", msg)
+	// With one, they are converted into the chosen currency.
+	m.exchangeRates = currency.Rates{Rates: map[string]float64{"CNY": 7}}
+	m.applyCurrency()
+	if m.money.Code != "USD" || m.money.Convert(7) != 1 {
+		t.Errorf("expected CNY converted to USD, got %+v", m.money)
 	}
 
-	mu.Lock()
-	defer mu.Unlock()
-	for _, r := range calledRegions {
-		if r == "us-east-1" {
-			t.Error("skip region us-east-1 should not be called")
+	// Regions priced in another currency are left out of the plan.
+	m.allRegions = []pricing.Region{{Code: "cn-north-1"}, {Code: "cn-northwest-1"}, {Code: "us-east-1"}}
+	cny, usd := rates, pricing.RateSheet{Rates: pricing.DefaultRates()}
+	m.cachedRates = func(region string) *pricing.RateSheet {
+		if region == "us-east-1" {
+			return &usd
 		}
+		return &cny
 	}
-	if len(calledRegions) != 2 {
-		t.Errorf("expected 2 regions called, got %d: %v", len(calledRegions), calledRegions)
+	if _, regionRows := m.planRows(); len(regionRows) != 2 {
+		t.Errorf("expected the China regions only, got %+v", regionRows)
 	}
 }
@@ -7,8 +7,10 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
 
-	"github.com/josegonzalez/aws-eks-calculator/internal/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
 	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
 )
 
 // InputField defines a label/hint pair for an input field.
@@ -18,8 +20,8 @@ type InputField struct {
 }
 
 // InputFieldsForCapability returns the input field definitions for a capability.
-// ArgoCD has 9 inputs (including AppTemplates/ClustersPerTemplate),
-// ACK and kro have 7 inputs.
+// ArgoCD has 13 inputs (including AppTemplates/ClustersPerTemplate and the
+// ancillary self-managed infrastructure), ACK and kro have 7 inputs.
 func InputFieldsForCapability(cap calculator.Capability) []InputField {
 	base := []InputField{
 		{"Clusters", "Number of EKS clusters with the capability enabled. Each cluster incurs a base fee."},
@@ -58,6 +60,15 @@ func InputFieldsForCapability(cap calculator.Capability) []InputField {
 		InputField{"Mem GB $/hr", "Fargate memory cost per GB-hour. Fetched from AWS Pricing API; override for custom pricing."},
 	)
 
+	if cap == calculator.CapabilityArgoCD {
+		base = append(base,
+			InputField{"ALBs/cluster", "Application Load Balancers fronting the ArgoCD UI/API per cluster. 0 excludes load balancer costs."},
+			InputField{"LCUs/ALB", "Average Load Balancer Capacity Units consumed per ALB each hour."},
+			InputField{"EBS GB/cluster", "Persistent storage (GB) per cluster for redis and repo-server caches. Billed per GB-month."},
+			InputField{"Logs GB/mo", "CloudWatch Logs ingestion (GB) per cluster per month."},
+		)
+	}
+
 	return base
 }
 
@@ -110,8 +121,9 @@ func totalResourcesLabel(cap calculator.Capability) string {
 }
 
 // RenderCalculator renders the main calculator view with inputs on the left
-// and cost breakdown on the right.
-func RenderCalculator(cap calculator.Capability, inputs []textinput.Model, focusIndex int, input calculator.ScenarioInput, breakdown calculator.CostBreakdown, width, height int) string {
+// and cost breakdown on the right. Rates taken from the overrides file are
+// marked in the breakdown.
+func RenderCalculator(cap calculator.Capability, inputs []textinput.Model, focusIndex int, input calculator.ScenarioInput, breakdown calculator.CostBreakdown, rates pricing.RateSheet, money currency.Formatter, width, height int) string {
 	leftWidth := 32
 	rightWidth := width - leftWidth - 5
 	if rightWidth < 40 {
@@ -119,7 +131,7 @@ func RenderCalculator(cap calculator.Capability, inputs []textinput.Model, focus
 	}
 
 	left := renderInputPanel(cap, inputs, focusIndex, breakdown, leftWidth, input.Region)
-	right := renderBreakdownPanel(cap, input, breakdown, rightWidth)
+	right := renderBreakdownPanel(cap, input, breakdown, rates, money, rightWidth)
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, left, "  ", right)
 }
@@ -127,6 +139,11 @@ func RenderCalculator(cap calculator.Capability, inputs []textinput.Model, focus
 func renderInputPanel(cap calculator.Capability, inputs []textinput.Model, focusIndex int, breakdown calculator.CostBreakdown, width int, region string) string {
 	var b strings.Builder
 	labels := inputLabelsForCapability(cap)
+	// Rate inputs hold rates unconverted, in the currency of the rates.
+	symbol := currency.Formatter{Code: breakdown.Currency}.Symbol()
+	for i, label := range labels {
+		labels[i] = strings.Replace(label, "$", symbol, 1)
+	}
 
 	b.WriteString(styles.SectionStyle.Render("EKS-MANAGED COSTS"))
 	b.WriteString("\n\n")
@@ -161,7 +178,13 @@ func renderInputPanel(cap calculator.Capability, inputs []textinput.Model, focus
 	// Self-managed section
 	b.WriteString(styles.SectionStyle.Render("SELF-MANAGED COSTS"))
 	b.WriteString("\n\n")
+	ancillaryIdx := inputIdx + 4
 	for i := inputIdx; i < len(inputs); i++ {
+		if cap == calculator.CapabilityArgoCD && i == ancillaryIdx {
+			b.WriteString("\n")
+			b.WriteString(styles.SubSectionStyle.Render("  Ancillary Infra"))
+			b.WriteString("\n")
+		}
 		renderInput(&b, labels[i], inputs[i], i == focusIndex)
 	}
 
@@ -188,8 +211,9 @@ func renderInput(b *strings.Builder, label string, input textinput.Model, focuse
 	)
 }
 
-func renderBreakdownPanel(cap calculator.Capability, input calculator.ScenarioInput, breakdown calculator.CostBreakdown, width int) string {
+func renderBreakdownPanel(cap calculator.Capability, input calculator.ScenarioInput, breakdown calculator.CostBreakdown, rates pricing.RateSheet, money currency.Formatter, width int) string {
 	var b strings.Builder
+	baseField, resField := pricing.FieldsForCapability(cap)
 
 	b.WriteString(styles.SectionStyle.Render("EKS-MANAGED COST BREAKDOWN"))
 	b.WriteString("\n\n")
@@ -197,38 +221,53 @@ func renderBreakdownPanel(cap calculator.Capability, input calculator.ScenarioIn
 	// Base capability
 	fmt.Fprintf(&b, "  %s  %s\n",
 		styles.LabelStyle.Render("Base capability"),
-		styles.MoneyStyle.Render(formatMoney(breakdown.BaseCapabilityMonthly)+"/mo"),
+		styles.MoneyStyle.Render(formatMoney(money, breakdown.BaseCapabilityMonthly)+"/mo"),
 	)
-	fmt.Fprintf(&b, "  %s\n",
-		styles.MutedStyle.Render(fmt.Sprintf("$%.6f/hr x %.0fh x %d clusters",
-			input.BasePerHour,
+	fmt.Fprintf(&b, "  %s%s\n",
+		styles.MutedStyle.Render(fmt.Sprintf("%s/hr x %.0fh x %d clusters",
+			formatRate(money, input.BasePerHour, 6),
 			input.HoursPerMonth,
 			input.NumClusters)),
+		overrideMark(rates, baseField, input.BasePerHour),
 	)
 
 	// Per-resource
 	resLabel := resourceLabel(cap)
 	fmt.Fprintf(&b, "  %s  %s\n",
 		styles.LabelStyle.Render(resLabel),
-		styles.MoneyStyle.Render(formatMoney(breakdown.PerResourceMonthly)+"/mo"),
-	)
-	fmt.Fprintf(&b, "  %s\n",
-		styles.MutedStyle.Render(fmt.Sprintf("$%.6f/hr x %d x %.0fh",
-			input.ResourcePerHour,
-			breakdown.TotalResources,
-			input.HoursPerMonth)),
+		styles.MoneyStyle.Render(formatMoney(money, breakdown.PerResourceMonthly)+"/mo"),
 	)
+	if len(breakdown.PerResourceTiers) == 0 {
+		fmt.Fprintf(&b, "  %s%s\n",
+			styles.MutedStyle.Render(fmt.Sprintf("%s/hr x %d x %.0fh",
+				formatRate(money, input.ResourcePerHour, 6),
+				breakdown.TotalResources,
+				input.HoursPerMonth)),
+			overrideMark(rates, resField, input.ResourcePerHour),
+		)
+	}
+	for i, tc := range breakdown.PerResourceTiers {
+		fmt.Fprintf(&b, "    %s  %s\n",
+			styles.LabelStyle.Render(fmt.Sprintf("Tier %d (%s)", i+1, tierRange(tc.Tier, i == len(breakdown.PerResourceTiers)-1))),
+			styles.MoneyStyle.Render(formatMoney(money, tc.Monthly)+"/mo"),
+		)
+		fmt.Fprintf(&b, "    %s\n",
+			styles.MutedStyle.Render(fmt.Sprintf("%s/hr x %.0f resource-hrs",
+				formatRate(money, tc.Tier.PerHour, 6),
+				tc.ResourceHours)),
+		)
+	}
 
 	// Totals
 	b.WriteString(styles.LabelStyle.Render(strings.Repeat("─", 36)))
 	b.WriteString("\n")
 	fmt.Fprintf(&b, "  %s  %s\n",
 		styles.LabelStyle.Render("MONTHLY TOTAL  "),
-		styles.BigMoneyStyle.Render(formatMoney(breakdown.TotalMonthly)),
+		styles.BigMoneyStyle.Render(formatMoney(money, breakdown.TotalMonthly)),
 	)
 	fmt.Fprintf(&b, "  %s  %s\n\n",
 		styles.LabelStyle.Render("ANNUAL TOTAL   "),
-		styles.BigMoneyStyle.Render(formatMoney(breakdown.TotalAnnual)),
+		styles.BigMoneyStyle.Render(formatMoney(money, breakdown.TotalAnnual)),
 	)
 
 	// Self-managed comparison
@@ -237,27 +276,33 @@ func renderBreakdownPanel(cap calculator.Capability, input calculator.ScenarioIn
 
 	fmt.Fprintf(&b, "  %s  %s\n",
 		styles.LabelStyle.Render("Compute        "),
-		styles.MoneyStyle.Render(formatMoney(breakdown.SelfManagedComputeMonthly)+"/mo"),
+		styles.MoneyStyle.Render(formatMoney(money, breakdown.SelfManagedComputeMonthly)+"/mo"),
 	)
-	fmt.Fprintf(&b, "  %s\n",
-		styles.MutedStyle.Render(fmt.Sprintf("(%.1f vCPU x $%.6f + %.1fGB x $%.6f)/hr",
-			input.SelfManagedVCPUPerCluster, input.SelfManagedVCPUCostPerHour,
-			input.SelfManagedMemGBPerCluster, input.SelfManagedMemGBCostPerHour)),
+	fmt.Fprintf(&b, "  %s%s\n",
+		styles.MutedStyle.Render(fmt.Sprintf("(%.1f vCPU x %s + %.1fGB x %s)/hr",
+			input.SelfManagedVCPUPerCluster, formatRate(money, input.SelfManagedVCPUCostPerHour, 6),
+			input.SelfManagedMemGBPerCluster, formatRate(money, input.SelfManagedMemGBCostPerHour, 6))),
+		overrideMark(rates, pricing.FieldFargateVCPUPerHour, input.SelfManagedVCPUCostPerHour)+
+			overrideMark(rates, pricing.FieldFargateMemGBPerHour, input.SelfManagedMemGBCostPerHour),
 	)
 	fmt.Fprintf(&b, "  %s\n",
 		styles.MutedStyle.Render(fmt.Sprintf("x %.0fh x %d clusters",
 			input.HoursPerMonth, input.NumClusters)),
 	)
 
+	if cap == calculator.CapabilityArgoCD {
+		renderAncillaryLines(&b, input, breakdown, rates, money)
+	}
+
 	b.WriteString(styles.LabelStyle.Render(strings.Repeat("─", 36)))
 	b.WriteString("\n")
 	fmt.Fprintf(&b, "  %s  %s\n",
 		styles.LabelStyle.Render("MONTHLY TOTAL  "),
-		styles.BigMoneyStyle.Render(formatMoney(breakdown.SelfManagedTotalMonthly)),
+		styles.BigMoneyStyle.Render(formatMoney(money, breakdown.SelfManagedTotalMonthly)),
 	)
 	fmt.Fprintf(&b, "  %s  %s\n\n",
 		styles.LabelStyle.Render("ANNUAL TOTAL   "),
-		styles.BigMoneyStyle.Render(formatMoney(breakdown.SelfManagedTotalAnnual)),
+		styles.BigMoneyStyle.Render(formatMoney(money, breakdown.SelfManagedTotalAnnual)),
 	)
 
 	// Difference
@@ -276,11 +321,11 @@ func renderBreakdownPanel(cap calculator.Capability, input calculator.ScenarioIn
 	}
 	fmt.Fprintf(&b, "  %s  %s\n",
 		styles.LabelStyle.Render("Monthly        "),
-		diffStyle.Render(formatMoneyWithSign(diff)+"/mo"),
+		diffStyle.Render(formatMoneyWithSign(money, diff)+"/mo"),
 	)
 	fmt.Fprintf(&b, "  %s  %s\n",
 		styles.LabelStyle.Render("Annual         "),
-		diffStyle.Render(formatMoneyWithSign(diff*12)+"/yr"),
+		diffStyle.Render(formatMoneyWithSign(money, diff*12)+"/yr"),
 	)
 	fmt.Fprintf(&b, "  %s\n",
 		styles.MutedStyle.Render(diffLabel),
@@ -289,29 +334,61 @@ func renderBreakdownPanel(cap calculator.Capability, input calculator.ScenarioIn
 	return b.String()
 }
 
-func formatMoney(v float64) string {
-	s := fmt.Sprintf("%.2f", v)
-	// Add comma separators for thousands
-	parts := strings.SplitN(s, ".", 2)
-	intPart := parts[0]
-	if len(intPart) > 3 {
-		var result []byte
-		for i, c := range intPart {
-			if i > 0 && (len(intPart)-i)%3 == 0 {
-				result = append(result, ',')
-			}
-			result = append(result, byte(c))
-		}
-		intPart = string(result)
+// renderAncillaryLines renders one line per ancillary self-managed component.
+func renderAncillaryLines(b *strings.Builder, input calculator.ScenarioInput, breakdown calculator.CostBreakdown, rates pricing.RateSheet, money currency.Formatter) {
+	lines := []struct {
+		label  string
+		amount float64
+		detail string
+		mark   string
+	}{
+		{"Load balancer  ", breakdown.SelfManagedALBMonthly, fmt.Sprintf("%d ALB x %s/hr x %.0fh x %d clusters",
+			input.SelfManagedALBsPerCluster, formatRate(money, input.SelfManagedALBCostPerHour, 4), input.HoursPerMonth, input.NumClusters),
+			overrideMark(rates, pricing.FieldALBPerHour, input.SelfManagedALBCostPerHour)},
+		{"ALB LCUs       ", breakdown.SelfManagedLCUMonthly, fmt.Sprintf("%d ALB x %.1f LCU x %s/hr x %.0fh x %d clusters",
+			input.SelfManagedALBsPerCluster, input.SelfManagedLCUsPerALB, formatRate(money, input.SelfManagedLCUCostPerHour, 4), input.HoursPerMonth, input.NumClusters),
+			overrideMark(rates, pricing.FieldALBLCUPerHour, input.SelfManagedLCUCostPerHour)},
+		{"EBS storage    ", breakdown.SelfManagedEBSMonthly, fmt.Sprintf("%.0fGB x %s/GB-mo x %.0fh/%.0fh x %d clusters",
+			input.SelfManagedEBSGBPerCluster, formatRate(money, input.SelfManagedEBSCostPerGBMonth, 4), input.HoursPerMonth, calculator.DefaultHoursPerMonth, input.NumClusters),
+			overrideMark(rates, pricing.FieldEBSGBMonth, input.SelfManagedEBSCostPerGBMonth)},
+		{"Logs ingestion ", breakdown.SelfManagedLogsMonthly, fmt.Sprintf("%.1fGB x %s/GB x %d clusters",
+			input.SelfManagedLogsGBPerCluster, formatRate(money, input.SelfManagedLogsCostPerGB, 2), input.NumClusters),
+			overrideMark(rates, pricing.FieldCloudWatchLogsPerGB, input.SelfManagedLogsCostPerGB)},
+	}
+
+	for _, l := range lines {
+		fmt.Fprintf(b, "  %s  %s\n",
+			styles.LabelStyle.Render(l.label),
+			styles.MoneyStyle.Render(formatMoney(money, l.amount)+"/mo"),
+		)
+		fmt.Fprintf(b, "  %s%s\n", styles.MutedStyle.Render(l.detail), l.mark)
 	}
-	return "$" + intPart + "." + parts[1]
 }
 
-func formatMoneyWithSign(v float64) string {
-	if v > 0 {
-		return fmt.Sprintf("+$%.2f", v)
-	} else if v < 0 {
-		return fmt.Sprintf("-$%.2f", -v)
+// tierRange describes the resource-hours a tier covers, e.g. "0-500000 hrs"
+// or "500000+ hrs" for the last tier, which calculator.ApplyTiers leaves
+// unbounded whatever its upper bound.
+func tierRange(t calculator.RateTier, last bool) string {
+	if t.To <= 0 || last {
+		return fmt.Sprintf("%.0f+ hrs", t.From)
 	}
-	return "$0.00"
+	return fmt.Sprintf("%.0f-%.0f hrs", t.From, t.To)
+}
+
+// formatMoney formats an amount in the currency of the rates in the display
+// currency.
+func formatMoney(money currency.Formatter, v float64) string {
+	return money.Format(v)
+}
+
+// formatMoneyWithSign formats an amount in the currency of the rates in the
+// display currency with an explicit sign.
+func formatMoneyWithSign(money currency.Formatter, v float64) string {
+	return money.FormatSigned(v)
+}
+
+// formatRate formats a unit price in the currency of the rates in the display
+// currency.
+func formatRate(money currency.Formatter, v float64, decimals int) string {
+	return money.FormatRate(v, decimals)
 }
@@ -6,16 +6,22 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 
-	"github.com/josegonzalez/aws-eks-calculator/internal/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
 )
 
 func makeTestInputs(n int) []textinput.Model {
+	values13 := []string{"3", "10", "730", "0", "0", "1.0", "2.0", "0.0405", "0.0044", "1", "2.0", "10", "5.0"}
 	values9 := []string{"3", "10", "730", "0", "0", "1.0", "2.0", "0.0405", "0.0044"}
 	values7 := []string{"3", "10", "730", "1.0", "2.0", "0.0405", "0.0044"}
 	var values []string
-	if n == 9 {
+	switch n {
+	case 13:
+		values = values13
+	case 9:
 		values = values9
-	} else {
+	default:
 		values = values7
 	}
 	inputs := make([]textinput.Model, n)
@@ -40,7 +46,7 @@ func TestRenderCalculatorArgoCD(t *testing.T) {
 	}
 	breakdown := calculator.Calculate(input)
 
-	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, 120, 40)
+	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
 
 	if !strings.Contains(output, "EKS-MANAGED COSTS") {
 		t.Error("missing input panel header")
@@ -78,7 +84,7 @@ func TestRenderCalculatorACK(t *testing.T) {
 	}
 	breakdown := calculator.Calculate(input)
 
-	output := RenderCalculator(calculator.CapabilityACK, inputs, 0, input, breakdown, 120, 40)
+	output := RenderCalculator(calculator.CapabilityACK, inputs, 0, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
 
 	if strings.Contains(output, "ApplicationSets") {
 		t.Error("ACK should NOT have ApplicationSets section")
@@ -101,7 +107,7 @@ func TestRenderCalculatorKro(t *testing.T) {
 	}
 	breakdown := calculator.Calculate(input)
 
-	output := RenderCalculator(calculator.CapabilityKro, inputs, 0, input, breakdown, 120, 40)
+	output := RenderCalculator(calculator.CapabilityKro, inputs, 0, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
 
 	if strings.Contains(output, "ApplicationSets") {
 		t.Error("kro should NOT have ApplicationSets section")
@@ -125,7 +131,7 @@ func TestRenderCalculatorNarrowWidth(t *testing.T) {
 	breakdown := calculator.Calculate(input)
 
 	// Width too narrow for right panel
-	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, 50, 40)
+	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, pricing.RateSheet{}, currency.USD(), 50, 40)
 	if output == "" {
 		t.Error("should still render with narrow width")
 	}
@@ -140,7 +146,7 @@ func TestRenderBreakdownDiffZero(t *testing.T) {
 		ManagedVsSelfManaged:    0,
 	}
 
-	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, 120, 40)
+	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
 	if !strings.Contains(output, "same cost") {
 		t.Error("should show 'same cost' when difference is 0")
 	}
@@ -155,7 +161,7 @@ func TestRenderBreakdownManagedCheaper(t *testing.T) {
 		ManagedVsSelfManaged:    -20,
 	}
 
-	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, 120, 40)
+	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
 	if !strings.Contains(output, "AWS managed saves") {
 		t.Error("should show saves message when managed is cheaper")
 	}
@@ -174,7 +180,7 @@ func TestFormatMoney(t *testing.T) {
 		{1234567.89, "$1,234,567.89"},
 	}
 	for _, tt := range tests {
-		got := formatMoney(tt.input)
+		got := formatMoney(currency.USD(), tt.input)
 		if got != tt.want {
 			t.Errorf("formatMoney(%f): got %q, want %q", tt.input, got, tt.want)
 		}
@@ -191,7 +197,7 @@ func TestFormatMoneyWithSign(t *testing.T) {
 		{-5.25, "-$5.25"},
 	}
 	for _, tt := range tests {
-		got := formatMoneyWithSign(tt.input)
+		got := formatMoneyWithSign(currency.USD(), tt.input)
 		if got != tt.want {
 			t.Errorf("formatMoneyWithSign(%f): got %q, want %q", tt.input, got, tt.want)
 		}
@@ -200,8 +206,8 @@ func TestFormatMoneyWithSign(t *testing.T) {
 
 func TestInputFieldsForCapability(t *testing.T) {
 	argoCDFields := InputFieldsForCapability(calculator.CapabilityArgoCD)
-	if len(argoCDFields) != 9 {
-		t.Errorf("ArgoCD: expected 9 fields, got %d", len(argoCDFields))
+	if len(argoCDFields) != 13 {
+		t.Errorf("ArgoCD: expected 13 fields, got %d", len(argoCDFields))
 	}
 
 	ackFields := InputFieldsForCapability(calculator.CapabilityACK)
@@ -287,8 +293,139 @@ func TestRenderBreakdownManagedCostsMore(t *testing.T) {
 		ManagedVsSelfManaged:    100,
 	}
 
-	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, 120, 40)
+	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
 	if !strings.Contains(output, "AWS managed costs more") {
 		t.Error("should show 'AWS managed costs more' when diff > 0")
 	}
 }
+
+func TestRenderCalculatorArgoCDAncillary(t *testing.T) {
+	inputs := makeTestInputs(13)
+	input := calculator.ScenarioInput{
+		Capability:                   calculator.CapabilityArgoCD,
+		NumClusters:                  3,
+		ResourcesPerCluster:          10,
+		HoursPerMonth:                730,
+		SelfManagedALBsPerCluster:    1,
+		SelfManagedLCUsPerALB:        2.0,
+		SelfManagedEBSGBPerCluster:   10,
+		SelfManagedLogsGBPerCluster:  5.0,
+		SelfManagedALBCostPerHour:    0.0225,
+		SelfManagedLCUCostPerHour:    0.008,
+		SelfManagedEBSCostPerGBMonth: 0.08,
+		SelfManagedLogsCostPerGB:     0.50,
+	}
+	breakdown := calculator.Calculate(input)
+
+	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 9, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
+
+	for _, want := range []string{"Ancillary Infra", "ALBs/cluster", "Load balancer", "ALB LCUs", "EBS storage", "Logs ingestion"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in ArgoCD output", want)
+		}
+	}
+	// 0.0225 x 730 x 3 = 49.28
+	if !strings.Contains(output, "$49.28/mo") {
+		t.Error("missing load balancer monthly cost")
+	}
+
+	// EBS is priced per GB-month, so shorter months are prorated:
+	// 10 x 0.08 x 365/730 x 3 = 1.20
+	input.HoursPerMonth = 365
+	breakdown = calculator.Calculate(input)
+	output = RenderCalculator(calculator.CapabilityArgoCD, inputs, 9, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
+	for _, want := range []string{"10GB x $0.0800/GB-mo x 365h/730h x 3 clusters", "$1.20/mo"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in prorated EBS output", want)
+		}
+	}
+}
+
+func TestRenderCalculatorACKNoAncillary(t *testing.T) {
+	inputs := makeTestInputs(7)
+	input := calculator.ScenarioInput{
+		Capability:          calculator.CapabilityACK,
+		NumClusters:         3,
+		ResourcesPerCluster: 10,
+		HoursPerMonth:       730,
+	}
+	breakdown := calculator.Calculate(input)
+
+	output := RenderCalculator(calculator.CapabilityACK, inputs, 0, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
+
+	if strings.Contains(output, "Ancillary Infra") || strings.Contains(output, "Load balancer") {
+		t.Error("ancillary infrastructure should only be shown for ArgoCD")
+	}
+}
+
+func TestRenderCalculatorMarksOverriddenRates(t *testing.T) {
+	inputs := makeTestInputs(13)
+	input := calculator.ScenarioInput{
+		Capability:                   calculator.CapabilityArgoCD,
+		NumClusters:                  1,
+		ResourcesPerCluster:          10,
+		HoursPerMonth:                730,
+		BasePerHour:                  0.025,
+		ResourcePerHour:              0.0012,
+		SelfManagedVCPUCostPerHour:   0.0350,
+		SelfManagedMemGBCostPerHour:  0.004446,
+		SelfManagedALBsPerCluster:    1,
+		SelfManagedALBCostPerHour:    0.0225,
+		SelfManagedLCUCostPerHour:    0.008,
+		SelfManagedEBSCostPerGBMonth: 0.08,
+		SelfManagedLogsCostPerGB:     0.50,
+	}
+	breakdown := calculator.Calculate(input)
+
+	var rates pricing.RateSheet
+	rates.Provenance = map[pricing.Field]pricing.Provenance{}
+	for _, f := range []pricing.Field{pricing.FieldArgoCDBasePerHour, pricing.FieldFargateVCPUPerHour, pricing.FieldEBSGBMonth} {
+		rates.Provenance[f] = pricing.Provenance{Source: pricing.SourceOverrides}
+	}
+	rates.ArgoCDBasePerHour = 0.025
+	rates.FargateVCPUPerHour = 0.035
+	rates.EBSGBMonth = 0.09 // edited away from the override since
+	rates.ArgoCDAppPerHour = 0.0012
+	rates.Provenance[pricing.FieldArgoCDAppPerHour] = pricing.Provenance{Source: pricing.SourceAPI}
+
+	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, rates, currency.USD(), 120, 40)
+
+	if got := strings.Count(output, "(override)"); got != 2 {
+		t.Errorf("expected the base and vCPU rates marked, got %d markers:\n%s", got, output)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "(override)") && !strings.Contains(line, "0.025000/hr") && !strings.Contains(line, "vCPU x") {
+			t.Errorf("unexpected marker on %q", line)
+		}
+	}
+}
+
+func TestRenderCalculatorResourceTiers(t *testing.T) {
+	inputs := makeTestInputs(7)
+	input := calculator.ScenarioInput{
+		Capability:          calculator.CapabilityACK,
+		NumClusters:         10,
+		ResourcesPerCluster: 100,
+		HoursPerMonth:       730,
+		ResourcePerHour:     0.002,
+		ResourceTiers: []calculator.RateTier{
+			{From: 0, To: 500000, PerHour: 0.002},
+			{From: 500000, To: 600000, PerHour: 0.001},
+		},
+	}
+	breakdown := calculator.Calculate(input)
+
+	output := RenderCalculator(calculator.CapabilityACK, inputs, 0, input, breakdown, pricing.RateSheet{}, currency.USD(), 120, 40)
+
+	for _, want := range []string{
+		"Tier 1 (0-500000 hrs)", "$1,000.00/mo", "$0.002000/hr x 500000 resource-hrs",
+		"Tier 2 (500000+ hrs)", "$230.00/mo", "$0.001000/hr x 230000 resource-hrs",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in tiered breakdown:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "x 1000 x 730h") {
+		t.Error("tiered rates should not show the flat per-resource formula")
+	}
+}
@@ -0,0 +1,102 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
+)
+
+// TenantRow pairs the name and resource-count inputs for one tenant.
+type TenantRow struct {
+	Name      textinput.Model
+	Resources textinput.Model
+}
+
+// RenderChargeback renders the tenant editor on the left and the per-tenant
+// chargeback report on the right. focusIndex addresses the flattened inputs:
+// tenant i's name is 2*i and its resource count is 2*i+1.
+func RenderChargeback(cap calculator.Capability, rows []TenantRow, focusIndex int, policy calculator.AllocationPolicy, breakdown calculator.CostBreakdown, money currency.Formatter) string {
+	left := renderTenantPanel(cap, rows, focusIndex, policy, breakdown)
+	right := renderChargebackReport(breakdown, money)
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, "  ", right)
+}
+
+func renderTenantPanel(cap calculator.Capability, rows []TenantRow, focusIndex int, policy calculator.AllocationPolicy, breakdown calculator.CostBreakdown) string {
+	var b strings.Builder
+
+	b.WriteString(styles.SectionStyle.Render("TENANTS"))
+	b.WriteString("\n\n")
+
+	if len(rows) == 0 {
+		b.WriteString("  " + styles.MutedStyle.Render("No tenants. Press ctrl+n to add one."))
+		b.WriteString("\n")
+	}
+
+	for i, row := range rows {
+		nameStyle := styles.BlurredInputStyle
+		if focusIndex == 2*i || focusIndex == 2*i+1 {
+			nameStyle = styles.FocusedInputStyle
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n",
+			nameStyle.Render(fmt.Sprintf("%2d.", i+1)),
+			row.Name.View(),
+			row.Resources.View(),
+		)
+	}
+
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "  %s %s\n",
+		styles.LabelStyle.Render(totalResourcesLabel(cap)),
+		styles.ValueStyle.Render(fmt.Sprintf("%d", breakdown.TotalResources)),
+	)
+	fmt.Fprintf(&b, "  %s %s  %s\n",
+		styles.LabelStyle.Render("Base fee policy:"),
+		styles.ValueStyle.Render(policy.String()),
+		styles.MutedStyle.Render("(ctrl+p to change)"),
+	)
+
+	return b.String()
+}
+
+func renderChargebackReport(breakdown calculator.CostBreakdown, money currency.Formatter) string {
+	var b strings.Builder
+
+	b.WriteString(styles.SectionStyle.Render("CHARGEBACK REPORT"))
+	b.WriteString("\n\n")
+
+	fmt.Fprintf(&b, "  %s\n", styles.LabelStyle.Render(
+		fmt.Sprintf("%-16s %9s %12s %12s %12s %6s", "Tenant", "Resources", "Base", "Usage", "Total", "Share")))
+
+	for _, c := range breakdown.Chargeback {
+		fmt.Fprintf(&b, "  %s %s %s\n",
+			styles.ValueStyle.Render(fmt.Sprintf("%-16s %9d", truncate(c.Tenant, 16), c.Resources)),
+			styles.MoneyStyle.Render(fmt.Sprintf("%12s %12s %12s", formatMoney(money, c.BaseMonthly), formatMoney(money, c.ResourceMonthly), formatMoney(money, c.TotalMonthly))),
+			styles.MutedStyle.Render(fmt.Sprintf("%5.1f%%", c.Share*100)),
+		)
+	}
+
+	b.WriteString(styles.LabelStyle.Render(strings.Repeat("─", 72)))
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "  %s  %s\n",
+		styles.LabelStyle.Render("MONTHLY TOTAL  "),
+		styles.BigMoneyStyle.Render(formatMoney(money, breakdown.TotalMonthly)),
+	)
+
+	return b.String()
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,72 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+)
+
+func makeTenantRows(names ...string) []TenantRow {
+	rows := make([]TenantRow, len(names))
+	for i, n := range names {
+		rows[i].Name = textinput.New()
+		rows[i].Name.SetValue(n)
+		rows[i].Resources = textinput.New()
+		rows[i].Resources.SetValue("5")
+	}
+	return rows
+}
+
+func TestRenderChargeback(t *testing.T) {
+	input := calculator.ScenarioInput{
+		Capability:          calculator.CapabilityArgoCD,
+		NumClusters:         2,
+		ResourcesPerCluster: 10,
+		HoursPerMonth:       730,
+		BasePerHour:         0.03,
+		ResourcePerHour:     0.0015,
+		Tenants:             []calculator.Tenant{{Name: "payments", Resources: 5}, {Name: "search", Resources: 5}},
+		Allocation:          calculator.AllocateProportional,
+	}
+	breakdown := calculator.Calculate(input)
+
+	output := RenderChargeback(calculator.CapabilityArgoCD, makeTenantRows("payments", "search"), 2, input.Allocation, breakdown, currency.USD())
+
+	for _, want := range []string{"TENANTS", "CHARGEBACK REPORT", "payments", "search", "platform", "Proportional", "Total apps:", "$65.70"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in chargeback output", want)
+		}
+	}
+}
+
+func TestRenderChargebackEmpty(t *testing.T) {
+	output := RenderChargeback(calculator.CapabilityACK, nil, 0, calculator.AllocateEven, calculator.CostBreakdown{}, currency.USD())
+
+	if !strings.Contains(output, "No tenants") {
+		t.Error("missing empty state")
+	}
+	if !strings.Contains(output, "Even split") {
+		t.Error("missing allocation policy")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		input string
+		n     int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly-ten", 11, "exactly-ten"},
+		{"platform-engineering", 10, "platform-…"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.input, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d): got %q, want %q", tt.input, tt.n, got, tt.want)
+		}
+	}
+}
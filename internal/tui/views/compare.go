@@ -0,0 +1,128 @@
+package views
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
+)
+
+// CompareRow is one line of the region comparison.
+type CompareRow struct {
+	Region    string
+	Breakdown calculator.CostBreakdown
+	Missing   bool // no rates available yet (e.g. region not fetched)
+	Defaults  bool // the base or per-resource rate is a built-in default
+	Current   bool // the region the calculator is pricing
+}
+
+// CompareSort orders the region comparison.
+type CompareSort int
+
+const (
+	// SortByTotal puts the cheapest managed monthly total first.
+	SortByTotal CompareSort = iota
+	// SortByDelta puts the region where managed saves the most over
+	// self-managed first.
+	SortByDelta
+)
+
+// String names the sort order.
+func (s CompareSort) String() string {
+	if s == SortByDelta {
+		return "managed vs self-managed"
+	}
+	return "monthly total"
+}
+
+// Next returns the sort order after s.
+func (s CompareSort) Next() CompareSort {
+	return (s + 1) % 2
+}
+
+// Compare orders two rows by s: regions without rates last, ties by region
+// code.
+func (s CompareSort) Compare(a, b CompareRow) int {
+	if a.Missing != b.Missing {
+		if a.Missing {
+			return 1
+		}
+		return -1
+	}
+	if !a.Missing {
+		if c := cmp.Compare(s.key(a), s.key(b)); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a.Region, b.Region)
+}
+
+func (s CompareSort) key(row CompareRow) float64 {
+	if s == SortByDelta {
+		return row.Breakdown.ManagedVsSelfManaged
+	}
+	return row.Breakdown.TotalMonthly
+}
+
+// RenderCompare renders the active scenario priced in each region, sorted
+// by sort. otherCurrency lists the regions left out because their rates are
+// in another currency; fetchable says whether missing rates can be fetched.
+func RenderCompare(cap calculator.Capability, rows []CompareRow, sort CompareSort, otherCurrency []string, fetchable bool, money currency.Formatter) string {
+	var b strings.Builder
+
+	b.WriteString(styles.SectionStyle.Render(fmt.Sprintf("REGION COMPARISON (%s, by %s)", cap, sort)))
+	b.WriteString("\n\n")
+
+	fmt.Fprintf(&b, "  %s\n", styles.LabelStyle.Render(
+		fmt.Sprintf("%-16s %14s %16s %16s  %s", "Region", "Managed/mo", "Self-managed/mo", "vs self-managed", "Rates")))
+
+	missing := 0
+	for _, row := range rows {
+		label := fmt.Sprintf("%-16s", row.Region)
+		if row.Current {
+			label = fmt.Sprintf("%-16s", "▸ "+row.Region)
+		}
+
+		if row.Missing {
+			missing++
+			fmt.Fprintf(&b, "  %s %s\n",
+				styles.ValueStyle.Render(label),
+				styles.MutedStyle.Render(fmt.Sprintf("%14s", "not fetched")),
+			)
+			continue
+		}
+
+		deltaStyle := styles.SuccessStyle
+		if row.Breakdown.ManagedVsSelfManaged > 0 {
+			deltaStyle = styles.ErrorStyle
+		}
+		line := fmt.Sprintf("  %s %s %s",
+			styles.ValueStyle.Render(label),
+			styles.MoneyStyle.Render(fmt.Sprintf("%14s %16s",
+				formatMoney(money, row.Breakdown.TotalMonthly),
+				formatMoney(money, row.Breakdown.SelfManagedTotalMonthly))),
+			deltaStyle.Render(fmt.Sprintf("%16s", formatMoneyWithSign(money, row.Breakdown.ManagedVsSelfManaged))),
+		)
+		if row.Defaults {
+			line += "  " + styles.WarningStyle.Render("defaults")
+		}
+		b.WriteString(line + "\n")
+	}
+
+	if missing > 0 {
+		note := fmt.Sprintf("%d region(s) have no cached rates.", missing)
+		if fetchable {
+			note = fmt.Sprintf("%d region(s) have no cached rates; press f to fetch them.", missing)
+		}
+		fmt.Fprintf(&b, "\n  %s\n", styles.MutedStyle.Render(note))
+	}
+	if len(otherCurrency) > 0 {
+		fmt.Fprintf(&b, "\n  %s\n", styles.MutedStyle.Render(fmt.Sprintf(
+			"Priced in another currency, not compared: %s", strings.Join(otherCurrency, ", "))))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package views
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+)
+
+func TestRenderCompare(t *testing.T) {
+	rows := []CompareRow{
+		{Region: "us-east-1", Breakdown: calculator.CostBreakdown{TotalMonthly: 100, SelfManagedTotalMonthly: 150, ManagedVsSelfManaged: -50}, Current: true},
+		{Region: "eu-west-1", Breakdown: calculator.CostBreakdown{TotalMonthly: 120, SelfManagedTotalMonthly: 100, ManagedVsSelfManaged: 20}, Defaults: true},
+		{Region: "ap-south-1", Missing: true},
+	}
+
+	output := RenderCompare(calculator.CapabilityACK, rows, SortByDelta, []string{"cn-north-1"}, true, currency.USD())
+	for _, want := range []string{
+		"REGION COMPARISON (ACK, by managed vs self-managed)",
+		"▸ us-east-1", "$100.00", "$150.00", "-$50.00",
+		"+$20.00", "defaults",
+		"ap-south-1", "not fetched", "1 region(s) have no cached rates; press f to fetch them.",
+		"Priced in another currency, not compared: cn-north-1",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in comparison output", want)
+		}
+	}
+
+	output = RenderCompare(calculator.CapabilityACK, rows[:2], SortByTotal, nil, false, currency.USD())
+	for _, unwanted := range []string{"no cached rates", "another currency"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("unexpected %q in comparison output", unwanted)
+		}
+	}
+	if output := RenderCompare(calculator.CapabilityACK, rows[2:], SortByTotal, nil, false, currency.USD()); !strings.Contains(output, "have no cached rates.") || strings.Contains(output, "press f") {
+		t.Error("expected no fetch hint when rates cannot be fetched")
+	}
+}
+
+func TestCompareSort(t *testing.T) {
+	if SortByTotal.String() != "monthly total" || SortByDelta.String() != "managed vs self-managed" {
+		t.Error("unexpected sort names")
+	}
+	if SortByTotal.Next() != SortByDelta || SortByDelta.Next() != SortByTotal {
+		t.Error("expected the sort orders to alternate")
+	}
+
+	rows := []CompareRow{
+		{Region: "c", Missing: true},
+		{Region: "b", Breakdown: calculator.CostBreakdown{TotalMonthly: 10, ManagedVsSelfManaged: 5}},
+		{Region: "a", Missing: true},
+		{Region: "d", Breakdown: calculator.CostBreakdown{TotalMonthly: 20, ManagedVsSelfManaged: -5}},
+		{Region: "a2", Breakdown: calculator.CostBreakdown{TotalMonthly: 10, ManagedVsSelfManaged: 5}},
+	}
+	order := func(s CompareSort) string {
+		sorted := slices.Clone(rows)
+		slices.SortFunc(sorted, s.Compare)
+		var regions []string
+		for _, r := range sorted {
+			regions = append(regions, r.Region)
+		}
+		return strings.Join(regions, ",")
+	}
+	if got := order(SortByTotal); got != "a2,b,d,a,c" {
+		t.Errorf("by total: got %s", got)
+	}
+	if got := order(SortByDelta); got != "d,a2,b,a,c" {
+		t.Errorf("by delta: got %s", got)
+	}
+}
@@ -0,0 +1,60 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+func eur(t *testing.T) currency.Formatter {
+	t.Helper()
+	f, err := currency.New("EUR", "", currency.Rates{Rates: map[string]float64{"EUR": 0.5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFormatInCurrency(t *testing.T) {
+	money := eur(t)
+
+	if got := formatMoney(money, 2469.12); got != "1.234,56\u00a0€" {
+		t.Errorf("formatMoney: got %q", got)
+	}
+	if got := formatMoneyWithSign(money, -2469.12); got != "-1.234,56\u00a0€" {
+		t.Errorf("formatMoneyWithSign: got %q", got)
+	}
+	if got := formatRate(money, 0.03, 6); got != "0,015000\u00a0€" {
+		t.Errorf("formatRate: got %q", got)
+	}
+}
+
+func TestRenderCalculatorInCurrency(t *testing.T) {
+	money := eur(t)
+
+	input := calculator.DefaultInput(calculator.CapabilityArgoCD)
+	input.BasePerHour = 0.03
+	breakdown := calculator.Calculate(input)
+	inputs := makeTestInputs(13)
+
+	output := RenderCalculator(calculator.CapabilityArgoCD, inputs, 0, input, breakdown, pricing.RateSheet{}, money, 120, 40)
+	if !strings.Contains(output, formatMoney(money, breakdown.TotalMonthly)) || !strings.Contains(output, "€") {
+		t.Error("totals should be rendered in EUR")
+	}
+	if !strings.Contains(output, "0,015000\u00a0€/hr x") {
+		t.Error("rate details should be converted to EUR")
+	}
+}
+
+func TestRenderPlannerInCurrency(t *testing.T) {
+	input := calculator.DefaultInput(calculator.CapabilityArgoCD)
+	output := RenderPlanner(input, textinput.New(), nil, nil, eur(t))
+	if !strings.Contains(output, "Monthly budget €:") {
+		t.Error("budget label should use the display currency symbol")
+	}
+}
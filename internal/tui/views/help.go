@@ -17,6 +17,12 @@ func RenderHelp() string {
 		{"↑/↓ / tab / shift+tab", "Navigate between input fields"},
 		{"[ / ]", "Previous / next capability"},
 		{"r", "Open region picker"},
+		{"p", "Open AWS profile picker"},
+		{"c", "Open chargeback by tenant"},
+		{"b", "Open budget planner"},
+		{"R", "Compare regions"},
+		{"$", "Cycle display currency"},
+		{"!", "Reject or accept suspect rates"},
 		{"e", "Export current scenario to CSV"},
 		{"?", "Toggle this help overlay"},
 		{"esc", "Close overlay / go back"},
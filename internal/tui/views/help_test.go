@@ -17,6 +17,15 @@ func TestRenderHelp(t *testing.T) {
 	if !strings.Contains(output, "Previous / next capability") {
 		t.Error("missing capability switching help")
 	}
+	if !strings.Contains(output, "Open AWS profile picker") {
+		t.Error("missing profile picker help")
+	}
+	if !strings.Contains(output, "Open chargeback by tenant") {
+		t.Error("missing chargeback help")
+	}
+	if !strings.Contains(output, "Open budget planner") {
+		t.Error("missing budget planner help")
+	}
 	if !strings.Contains(output, "Quit") {
 		t.Error("missing quit help")
 	}
@@ -0,0 +1,94 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
+)
+
+// PlanRow is one line of the budget planner table.
+type PlanRow struct {
+	Label    string
+	Capacity calculator.Capacity
+	Missing  bool // no rates available yet (e.g. region not fetched)
+	Current  bool // the row matching the active capability or region
+}
+
+// RenderPlanner renders the budget planner: the budget input, followed by
+// the capacity it buys for each capability in the current region and for
+// the active capability in each region.
+func RenderPlanner(input calculator.ScenarioInput, budget textinput.Model, capRows, regionRows []PlanRow, money currency.Formatter) string {
+	var b strings.Builder
+
+	b.WriteString(styles.SectionStyle.Render("BUDGET PLANNER"))
+	b.WriteString("\n\n")
+
+	fmt.Fprintf(&b, "  %s %s\n",
+		styles.FocusedInputStyle.Render(fmt.Sprintf("%-17s", budgetLabel(money))),
+		budget.View(),
+	)
+	fmt.Fprintf(&b, "  %s\n\n", styles.MutedStyle.Render(fmt.Sprintf(
+		"Max clusters holds %d resources/cluster; max resources holds %d clusters.",
+		input.ResourcesPerCluster, input.NumClusters)))
+
+	b.WriteString(styles.SectionStyle.Render(fmt.Sprintf("BY CAPABILITY (%s)", input.Region)))
+	b.WriteString("\n\n")
+	renderPlanTable(&b, "Capability", capRows, money)
+
+	b.WriteString("\n")
+	b.WriteString(styles.SectionStyle.Render(fmt.Sprintf("BY REGION (%s)", input.Capability)))
+	b.WriteString("\n\n")
+	renderPlanTable(&b, "Region", regionRows, money)
+
+	return b.String()
+}
+
+func renderPlanTable(b *strings.Builder, heading string, rows []PlanRow, money currency.Formatter) {
+	fmt.Fprintf(b, "  %s\n", styles.LabelStyle.Render(
+		fmt.Sprintf("%-16s %14s %18s %14s", heading, "Max clusters", "Max resources/cl", "Headroom")))
+
+	for _, row := range rows {
+		label := fmt.Sprintf("%-16s", row.Label)
+		if row.Current {
+			label = fmt.Sprintf("%-16s", "▸ "+row.Label)
+		}
+
+		if row.Missing {
+			fmt.Fprintf(b, "  %s %s\n",
+				styles.ValueStyle.Render(label),
+				styles.MutedStyle.Render(fmt.Sprintf("%14s", "not fetched")),
+			)
+			continue
+		}
+
+		headroomStyle := styles.SuccessStyle
+		if !row.Capacity.Fits() {
+			headroomStyle = styles.ErrorStyle
+		}
+		fmt.Fprintf(b, "  %s %s %s\n",
+			styles.ValueStyle.Render(label),
+			styles.MoneyStyle.Render(fmt.Sprintf("%14s %18s",
+				formatCapacity(row.Capacity.MaxClusters),
+				formatCapacity(row.Capacity.MaxResourcesPerCluster))),
+			headroomStyle.Render(fmt.Sprintf("%14s", formatMoneyWithSign(money, row.Capacity.Headroom))),
+		)
+	}
+}
+
+// budgetLabel names the budget input in the display currency.
+func budgetLabel(money currency.Formatter) string {
+	return fmt.Sprintf("Monthly budget %s:", money.Symbol())
+}
+
+// formatCapacity renders a capacity count, spelling out calculator.Unlimited.
+func formatCapacity(n int) string {
+	if n == calculator.Unlimited {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d", n)
+}
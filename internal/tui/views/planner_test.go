@@ -0,0 +1,54 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+)
+
+func TestRenderPlanner(t *testing.T) {
+	input := calculator.ScenarioInput{
+		Capability:          calculator.CapabilityACK,
+		NumClusters:         3,
+		ResourcesPerCluster: 10,
+		Region:              "eu-west-1",
+	}
+	budget := textinput.New()
+	budget.SetValue("500")
+
+	capRows := []PlanRow{
+		{Label: "ArgoCD", Capacity: calculator.Capacity{MaxClusters: 12, MaxResourcesPerCluster: 40, Headroom: 100}},
+		{Label: "ACK", Capacity: calculator.Capacity{MaxClusters: calculator.Unlimited, MaxResourcesPerCluster: 7, Headroom: -25}, Current: true},
+	}
+	regionRows := []PlanRow{
+		{Label: "eu-west-1", Capacity: calculator.Capacity{MaxClusters: 9}, Current: true},
+		{Label: "us-west-2", Missing: true},
+	}
+
+	output := RenderPlanner(input, budget, capRows, regionRows, currency.USD())
+
+	for _, want := range []string{
+		"BUDGET PLANNER", "500",
+		"holds 10 resources/cluster", "holds 3 clusters",
+		"BY CAPABILITY (eu-west-1)", "BY REGION (ACK)",
+		"▸ ACK", "unlimited", "+$100.00", "-$25.00",
+		"us-west-2", "not fetched",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in planner output", want)
+		}
+	}
+}
+
+func TestFormatCapacity(t *testing.T) {
+	if got := formatCapacity(calculator.Unlimited); got != "unlimited" {
+		t.Errorf("got %q, want unlimited", got)
+	}
+	if got := formatCapacity(42); got != "42" {
+		t.Errorf("got %q, want 42", got)
+	}
+}
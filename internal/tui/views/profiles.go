@@ -0,0 +1,53 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
+)
+
+// RenderProfiles renders the AWS profile picker overlay. The empty profile
+// stands for the default credential chain; current is marked as in use.
+func RenderProfiles(profiles []string, cursor int, current, roleARN string, err error) string {
+	var b strings.Builder
+
+	b.WriteString(styles.TitleStyle.Render("Select AWS Profile"))
+	b.WriteString("\n\n")
+
+	for i, name := range profiles {
+		label := name
+		if name == "" {
+			label = "(default credential chain)"
+		}
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		line := fmt.Sprintf("%s %-30s", marker, label)
+		if i == cursor {
+			b.WriteString("  " + styles.SelectedPresetStyle.Render(line))
+		} else {
+			b.WriteString("  " + styles.NormalPresetStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	switch {
+	case err != nil:
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render(fmt.Sprintf("⚠ Unable to read the AWS config files: %v", err)))
+		b.WriteString("\n")
+	case len(profiles) <= 1:
+		b.WriteString("\n")
+		b.WriteString(styles.MutedStyle.Render("No profiles in the AWS config files"))
+		b.WriteString("\n")
+	}
+	if roleARN != "" {
+		b.WriteString("\n")
+		b.WriteString(styles.MutedStyle.Render("Assuming role " + roleARN))
+		b.WriteString("\n")
+	}
+
+	return styles.BoxStyle.Render(b.String())
+}
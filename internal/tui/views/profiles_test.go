@@ -0,0 +1,38 @@
+package views
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderProfiles(t *testing.T) {
+	output := RenderProfiles([]string{"", "dev", "prod"}, 1, "prod", "", nil)
+
+	if !strings.Contains(output, "Select AWS Profile") {
+		t.Error("missing title")
+	}
+	for _, want := range []string{"(default credential chain)", "dev", "* prod"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in %q", want, output)
+		}
+	}
+	if strings.Contains(output, "No profiles") || strings.Contains(output, "Assuming role") {
+		t.Error("unexpected notes")
+	}
+}
+
+func TestRenderProfilesNotes(t *testing.T) {
+	output := RenderProfiles([]string{""}, 0, "", "arn:aws:iam::123456789012:role/pricing", nil)
+	if !strings.Contains(output, "* (default credential chain)") || !strings.Contains(output, "No profiles in the AWS config files") {
+		t.Errorf("expected the default chain in use and no profiles, got %q", output)
+	}
+	if !strings.Contains(output, "Assuming role arn:aws:iam::123456789012:role/pricing") {
+		t.Errorf("expected the role shown, got %q", output)
+	}
+
+	output = RenderProfiles([]string{""}, 0, "", "", errors.New("permission denied"))
+	if !strings.Contains(output, "Unable to read the AWS config files: permission denied") || strings.Contains(output, "No profiles") {
+		t.Errorf("expected the read error alone, got %q", output)
+	}
+}
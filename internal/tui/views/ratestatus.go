@@ -0,0 +1,167 @@
+package views
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// RenderRateStatus renders a one-line summary of where the capability's base
+// and per-resource rates came from and how old they are. Rates without
+// provenance render nothing.
+func RenderRateStatus(cap calculator.Capability, rates pricing.RateSheet, now time.Time) string {
+	baseField, resField := pricing.FieldsForCapability(cap)
+	base, okBase := rates.Provenance[baseField]
+	res, okRes := rates.Provenance[resField]
+	if !okBase || !okRes {
+		return ""
+	}
+
+	baseDesc := describeProvenance(base, now)
+	resDesc := describeProvenance(res, now)
+	text := fmt.Sprintf("Rates for %s: %s", base.Region, baseDesc)
+	if baseDesc != resDesc {
+		text = fmt.Sprintf("Rates for %s: base from %s · per-resource from %s", base.Region, baseDesc, resDesc)
+	}
+
+	if base.Source == pricing.SourceDefaults || res.Source == pricing.SourceDefaults {
+		return styles.WarningStyle.Render(text)
+	}
+	return styles.MutedStyle.Render(text)
+}
+
+// describeProvenance names a rate's source, the Pricing API endpoint that
+// served it unless it was the default one, and, when known, how long ago it
+// was fetched or published.
+func describeProvenance(p pricing.Provenance, now time.Time) string {
+	var desc, verb string
+	switch p.Source {
+	case pricing.SourceDefaults:
+		return "built-in defaults"
+	case pricing.SourceAPI:
+		desc, verb = "live Pricing API", "fetched"
+	case pricing.SourceCache:
+		desc, verb = "cache", "fetched"
+	case pricing.SourceOffers:
+		desc, verb = "offer file", "published"
+	case pricing.SourceOverrides:
+		return "override file"
+	case pricing.SourceHistory:
+		desc, verb = "rate history", "fetched"
+	case "":
+		desc, verb = "unknown source", "fetched"
+	default:
+		desc, verb = p.Source, "fetched"
+	}
+
+	if p.Endpoint != "" && p.Endpoint != pricing.DefaultEndpoint {
+		desc += " via " + p.Endpoint
+	}
+	if p.FetchedAt.IsZero() {
+		return desc
+	}
+	return fmt.Sprintf("%s, %s %s", desc, verb, formatAge(now.Sub(p.FetchedAt), p.FetchedAt))
+}
+
+// formatAge renders a duration as a short relative age, switching to the
+// date once it is more than two days old.
+func formatAge(d time.Duration, at time.Time) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return "on " + at.Format("2006-01-02")
+	}
+}
+
+// maxListedChanges is how many rate changes RenderRateChanges, and suspect
+// rates RenderAnomalies, spell out.
+const maxListedChanges = 3
+
+// RenderRateChanges renders a notice listing rates that changed since the
+// snapshot taken at since, or "" if none did.
+func RenderRateChanges(changes []pricing.RateChange, since time.Time, money currency.Formatter) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	listed := changes
+	if len(listed) > maxListedChanges {
+		listed = listed[:maxListedChanges]
+	}
+	parts := make([]string, 0, len(listed)+1)
+	for _, c := range listed {
+		parts = append(parts, fmt.Sprintf("%s %s → %s (%+.1f%%)", c.Field, formatRate(money, c.From, 6), formatRate(money, c.To, 6), c.Percent()))
+	}
+	if more := len(changes) - len(listed); more > 0 {
+		parts = append(parts, fmt.Sprintf("and %d more", more))
+	}
+	return styles.WarningStyle.Render(fmt.Sprintf("⚠ Rates changed since %s: %s", since.Format("2006-01-02"), strings.Join(parts, ", ")))
+}
+
+// RenderAnomalies renders a warning listing suspect rates, or "" if there
+// are none. It says whether they were used or rejected, and how to switch.
+func RenderAnomalies(anomalies []pricing.Anomaly, rejected bool) string {
+	if len(anomalies) == 0 {
+		return ""
+	}
+
+	listed := anomalies
+	if len(listed) > maxListedChanges {
+		listed = listed[:maxListedChanges]
+	}
+	parts := make([]string, 0, len(listed)+1)
+	for _, a := range listed {
+		parts = append(parts, a.String())
+	}
+	if more := len(anomalies) - len(listed); more > 0 {
+		parts = append(parts, fmt.Sprintf("and %d more", more))
+	}
+
+	action := "used; ! to reject"
+	if rejected {
+		action = "replaced by the next source; ! to accept"
+	}
+	return styles.WarningStyle.Render(fmt.Sprintf("⚠ Suspect rates (%s): %s", action, strings.Join(parts, ", ")))
+}
+
+// warmBarWidth is the width of the cache warming progress bar.
+const warmBarWidth = 20
+
+// RenderWarmProgress renders a progress bar for background cache warming,
+// e.g. "Caching other regions [██████····] 12/22 (1 failed)".
+func RenderWarmProgress(done, total, failed int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * warmBarWidth / total
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("·", warmBarWidth-filled)
+	line := fmt.Sprintf("Caching other regions [%s] %d/%d", bar, done, total)
+	if failed > 0 {
+		line += fmt.Sprintf(" (%d failed)", failed)
+	}
+	return styles.MutedStyle.Render(line)
+}
+
+// overrideTolerance absorbs the rounding of rates shown in editable inputs.
+const overrideTolerance = 5e-5
+
+// overrideMark returns a marker for a breakdown line whose rate v comes from
+// the overrides file, or "" otherwise. A rate the user has since edited away
+// from the overridden value is not marked.
+func overrideMark(rates pricing.RateSheet, f pricing.Field, v float64) string {
+	if rates.Provenance[f].Source != pricing.SourceOverrides || math.Abs(rates.Get(f)-v) > overrideTolerance {
+		return ""
+	}
+	return styles.WarningStyle.Render(" (override)")
+}
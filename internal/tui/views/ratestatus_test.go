@@ -0,0 +1,134 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+var statusNow = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func ratesFrom(base, res pricing.Provenance) pricing.RateSheet {
+	r := pricing.RateSheet{Rates: pricing.DefaultRates()}
+	r.Provenance = map[pricing.Field]pricing.Provenance{
+		pricing.FieldArgoCDBasePerHour: base,
+		pricing.FieldArgoCDAppPerHour:  res,
+	}
+	return r
+}
+
+func TestRenderRateStatusSingleSource(t *testing.T) {
+	p := pricing.Provenance{Source: pricing.SourceCache, Region: "eu-west-1", FetchedAt: statusNow.Add(-3 * time.Hour)}
+	output := RenderRateStatus(calculator.CapabilityArgoCD, ratesFrom(p, p), statusNow)
+
+	if !strings.Contains(output, "Rates for eu-west-1: cache, fetched 3h ago") {
+		t.Errorf("unexpected status %q", output)
+	}
+}
+
+func TestRenderRateStatusMixedSources(t *testing.T) {
+	base := pricing.Provenance{Source: pricing.SourceOffers, Region: "us-east-1", FetchedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}
+	res := pricing.Provenance{Source: pricing.SourceDefaults, Region: "us-east-1"}
+	output := RenderRateStatus(calculator.CapabilityArgoCD, ratesFrom(base, res), statusNow)
+
+	want := "Rates for us-east-1: base from offer file, published on 2026-10-01 · per-resource from built-in defaults"
+	if !strings.Contains(output, want) {
+		t.Errorf("expected %q, got %q", want, output)
+	}
+}
+
+func TestRenderRateStatusNoProvenance(t *testing.T) {
+	if output := RenderRateStatus(calculator.CapabilityACK, pricing.RateSheet{Rates: pricing.DefaultRates()}, statusNow); output != "" {
+		t.Errorf("expected nothing without provenance, got %q", output)
+	}
+}
+
+func TestDescribeProvenance(t *testing.T) {
+	tests := []struct {
+		p    pricing.Provenance
+		want string
+	}{
+		{pricing.Provenance{Source: pricing.SourceAPI, FetchedAt: statusNow.Add(-10 * time.Second)}, "live Pricing API, fetched just now"},
+		{pricing.Provenance{Source: pricing.SourceAPI, FetchedAt: statusNow.Add(-5 * time.Minute)}, "live Pricing API, fetched 5m ago"},
+		{pricing.Provenance{Source: pricing.SourceAPI, Endpoint: "us-east-1", FetchedAt: statusNow.Add(-5 * time.Minute)}, "live Pricing API, fetched 5m ago"},
+		{pricing.Provenance{Source: pricing.SourceCache, Endpoint: "eu-central-1"}, "cache via eu-central-1"},
+		{pricing.Provenance{Source: pricing.SourceCache, FetchedAt: statusNow.Add(-47 * time.Hour)}, "cache, fetched 47h ago"},
+		{pricing.Provenance{Source: pricing.SourceOffers}, "offer file"},
+		{pricing.Provenance{Source: pricing.SourceOverrides, Region: "us-east-1"}, "override file"},
+		{pricing.Provenance{Source: pricing.SourceHistory, FetchedAt: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)}, "rate history, fetched on 2026-09-01"},
+		{pricing.Provenance{Source: pricing.SourceDefaults, FetchedAt: statusNow}, "built-in defaults"},
+		{pricing.Provenance{Source: "finance", FetchedAt: statusNow.Add(-72 * time.Hour)}, "finance, fetched on 2026-10-15"},
+		{pricing.Provenance{}, "unknown source"},
+	}
+	for _, tt := range tests {
+		if got := describeProvenance(tt.p, statusNow); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestRenderRateChanges(t *testing.T) {
+	since := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	if output := RenderRateChanges(nil, since, currency.USD()); output != "" {
+		t.Errorf("expected nothing without changes, got %q", output)
+	}
+
+	changes := []pricing.RateChange{{Field: pricing.FieldArgoCDBasePerHour, From: 0.025, To: 0.027}}
+	output := RenderRateChanges(changes, since, currency.USD())
+	want := "Rates changed since 2026-10-17: ArgoCDBasePerHour $0.025000 → $0.027000 (+8.0%)"
+	if !strings.Contains(output, want) {
+		t.Errorf("expected %q, got %q", want, output)
+	}
+
+	changes = append(changes,
+		pricing.RateChange{Field: pricing.FieldACKBasePerHour, From: 0.03, To: 0.027},
+		pricing.RateChange{Field: pricing.FieldKroBasePerHour, From: 0.03, To: 0.027},
+		pricing.RateChange{Field: pricing.FieldEBSGBMonth, From: 0.08, To: 0.09},
+		pricing.RateChange{Field: pricing.FieldALBPerHour, From: 0.0225, To: 0.025},
+	)
+	output = RenderRateChanges(changes, since, currency.USD())
+	if !strings.Contains(output, "KroBasePerHour $0.030000 → $0.027000 (-10.0%), and 2 more") || strings.Contains(output, "EBSGBMonth") {
+		t.Errorf("expected three changes listed and the rest counted, got %q", output)
+	}
+}
+
+func TestRenderAnomalies(t *testing.T) {
+	if output := RenderAnomalies(nil, false); output != "" {
+		t.Errorf("expected nothing without anomalies, got %q", output)
+	}
+
+	anomalies := []pricing.Anomaly{{Field: pricing.FieldArgoCDBasePerHour, Kind: pricing.AnomalyHigh, Value: 3, Default: 0.03}}
+	want := "Suspect rates (used; ! to reject): ArgoCDBasePerHour is 3, 100x the default 0.03"
+	if output := RenderAnomalies(anomalies, false); !strings.Contains(output, want) {
+		t.Errorf("expected %q, got %q", want, output)
+	}
+
+	for range 4 {
+		anomalies = append(anomalies, pricing.Anomaly{Field: pricing.FieldKroBasePerHour, Kind: pricing.AnomalyUnit, Unit: "Seconds"})
+	}
+	output := RenderAnomalies(anomalies, true)
+	if !strings.Contains(output, "Suspect rates (replaced by the next source; ! to accept)") || !strings.Contains(output, "and 2 more") {
+		t.Errorf("expected rejected anomalies listed and the rest counted, got %q", output)
+	}
+}
+
+func TestRenderWarmProgress(t *testing.T) {
+	tests := []struct {
+		done, total, failed int
+		want                string
+	}{
+		{0, 22, 0, "Caching other regions [····················] 0/22"},
+		{11, 22, 0, "Caching other regions [██████████··········] 11/22"},
+		{22, 22, 1, "Caching other regions [████████████████████] 22/22 (1 failed)"},
+		{0, 0, 0, "Caching other regions [····················] 0/0"},
+	}
+	for _, tt := range tests {
+		if got := RenderWarmProgress(tt.done, tt.total, tt.failed); !strings.Contains(got, tt.want) {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
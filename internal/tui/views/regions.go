@@ -4,18 +4,65 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/josegonzalez/aws-eks-calculator/currency"
 	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
 )
 
-// RenderRegions renders the region picker overlay.
-func RenderRegions(regions []string, cursor int) string {
+// RegionRow is one region in the region picker, with the monthly total of
+// the active scenario at that region's rates.
+type RegionRow struct {
+	Region   pricing.Region
+	Monthly  float64
+	Missing  bool   // no rates available yet (e.g. region not fetched)
+	Currency string // set when the region is priced in another currency
+	Current  bool   // the region the calculator is pricing
+}
+
+// RenderRegions renders the region picker overlay: the filter, then the
+// regions matching it. At most maxRows regions are listed, scrolled to keep
+// the cursor in view; maxRows <= 0 lists them all.
+func RenderRegions(filter textinput.Model, rows []RegionRow, cursor, maxRows int, money currency.Formatter) string {
 	var b strings.Builder
 
 	b.WriteString(styles.TitleStyle.Render("Select Region"))
 	b.WriteString("\n\n")
+	fmt.Fprintf(&b, "  %s %s\n\n", styles.LabelStyle.Render("Filter"), filter.View())
+
+	if len(rows) == 0 {
+		b.WriteString("  " + styles.MutedStyle.Render(fmt.Sprintf("No regions match %q", filter.Value())))
+		b.WriteString("\n")
+		return styles.BoxStyle.Render(b.String())
+	}
+
+	start, end := 0, len(rows)
+	if maxRows > 0 && len(rows) > maxRows {
+		start = min(max(cursor-maxRows/2, 0), len(rows)-maxRows)
+		end = start + maxRows
+	}
 
-	for i, r := range regions {
-		line := fmt.Sprintf("%-20s", r)
+	if start > 0 {
+		b.WriteString("  " + styles.MutedStyle.Render(fmt.Sprintf("↑ %d more", start)))
+		b.WriteString("\n")
+	}
+	for i := start; i < end; i++ {
+		row := rows[i]
+		marker := " "
+		if row.Current {
+			marker = "*"
+		}
+		var cost string
+		switch {
+		case row.Missing:
+			cost = "not fetched"
+		case row.Currency != "":
+			cost = "priced in " + row.Currency
+		default:
+			cost = formatMoney(money, row.Monthly) + "/mo"
+		}
+		line := fmt.Sprintf("%s %-16s %-26s %16s", marker, row.Region.Code, row.Region.Name, cost)
 		if i == cursor {
 			b.WriteString("  " + styles.SelectedPresetStyle.Render(line))
 		} else {
@@ -23,6 +70,10 @@ func RenderRegions(regions []string, cursor int) string {
 		}
 		b.WriteString("\n")
 	}
+	if end < len(rows) {
+		b.WriteString("  " + styles.MutedStyle.Render(fmt.Sprintf("↓ %d more", len(rows)-end)))
+		b.WriteString("\n")
+	}
 
 	return styles.BoxStyle.Render(b.String())
 }
@@ -1,35 +1,93 @@
 package views
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/josegonzalez/aws-eks-calculator/currency"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
 )
 
+func testRegionRows() []RegionRow {
+	return []RegionRow{
+		{Region: pricing.Region{Code: "us-east-1", Name: "US East (N. Virginia)"}, Monthly: 123.45, Current: true},
+		{Region: pricing.Region{Code: "us-west-2", Name: "US West (Oregon)"}, Missing: true},
+		{Region: pricing.Region{Code: "cn-north-1"}, Currency: "CNY"},
+	}
+}
+
 func TestRenderRegions(t *testing.T) {
-	regions := []string{"us-east-1", "us-west-2", "eu-west-1"}
-	output := RenderRegions(regions, 0)
+	rows := testRegionRows()
+	output := RenderRegions(textinput.New(), rows, 0, 0, currency.USD())
 
-	if !strings.Contains(output, "Select Region") {
-		t.Error("missing title")
+	if !strings.Contains(output, "Select Region") || !strings.Contains(output, "Filter") {
+		t.Error("missing title or filter")
 	}
-	for _, r := range regions {
-		if !strings.Contains(output, r) {
-			t.Errorf("missing region %q", r)
+	for _, r := range rows {
+		if !strings.Contains(output, r.Region.Code) || !strings.Contains(output, r.Region.Name) {
+			t.Errorf("missing region %v", r.Region)
 		}
 	}
-	if strings.Contains(output, "navigate") {
-		t.Error("hints should not be inside the regions box")
+	for _, want := range []string{"* us-east-1", "$123.45/mo", "not fetched", "priced in CNY"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Contains(output, "navigate") || strings.Contains(output, "more") {
+		t.Error("hints and scroll markers should not be inside the regions box")
 	}
 }
 
 func TestRenderRegionsCursorMid(t *testing.T) {
-	regions := []string{"us-east-1", "us-west-2", "eu-west-1"}
-	output := RenderRegions(regions, 1)
+	rows := testRegionRows()
+	output := RenderRegions(textinput.New(), rows, 1, 0, currency.USD())
 
 	// Should still contain all regions
-	for _, r := range regions {
-		if !strings.Contains(output, r) {
-			t.Errorf("missing region %q", r)
+	for _, r := range rows {
+		if !strings.Contains(output, r.Region.Code) || !strings.Contains(output, r.Region.Name) {
+			t.Errorf("missing region %v", r.Region)
+		}
+	}
+}
+
+func TestRenderRegionsScrolls(t *testing.T) {
+	var rows []RegionRow
+	for i := range 10 {
+		rows = append(rows, RegionRow{Region: pricing.Region{Code: fmt.Sprintf("region-%d", i)}, Missing: true})
+	}
+
+	tests := []struct {
+		cursor       int
+		first, last  int
+		above, below string
+	}{
+		{0, 0, 3, "", "↓ 6 more"},
+		{5, 3, 6, "↑ 3 more", "↓ 3 more"},
+		{9, 6, 9, "↑ 6 more", ""},
+	}
+	for _, tt := range tests {
+		output := RenderRegions(textinput.New(), rows, tt.cursor, 4, currency.USD())
+		for i, r := range rows {
+			if shown := strings.Contains(output, r.Region.Code); shown != (i >= tt.first && i <= tt.last) {
+				t.Errorf("cursor %d: region %d shown=%v", tt.cursor, i, shown)
+			}
 		}
+		for _, marker := range []string{tt.above, tt.below} {
+			if marker != "" && !strings.Contains(output, marker) {
+				t.Errorf("cursor %d: missing %q", tt.cursor, marker)
+			}
+		}
+	}
+}
+
+func TestRenderRegionsNoMatch(t *testing.T) {
+	filter := textinput.New()
+	filter.SetValue("mars")
+	output := RenderRegions(filter, nil, 0, 0, currency.USD())
+	if !strings.Contains(output, `No regions match "mars"`) {
+		t.Error("expected the empty result explained")
 	}
 }
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/josegonzalez/aws-eks-calculator/internal/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
 	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
 )
 
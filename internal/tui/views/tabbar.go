@@ -3,7 +3,7 @@ package views
 import (
 	"strings"
 
-	"github.com/josegonzalez/aws-eks-calculator/internal/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
 	"github.com/josegonzalez/aws-eks-calculator/internal/tui/styles"
 )
 
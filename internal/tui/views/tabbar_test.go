@@ -4,7 +4,7 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/josegonzalez/aws-eks-calculator/internal/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
 )
 
 func TestRenderTabBar(t *testing.T) {
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// warmWorkers bounds how many regions are fetched at once while warming the
+// cache. The Pricing API client's shared rate limit applies on top.
+const warmWorkers = 4
+
+// warmProgressMsg reports that cache warming finished one region.
+type warmProgressMsg struct {
+	region string
+	err    error
+}
+
+// cacheWarmMsg is sent when background cache warming completes or is
+// cancelled.
+type cacheWarmMsg struct{}
+
+// warmState tracks background cache warming.
+type warmState struct {
+	cancel  context.CancelFunc
+	updates <-chan tea.Msg
+
+	total, done, failed int
+}
+
+// active reports whether warming is still running.
+func (w warmState) active() bool {
+	return w.updates != nil
+}
+
+// warmTargets returns the regions to warm: those named in the preferences,
+// or every known region whose prices the current region's Pricing API
+// endpoints serve, less the current one. None if warming is disabled.
+func (m Model) warmTargets() []string {
+	if m.warmDisabled {
+		return nil
+	}
+	codes := m.warmRegions
+	if len(codes) == 0 {
+		// Other partitions' endpoints usually need other credentials.
+		endpoints := pricing.PartitionOf(m.pricingRegion).Endpoints
+		for _, r := range m.allRegions {
+			if slices.Equal(r.Partition().Endpoints, endpoints) {
+				codes = append(codes, r.Code)
+			}
+		}
+	}
+
+	var targets []string
+	for _, code := range codes {
+		if code != m.pricingRegion {
+			targets = append(targets, code)
+		}
+	}
+	return targets
+}
+
+// startWarming fetches the target regions in the background, populating the
+// on-disk cache so that future region switches are instant.
+func (m *Model) startWarming() tea.Cmd {
+	return m.warmCmd(m.warmTargets())
+}
+
+// warmCmd fetches regions in the background, populating the on-disk cache.
+// Progress arrives as warmProgressMsg, followed by cacheWarmMsg.
+func (m *Model) warmCmd(regions []string) tea.Cmd {
+	if len(regions) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan tea.Msg)
+	go warmRegions(ctx, m.provider, regions, updates)
+
+	m.warm = warmState{cancel: cancel, updates: updates, total: len(regions)}
+	return waitForWarm(updates)
+}
+
+// stopWarming cancels warming if it is running. Fetches in flight are
+// abandoned.
+func (m *Model) stopWarming() {
+	if m.warm.cancel != nil {
+		m.warm.cancel()
+	}
+}
+
+// warmRegions fetches regions with a bounded pool of workers, sending one
+// warmProgressMsg per region to updates, and closes updates when done or
+// when ctx is cancelled. Each region is given long enough to retry and fail
+// over across the endpoints.
+func warmRegions(ctx context.Context, provider pricing.Provider, regions []string, updates chan<- tea.Msg) {
+	defer close(updates)
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range min(warmWorkers, len(regions)) {
+		wg.Go(func() {
+			for region := range jobs {
+				rctx, cancel := context.WithTimeout(ctx, pricing.CallTimeout(region))
+				_, _, err := resolveRates(rctx, provider, region)
+				cancel()
+
+				select {
+				case updates <- warmProgressMsg{region: region, err: err}:
+				case <-ctx.Done():
+				}
+			}
+		})
+	}
+
+feed:
+	for _, region := range regions {
+		select {
+		case jobs <- region:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// waitForWarm returns a command that delivers the next warming update.
+func waitForWarm(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return cacheWarmMsg{}
+		}
+		return msg
+	}
+}
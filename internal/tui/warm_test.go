@@ -0,0 +1,261 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/josegonzalez/aws-eks-calculator/internal/prefs"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// drainWarming feeds warming updates back into the model until warming
+// completes, and returns the final model.
+func drainWarming(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	for cmd != nil {
+		msg := cmd()
+		updated, next := m.Update(msg)
+		m = updated.(Model)
+		cmd = next
+		if _, done := msg.(cacheWarmMsg); done {
+			break
+		}
+	}
+	return m
+}
+
+func newWarmModel(provider pricing.ProviderFunc) Model {
+	m := newReadyModel()
+	m.provider = provider
+	m.pricingRegion = "us-east-1"
+	m.allRegions = []pricing.Region{{Code: "us-east-1"}, {Code: "us-east-2"}, {Code: "eu-west-1"}, {Code: "ap-south-1"}}
+	return m
+}
+
+func TestWarmingFetchesOtherRegions(t *testing.T) {
+	var mu sync.Mutex
+	var called []string
+	m := newWarmModel(func(_ context.Context, region string) (pricing.Rates, error) {
+		mu.Lock()
+		called = append(called, region)
+		mu.Unlock()
+		if region == "ap-south-1" {
+			return pricing.Rates{}, errors.New("timeout")
+		}
+		return pricing.DefaultRates(), nil
+	})
+
+	cmd := m.startWarming()
+	if !m.warm.active() || m.warm.total != 3 {
+		t.Fatalf("expected warming of 3 regions to start, got %+v", m.warm)
+	}
+	m = drainWarming(t, m, cmd)
+
+	sort.Strings(called)
+	if strings.Join(called, ",") != "ap-south-1,eu-west-1,us-east-2" {
+		t.Errorf("unexpected regions warmed: %v", called)
+	}
+	if m.warm.done != 3 || m.warm.failed != 1 {
+		t.Errorf("expected 3 done and 1 failed, got %+v", m.warm)
+	}
+	if m.warm.active() {
+		t.Error("warming should be inactive once complete")
+	}
+}
+
+// lastEndpointAnswers stands in for a Pricing API where only the last
+// endpoint for region answers and the ones before it hang: the call
+// succeeds only if ctx allows every attempt at those to time out first.
+func lastEndpointAnswers(ctx context.Context, region string) error {
+	endpoints := pricing.PartitionOf(region).Endpoints
+	failover := pricing.CallTimeout(region) / time.Duration(len(endpoints)) * time.Duration(len(endpoints)-1)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < failover {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+func TestWarmingFailsOver(t *testing.T) {
+	m := newWarmModel(func(ctx context.Context, region string) (pricing.Rates, error) {
+		if err := lastEndpointAnswers(ctx, region); err != nil {
+			return pricing.Rates{}, err
+		}
+		return pricing.DefaultRates(), nil
+	})
+
+	m = drainWarming(t, m, m.startWarming())
+	if m.warm.done != 3 || m.warm.failed != 0 {
+		t.Errorf("expected every region fetched from the last endpoint, got %+v", m.warm)
+	}
+}
+
+func TestWarmingBoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	m := newReadyModel()
+	m.provider = pricing.ProviderFunc(func(context.Context, string) (pricing.Rates, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+		return pricing.DefaultRates(), nil
+	})
+	m.allRegions = nil
+	for _, r := range pricing.BuiltinRegions() {
+		m.allRegions = append(m.allRegions, r)
+	}
+
+	m = drainWarming(t, m, m.startWarming())
+	if p := peak.Load(); p > warmWorkers || p < 2 {
+		t.Errorf("expected between 2 and %d concurrent fetches, got %d", warmWorkers, p)
+	}
+	// Every other region but the two in China, which other endpoints serve.
+	if m.warm.done != len(m.allRegions)-3 {
+		t.Errorf("expected every other region warmed, got %d", m.warm.done)
+	}
+}
+
+func TestWarmingCancelledOnQuit(t *testing.T) {
+	var cancelled atomic.Int32
+	started := make(chan struct{}, warmWorkers)
+	m := newWarmModel(func(ctx context.Context, _ string) (pricing.Rates, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		cancelled.Add(1)
+		return pricing.Rates{}, ctx.Err()
+	})
+	// More regions than workers, so that some are still queued.
+	for _, code := range []string{"eu-west-2", "eu-central-1", "ap-northeast-1"} {
+		m.allRegions = append(m.allRegions, pricing.Region{Code: code})
+	}
+
+	cmd := m.startWarming()
+	for range warmWorkers {
+		<-started
+	}
+	updated, quit := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if quit == nil {
+		t.Fatal("expected the quit command")
+	}
+
+	done := make(chan tea.Msg)
+	go func() {
+		msg := cmd()
+		for {
+			if _, ok := msg.(cacheWarmMsg); ok {
+				break
+			}
+			msg = waitForWarm(updated.(Model).warm.updates)()
+		}
+		done <- msg
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("warming did not stop after quitting")
+	}
+	if cancelled.Load() == 0 {
+		t.Error("expected in-flight fetches to see cancellation")
+	}
+}
+
+func TestWarmingStopsOnAuthError(t *testing.T) {
+	m := newReadyModel()
+	stopped := false
+	m.warm = warmState{cancel: func() { stopped = true }, updates: make(chan tea.Msg), total: 3}
+
+	updated, cmd := m.Update(warmProgressMsg{region: "us-east-2", err: authError{}})
+	m = updated.(Model)
+	if !stopped {
+		t.Error("an auth failure should stop warming")
+	}
+	if cmd == nil || m.warm.done != 1 || m.warm.failed != 1 {
+		t.Errorf("expected progress recorded and the next update awaited, got %+v", m.warm)
+	}
+
+	stopped = false
+	updated, _ = m.Update(warmProgressMsg{region: "eu-west-1", err: errors.New("timeout")})
+	if stopped || updated.(Model).warm.failed != 2 {
+		t.Error("other failures should not stop warming")
+	}
+}
+
+// authError mimics the AWS SDK's access denied API error.
+type authError struct{}
+
+func (authError) Error() string     { return "api error AccessDeniedException" }
+func (authError) ErrorCode() string { return "AccessDeniedException" }
+
+func TestWarmTargets(t *testing.T) {
+	m := newWarmModel(nil)
+
+	if got := strings.Join(m.warmTargets(), ","); got != "us-east-2,eu-west-1,ap-south-1" {
+		t.Errorf("expected every other region, got %q", got)
+	}
+
+	// GovCloud shares the commercial endpoints; China does not.
+	m.allRegions = append(m.allRegions, pricing.Region{Code: "us-gov-west-1"}, pricing.Region{Code: "cn-north-1"}, pricing.Region{Code: "cn-northwest-1"})
+	if got := strings.Join(m.warmTargets(), ","); got != "us-east-2,eu-west-1,ap-south-1,us-gov-west-1" {
+		t.Errorf("expected the regions served by the same endpoints, got %q", got)
+	}
+	m.pricingRegion = "cn-north-1"
+	if got := strings.Join(m.warmTargets(), ","); got != "cn-northwest-1" {
+		t.Errorf("expected the other China region, got %q", got)
+	}
+	m.pricingRegion = "us-east-1"
+
+	m.warmRegions = []string{"us-east-1", "eu-central-1"}
+	if got := strings.Join(m.warmTargets(), ","); got != "eu-central-1" {
+		t.Errorf("expected the configured regions less the current one, got %q", got)
+	}
+
+	m.warmDisabled = true
+	if got := m.warmTargets(); got != nil {
+		t.Errorf("expected nothing when disabled, got %v", got)
+	}
+	if cmd := m.startWarming(); cmd != nil || m.warm.active() {
+		t.Error("disabled warming should not start")
+	}
+}
+
+func TestViewWarmProgress(t *testing.T) {
+	m := newReadyModel()
+	m.width, m.height = 120, 40
+	if strings.Contains(m.View(), "Caching other regions") {
+		t.Error("no progress should show when not warming")
+	}
+
+	m.warm = warmState{updates: make(chan tea.Msg), total: 4, done: 1}
+	if !strings.Contains(m.View(), "Caching other regions") || !strings.Contains(m.View(), "1/4") {
+		t.Error("expected the warming progress while warming")
+	}
+}
+
+func TestNewModelWarmingPrefs(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	isolated := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", isolated)
+	t.Setenv("HOME", isolated) // the user cache dir on macOS
+	_ = prefs.Save(prefs.Prefs{WarmRegions: []string{"eu-west-1"}, DisableWarming: true})
+
+	m := NewModel()
+	if len(m.warmRegions) != 1 || m.warmRegions[0] != "eu-west-1" || !m.warmDisabled {
+		t.Errorf("expected warming prefs loaded, got %v %v", m.warmRegions, m.warmDisabled)
+	}
+	if m := NewModelAsOf(time.Now(), Options{}); !m.warmDisabled {
+		t.Error("historical rates should not be warmed")
+	}
+}
@@ -2,22 +2,70 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/josegonzalez/aws-eks-calculator/internal/tui"
 )
 
 var (
-	tuiRun = tui.Run
-	osExit = os.Exit
+	tuiRun               = tui.Run
+	tuiRunAsOf           = tui.RunAsOf
+	osExit               = os.Exit
+	osArgs               = os.Args
+	stdout     io.Writer = os.Stdout
 )
 
-func run() error {
-	return tuiRun()
+const usage = `Usage:
+  aws-eks-calculator                              Start the calculator
+  aws-eks-calculator --as-of YYYY-MM-DD           Start the calculator with the rates recorded on a date
+  aws-eks-calculator import-offers FILE...        Cache rates from AWS bulk offer files
+  aws-eks-calculator history REGION               List recorded rate changes for a region
+  aws-eks-calculator cache list                   List cached regions with their age and source
+  aws-eks-calculator cache purge REGION...|--all  Delete cached rates
+  aws-eks-calculator cache refresh [REGION...]    Refetch rates, ignoring the cache (default: cached regions)
+  aws-eks-calculator cache warm [REGION...]       Fetch rates not yet cached (default: every region)
+
+Options, given before the command:
+  --profile NAME                                  Call the Pricing API with an AWS shared config profile
+  --role-arn ARN                                  Assume an IAM role to call the Pricing API
+  --endpoint REGION                               Try this Pricing API endpoint first (us-east-1, eu-central-1,
+                                                  ap-south-1 or cn-northwest-1), failing over to the others
+  --offline                                       Never call AWS; use cached, override and default rates
+                                                  (also AWS_EKS_CALCULATOR_OFFLINE=1)
+  --record DIR                                    Save every Pricing API response to DIR as a fixture
+  --replay DIR                                    Answer Pricing API calls from the fixtures in DIR
+                                                  instead of AWS
+`
+
+func run(args []string) error {
+	opts, args, err := globalOptions(args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return tuiRun(opts)
+	}
+
+	switch args[0] {
+	case "import-offers":
+		return importOffers(args[1:])
+	case "history":
+		return rateHistory(args[1:])
+	case "cache":
+		return cacheCommand(args[1:], opts)
+	case "--as-of":
+		return runAsOf(args[1:], opts)
+	case "help", "-h", "--help":
+		_, err := fmt.Fprint(stdout, usage)
+		return err
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
 }
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(osArgs[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		osExit(1)
 	}
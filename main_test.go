@@ -1,16 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/josegonzalez/aws-eks-calculator/internal/prefs"
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
 )
 
 func TestRunSuccess(t *testing.T) {
 	old := tuiRun
 	defer func() { tuiRun = old }()
-	tuiRun = func() error { return nil }
+	tuiRun = func(tui.Options) error { return nil }
 
-	if err := run(); err != nil {
+	if err := run(nil); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
@@ -18,9 +26,9 @@ func TestRunSuccess(t *testing.T) {
 func TestRunError(t *testing.T) {
 	old := tuiRun
 	defer func() { tuiRun = old }()
-	tuiRun = func() error { return fmt.Errorf("test error") }
+	tuiRun = func(tui.Options) error { return fmt.Errorf("test error") }
 
-	err := run()
+	err := run(nil)
 	if err == nil {
 		t.Error("expected error")
 	}
@@ -29,7 +37,10 @@ func TestRunError(t *testing.T) {
 func TestMainSuccess(t *testing.T) {
 	oldRun := tuiRun
 	defer func() { tuiRun = oldRun }()
-	tuiRun = func() error { return nil }
+	tuiRun = func(tui.Options) error { return nil }
+	oldArgs := osArgs
+	defer func() { osArgs = oldArgs }()
+	osArgs = []string{"aws-eks-calculator"}
 
 	// main() should return without calling osExit
 	main()
@@ -40,7 +51,10 @@ func TestMainError(t *testing.T) {
 	oldExit := osExit
 	defer func() { tuiRun = oldRun; osExit = oldExit }()
 
-	tuiRun = func() error { return fmt.Errorf("test error") }
+	tuiRun = func(tui.Options) error { return fmt.Errorf("test error") }
+	oldArgs := osArgs
+	defer func() { osArgs = oldArgs }()
+	osArgs = []string{"aws-eks-calculator"}
 	exitCode := -1
 	osExit = func(code int) { exitCode = code }
 
@@ -50,3 +64,209 @@ func TestMainError(t *testing.T) {
 		t.Errorf("expected exit code 1, got %d", exitCode)
 	}
 }
+
+func TestRunHelp(t *testing.T) {
+	oldOut := stdout
+	defer func() { stdout = oldOut }()
+	var buf bytes.Buffer
+	stdout = &buf
+
+	if err := run([]string{"--help"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "import-offers") {
+		t.Errorf("usage should list subcommands, got %q", buf.String())
+	}
+
+	stdout = &failingWriter{}
+	if err := run([]string{"--help"}); err == nil {
+		t.Error("expected the write error")
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	err := run([]string{"bogus"})
+	if err == nil || !strings.Contains(err.Error(), `unknown command "bogus"`) {
+		t.Errorf("expected unknown command error, got %v", err)
+	}
+}
+
+func TestRunCredentialOptions(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	old := tuiRun
+	defer func() { tuiRun = old }()
+	var got pricing.Credentials
+	tuiRun = func(opts tui.Options) error {
+		got = opts.Credentials
+		return nil
+	}
+
+	const role = "arn:aws:iam::123456789012:role/pricing"
+	tests := []struct {
+		args []string
+		want pricing.Credentials
+	}{
+		{nil, pricing.Credentials{Profile: "saved", RoleARN: role}},
+		{[]string{"--profile", "prod"}, pricing.Credentials{Profile: "prod", RoleARN: role}},
+		{[]string{"--profile=", "--role-arn=" + role + "-2"}, pricing.Credentials{RoleARN: role + "-2"}},
+	}
+	_ = prefs.Save(prefs.Prefs{AWSProfile: "saved", AWSRoleARN: role})
+	for _, tt := range tests {
+		if err := run(tt.args); err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+		if got != tt.want {
+			t.Errorf("%v: got %+v, want %+v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRunCredentialOptionErrors(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	if err := run([]string{"--profile"}); err == nil || !strings.Contains(err.Error(), "--profile requires a value") {
+		t.Errorf("expected a missing value error, got %v", err)
+	}
+	if err := run([]string{"--role-arn", "pricing", "help"}); err == nil || !strings.Contains(err.Error(), `invalid role ARN "pricing"`) {
+		t.Errorf("expected an invalid ARN error, got %v", err)
+	}
+	if err := run([]string{"--endpoint", "eu-west-1", "help"}); err == nil || !strings.Contains(err.Error(), `invalid Pricing API endpoint "eu-west-1": use one of us-east-1, eu-central-1, ap-south-1`) {
+		t.Errorf("expected an invalid endpoint error, got %v", err)
+	}
+
+	_ = prefs.Save(prefs.Prefs{AWSRoleARN: "pricing"})
+	if err := run(nil); err == nil || !strings.Contains(err.Error(), `invalid role ARN "pricing"`) {
+		t.Errorf("expected the saved role checked, got %v", err)
+	}
+}
+
+func TestRunEndpointOption(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	old := tuiRun
+	defer func() { tuiRun = old }()
+	var endpoint string
+	tuiRun = func(opts tui.Options) error {
+		endpoint = opts.Endpoint
+		return nil
+	}
+
+	_ = prefs.Save(prefs.Prefs{PricingEndpoint: "ap-south-1"})
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "ap-south-1"},
+		{[]string{"--endpoint", "eu-central-1"}, "eu-central-1"},
+		{[]string{"--endpoint="}, ""},
+	} {
+		if err := run(tt.args); err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+		if endpoint != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.args, endpoint, tt.want)
+		}
+	}
+}
+
+func TestRunOfflineOption(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	old := tuiRun
+	defer func() { tuiRun = old }()
+	var offline bool
+	tuiRun = func(opts tui.Options) error {
+		offline = opts.Offline
+		return nil
+	}
+
+	tests := []struct {
+		pref bool
+		env  string
+		args []string
+		want bool
+	}{
+		{false, "", nil, false},
+		{true, "", nil, true},
+		{false, "1", nil, true},
+		{true, "false", nil, false},
+		{false, "", []string{"--offline"}, true},
+		{true, "", []string{"--offline=false"}, false},
+		{false, "", []string{"--profile", "prod", "--offline=true"}, true},
+	}
+	for _, tt := range tests {
+		_ = prefs.Save(prefs.Prefs{Offline: tt.pref})
+		t.Setenv(offlineEnv, tt.env)
+		if err := run(tt.args); err != nil {
+			t.Fatalf("%+v: %v", tt, err)
+		}
+		if offline != tt.want {
+			t.Errorf("%+v: got offline %v", tt, offline)
+		}
+	}
+}
+
+func TestRunOfflineOptionErrors(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+
+	if err := run([]string{"--offline=maybe"}); err == nil || !strings.Contains(err.Error(), `invalid --offline value "maybe"`) {
+		t.Errorf("expected an invalid value error, got %v", err)
+	}
+	t.Setenv(offlineEnv, "maybe")
+	if err := run(nil); err == nil || !strings.Contains(err.Error(), `invalid AWS_EKS_CALCULATOR_OFFLINE "maybe"`) {
+		t.Errorf("expected an invalid environment error, got %v", err)
+	}
+}
+
+func TestRunFixtureOptions(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	old := tuiRun
+	defer func() { tuiRun = old }()
+	var got tui.Options
+	tuiRun = func(opts tui.Options) error {
+		got = opts
+		return nil
+	}
+
+	dir := t.TempDir()
+	if err := run([]string{"--replay", dir}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Replay != dir || got.Record != "" {
+		t.Errorf("expected the replay directory, got %+v", got)
+	}
+	if err := run([]string{"--record=fixtures"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Record != "fixtures" || got.Replay != "" {
+		t.Errorf("expected the record directory, got %+v", got)
+	}
+}
+
+func TestRunFixtureOptionErrors(t *testing.T) {
+	prefs.SetDir(t.TempDir())
+	defer prefs.SetDir("")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	_ = os.WriteFile(file, nil, 0o644)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--record"}, "--record requires a value"},
+		{[]string{"--record", dir, "--replay", dir}, "cannot be used together"},
+		{[]string{"--offline", "--replay", dir}, "offline mode rules out"},
+		{[]string{"--replay", filepath.Join(dir, "missing")}, "not found"},
+		{[]string{"--replay", file}, "not found"},
+	}
+	for _, tt := range tests {
+		if err := run(tt.args); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+
+	"github.com/josegonzalez/aws-eks-calculator/internal/prefs"
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// offlineEnv overrides the offline preference when set to a value
+// strconv.ParseBool accepts, such as 1 or false.
+const offlineEnv = "AWS_EKS_CALCULATOR_OFFLINE"
+
+// globalOptions removes the leading --profile, --role-arn, --endpoint,
+// --offline, --record and --replay options from args. Options not given
+// default to the environment, then to the preferences.
+func globalOptions(args []string) (tui.Options, []string, error) {
+	p := prefs.Load()
+	opts := tui.Options{
+		Credentials: pricing.Credentials{Profile: p.AWSProfile, RoleARN: p.AWSRoleARN},
+		Endpoint:    p.PricingEndpoint,
+		Offline:     p.Offline,
+	}
+	if env := os.Getenv(offlineEnv); env != "" {
+		offline, err := strconv.ParseBool(env)
+		if err != nil {
+			return opts, nil, fmt.Errorf("invalid %s %q: use true or false", offlineEnv, env)
+		}
+		opts.Offline = offline
+	}
+
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		switch name {
+		case "--offline":
+			if !hasValue {
+				value = "true"
+			}
+			offline, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, nil, fmt.Errorf("invalid --offline value %q: use true or false", value)
+			}
+			opts.Offline = offline
+		case "--profile", "--role-arn", "--endpoint", "--record", "--replay":
+			if !hasValue {
+				if len(args) < 2 {
+					return opts, nil, fmt.Errorf("%s requires a value\n\n%s", name, usage)
+				}
+				value, args = args[1], args[1:]
+			}
+			switch name {
+			case "--profile":
+				opts.Credentials.Profile = value
+			case "--role-arn":
+				opts.Credentials.RoleARN = value
+			case "--record":
+				opts.Record = value
+			case "--replay":
+				opts.Replay = value
+			default:
+				opts.Endpoint = value
+			}
+		default:
+			return opts, args, checkOptions(opts)
+		}
+		args = args[1:]
+	}
+	return opts, args, checkOptions(opts)
+}
+
+// checkOptions rejects a role that is not an ARN, an unknown Pricing API
+// endpoint and a missing replay directory, which would otherwise only fail
+// once the Pricing API is called, and fixture options that cannot apply.
+func checkOptions(opts tui.Options) error {
+	if roleARN := opts.Credentials.RoleARN; roleARN != "" && !arn.IsARN(roleARN) {
+		return fmt.Errorf("invalid role ARN %q", roleARN)
+	}
+	if opts.Endpoint != "" && !pricing.ValidEndpoint(opts.Endpoint) {
+		return fmt.Errorf("invalid Pricing API endpoint %q: use one of %s", opts.Endpoint,
+			strings.Join(pricing.EndpointRegions(), ", "))
+	}
+
+	switch {
+	case opts.Record != "" && opts.Replay != "":
+		return fmt.Errorf("--record and --replay cannot be used together")
+	case opts.Offline && (opts.Record != "" || opts.Replay != ""):
+		return fmt.Errorf("--record and --replay stand in for the Pricing API, which offline mode rules out; add --offline=false")
+	case opts.Replay != "":
+		if info, err := os.Stat(opts.Replay); err != nil || !info.IsDir() {
+			return fmt.Errorf("replay directory %q not found", opts.Replay)
+		}
+	}
+	return nil
+}
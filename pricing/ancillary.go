@@ -0,0 +1,112 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+)
+
+// Usage type suffixes for the ancillary infrastructure a self-hosted ArgoCD
+// typically needs alongside its pods.
+const (
+	albHoursSuffix       = "LoadBalancerUsage"
+	albLCUSuffix         = "LCUUsage"
+	cloudWatchLogsSuffix = "DataProcessing-Bytes"
+)
+
+// applyAncillary fetches the ALB, EBS and CloudWatch Logs rates for the
+// region and passes any that were found to set. Each service is fetched
+// independently; failures leave the existing (default) values in place.
+func applyAncillary(ctx context.Context, client PricingAPI, region string, set func(Field, quote)) {
+	if hours, lcu, err := fetchALB(ctx, client, region); err == nil {
+		set(FieldALBPerHour, hours)
+		set(FieldALBLCUPerHour, lcu)
+	}
+
+	if gbMonth, err := fetchEBS(ctx, client, region); err == nil {
+		set(FieldEBSGBMonth, gbMonth)
+	}
+
+	if perGB, err := fetchCloudWatchLogs(ctx, client, region); err == nil {
+		set(FieldCloudWatchLogsPerGB, perGB)
+	}
+}
+
+// fetchALB returns the Application Load Balancer hourly and LCU-hour rates.
+func fetchALB(ctx context.Context, client PricingAPI, region string) (hours, lcu quote, err error) {
+	filters := []types.Filter{
+		regionFilter(region),
+		{
+			Type:  types.FilterTypeTermMatch,
+			Field: aws.String("productFamily"),
+			Value: aws.String("Load Balancer-Application"),
+		},
+	}
+
+	found, err := fetchBySuffix(ctx, client, "AWSELB", filters, []string{albHoursSuffix, albLCUSuffix})
+	if err != nil {
+		return quote{}, quote{}, fmt.Errorf("alb: %w", err)
+	}
+
+	hours, lcu = found[albHoursSuffix], found[albLCUSuffix]
+	if hours.rate <= 0 || lcu.rate <= 0 {
+		return quote{}, quote{}, fmt.Errorf("alb: %w", errNoProducts)
+	}
+
+	return hours, lcu, nil
+}
+
+// fetchEBS returns the gp3 EBS storage rate per GB-month.
+func fetchEBS(ctx context.Context, client PricingAPI, region string) (quote, error) {
+	input := &pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		Filters: []types.Filter{
+			regionFilter(region),
+			{
+				Type:  types.FilterTypeTermMatch,
+				Field: aws.String("productFamily"),
+				Value: aws.String("Storage"),
+			},
+			{
+				Type:  types.FilterTypeTermMatch,
+				Field: aws.String("volumeApiName"),
+				Value: aws.String("gp3"),
+			},
+		},
+		MaxResults: aws.Int32(1),
+	}
+
+	q, err := fetchSingleRate(ctx, client, input)
+	if err != nil {
+		return quote{}, fmt.Errorf("ebs: %w", err)
+	}
+
+	return q, nil
+}
+
+// fetchCloudWatchLogs returns the CloudWatch Logs ingestion rate per GB.
+func fetchCloudWatchLogs(ctx context.Context, client PricingAPI, region string) (quote, error) {
+	filters := []types.Filter{
+		regionFilter(region),
+		{
+			Type:  types.FilterTypeTermMatch,
+			Field: aws.String("productFamily"),
+			Value: aws.String("Data Payload"),
+		},
+	}
+
+	found, err := fetchBySuffix(ctx, client, "AmazonCloudWatch", filters, []string{cloudWatchLogsSuffix})
+	if err != nil {
+		return quote{}, fmt.Errorf("cloudwatch logs: %w", err)
+	}
+
+	q := found[cloudWatchLogsSuffix]
+	if q.rate <= 0 {
+		return quote{}, fmt.Errorf("cloudwatch logs: %w", errNoProducts)
+	}
+
+	return q, nil
+}
@@ -0,0 +1,122 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+func ancillaryProducts(region string) map[string]*pricing.GetProductsOutput {
+	return map[string]*pricing.GetProductsOutput{
+		"AWSELB:regionCode=" + region + ":productFamily=Load Balancer-Application": {
+			PriceList: []string{
+				eksProductJSON("USE1-LoadBalancerUsage", "0.0252"),
+				eksProductJSON("USE1-LCUUsage", "0.009"),
+			},
+		},
+		"AmazonEC2:regionCode=" + region + ":productFamily=Storage:volumeApiName=gp3": {
+			PriceList: []string{fargateJSON("0.088", "GB-Mo")},
+		},
+		"AmazonCloudWatch:regionCode=" + region + ":productFamily=Data Payload": {
+			PriceList: []string{
+				eksProductJSON("USE1-DataProcessing-Bytes", "0.57"),
+			},
+		},
+	}
+}
+
+func TestFetchRatesAncillarySuccess(t *testing.T) {
+	responses := allCapabilityProducts("us-east-1")
+	for k, v := range ancillaryProducts("us-east-1") {
+		responses[k] = v
+	}
+	mock := &mockPricingAPI{responses: responses}
+
+	rates, err := FetchRatesWithClient(context.Background(), mock, "us-east-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rates.ALBPerHour != 0.0252 {
+		t.Errorf("ALBPerHour: got %f, want 0.0252", rates.ALBPerHour)
+	}
+	if rates.ALBLCUPerHour != 0.009 {
+		t.Errorf("ALBLCUPerHour: got %f, want 0.009", rates.ALBLCUPerHour)
+	}
+	if rates.EBSGBMonth != 0.088 {
+		t.Errorf("EBSGBMonth: got %f, want 0.088", rates.EBSGBMonth)
+	}
+	if rates.CloudWatchLogsPerGB != 0.57 {
+		t.Errorf("CloudWatchLogsPerGB: got %f, want 0.57", rates.CloudWatchLogsPerGB)
+	}
+}
+
+func TestFetchRatesAncillaryMissingUsesDefaults(t *testing.T) {
+	mock := &mockPricingAPI{responses: allCapabilityProducts("us-east-1")}
+
+	rates, err := FetchRatesWithClient(context.Background(), mock, "us-east-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	defaults := DefaultRates()
+	if rates.ALBPerHour != defaults.ALBPerHour || rates.ALBLCUPerHour != defaults.ALBLCUPerHour {
+		t.Errorf("expected default ALB rates, got %f/%f", rates.ALBPerHour, rates.ALBLCUPerHour)
+	}
+	if rates.EBSGBMonth != defaults.EBSGBMonth {
+		t.Errorf("expected default EBSGBMonth, got %f", rates.EBSGBMonth)
+	}
+	if rates.CloudWatchLogsPerGB != defaults.CloudWatchLogsPerGB {
+		t.Errorf("expected default CloudWatchLogsPerGB, got %f", rates.CloudWatchLogsPerGB)
+	}
+}
+
+func TestFetchALBPartial(t *testing.T) {
+	mock := &mockPricingAPI{
+		responses: map[string]*pricing.GetProductsOutput{
+			"AWSELB:regionCode=us-east-1:productFamily=Load Balancer-Application": {
+				PriceList: []string{eksProductJSON("LoadBalancerUsage", "0.0225")},
+			},
+		},
+	}
+
+	if _, _, err := fetchALB(context.Background(), mock, "us-east-1"); err == nil {
+		t.Error("expected error when LCU rate is missing")
+	}
+}
+
+func TestFetchAncillaryAPIErrors(t *testing.T) {
+	mock := &mockPricingAPI{err: fmt.Errorf("throttled")}
+
+	if _, _, err := fetchALB(context.Background(), mock, "us-east-1"); err == nil {
+		t.Error("fetchALB: expected error")
+	}
+	if _, err := fetchEBS(context.Background(), mock, "us-east-1"); err == nil {
+		t.Error("fetchEBS: expected error")
+	}
+	if _, err := fetchCloudWatchLogs(context.Background(), mock, "us-east-1"); err == nil {
+		t.Error("fetchCloudWatchLogs: expected error")
+	}
+}
+
+func TestFetchCloudWatchLogsMissing(t *testing.T) {
+	mock := &mockPricingAPI{responses: map[string]*pricing.GetProductsOutput{}}
+
+	if _, err := fetchCloudWatchLogs(context.Background(), mock, "us-east-1"); err == nil {
+		t.Error("expected error when no logs product is found")
+	}
+}
+
+func TestHasAncillaryRates(t *testing.T) {
+	r := DefaultRates()
+	if !r.HasAncillaryRates() {
+		t.Error("DefaultRates() should have all ancillary rates")
+	}
+
+	r.EBSGBMonth = 0
+	if r.HasAncillaryRates() {
+		t.Error("should return false when EBSGBMonth is 0")
+	}
+}
@@ -0,0 +1,135 @@
+package pricing_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+// These assignments fail to compile if a public signature changes. Update
+// them only together with a major version bump.
+var (
+	_ func() pricing.Rates                                                                  = pricing.DefaultRates
+	_ func(context.Context, string) (pricing.Rates, error)                                  = pricing.FetchRates
+	_ func(context.Context, pricing.PricingAPI, string) (pricing.Rates, error)              = pricing.FetchRatesWithClient
+	_ func() pricing.Provider                                                               = pricing.Default
+	_ func(pricing.PricingAPI) pricing.Provider                                             = pricing.NewAPIProvider
+	_ func(pricing.Rates) pricing.Provider                                                  = pricing.Static
+	_ func() *pricing.Cache                                                                 = pricing.NewCache
+	_ func(string) *pricing.Cache                                                           = pricing.NewCacheDir
+	_ func(...string) (*pricing.Offers, error)                                              = pricing.LoadOffers
+	_ func(...io.Reader) (*pricing.Offers, error)                                           = pricing.ParseOffers
+	_ func(*pricing.Offers) []string                                                        = (*pricing.Offers).Regions
+	_ func(*pricing.Offers, *pricing.Cache) ([]string, error)                               = (*pricing.Offers).Populate
+	_ pricing.Provider                                                                      = (*pricing.Offers)(nil)
+	_ func(*pricing.Cache, string) *pricing.Rates                                           = (*pricing.Cache).Load
+	_ func(*pricing.Cache, string, pricing.Rates) error                                     = (*pricing.Cache).Save
+	_ func(*pricing.Cache, string, pricing.RateSheet) error                                 = (*pricing.Cache).SaveSheet
+	_ func(*pricing.Cache, time.Duration)                                                   = (*pricing.Cache).SetTTL
+	_ func(*pricing.Cache, string) (pricing.CacheEntry, bool)                               = (*pricing.Cache).Entry
+	_ func(*pricing.Cache) ([]pricing.CacheEntry, error)                                    = (*pricing.Cache).Entries
+	_ func(*pricing.Cache, string) error                                                    = (*pricing.Cache).Purge
+	_ func(*pricing.Cache) error                                                            = (*pricing.Cache).PurgeAll
+	_ func(pricing.Rates, calculator.Capability) (float64, float64)                         = pricing.Rates.ForCapability
+	_ func(pricing.Rates) bool                                                              = pricing.Rates.HasAllCapabilityRates
+	_ func(pricing.Rates) bool                                                              = pricing.Rates.HasAncillaryRates
+	_ pricing.Provider                                                                      = pricing.ProviderFunc(nil)
+	_ func(pricing.ProviderFunc, context.Context, string) (pricing.Rates, error)            = pricing.ProviderFunc.Rates
+	_ func() []pricing.Field                                                                = pricing.Fields
+	_ func(pricing.Rates, pricing.Field) float64                                            = pricing.Rates.Get
+	_ func(*pricing.Rates, pricing.Field, float64)                                          = (*pricing.Rates).Set
+	_ func(string, func(context.Context, string) (pricing.RateSheet, error)) pricing.Source = pricing.NewSource
+	_ func(...pricing.Source) *pricing.Chain                                                = pricing.NewChain
+	_ func() *pricing.Chain                                                                 = pricing.DefaultChain
+	_ func([]string, pricing.ChainConfig) (*pricing.Chain, error)                           = pricing.BuildChain
+	_ func(*pricing.Chain, context.Context, string) (pricing.Resolved, error)               = (*pricing.Chain).Resolve
+	_ func(*pricing.Chain) []string                                                         = (*pricing.Chain).Names
+	_ func(pricing.Resolved) []pricing.Field                                                = pricing.Resolved.Missing
+	_ func(*pricing.Cache) pricing.Source                                                   = pricing.CacheSource
+	_ func(pricing.PricingAPI, *pricing.Cache) pricing.Source                               = pricing.APISource
+	_ func() pricing.Source                                                                 = pricing.DefaultsSource
+	_ pricing.Provider                                                                      = (*pricing.Chain)(nil)
+	_ pricing.Source                                                                        = (*pricing.Offers)(nil)
+	_ func(string) (*pricing.Overrides, error)                                              = pricing.LoadOverrides
+	_ func(io.Reader) (*pricing.Overrides, error)                                           = pricing.ParseOverrides
+	_ func(*pricing.Overrides, context.Context, string) (pricing.RateSheet, error)          = (*pricing.Overrides).Lookup
+	_ pricing.Source                                                                        = (*pricing.Overrides)(nil)
+	_ func(*pricing.Cache, string) ([]pricing.Snapshot, error)                              = (*pricing.Cache).History
+	_ func([]pricing.Snapshot, time.Time) (pricing.Snapshot, bool)                          = pricing.SnapshotAt
+	_ func(pricing.Rates, pricing.Rates) []pricing.RateChange                               = pricing.CompareRates
+	_ func([]pricing.Snapshot) []pricing.RateChange                                         = pricing.Changes
+	_ func(pricing.RateChange) float64                                                      = pricing.RateChange.Percent
+	_ func(*pricing.Cache, time.Time) pricing.Source                                        = pricing.HistorySource
+	_ func(pricing.PricingAPI) pricing.PricingAPI                                           = pricing.NewRetryingClient
+	_ func(error) pricing.ErrorKind                                                         = pricing.Classify
+	_ func(pricing.ErrorKind) string                                                        = pricing.ErrorKind.String
+	_ func(pricing.Resolved) error                                                          = pricing.Resolved.FallbackErr
+	_ error                                                                                 = (*pricing.SourceError)(nil)
+	_ func(pricing.Schedule) float64                                                        = pricing.Schedule.Rate
+	_ func(pricing.Schedule) []calculator.RateTier                                          = pricing.Schedule.RateTiers
+	_ func() []pricing.Region                                                               = pricing.BuiltinRegions
+	_ func(string) string                                                                   = pricing.RegionName
+	_ func(context.Context, pricing.PricingAPI) ([]pricing.Region, error)                   = pricing.DiscoverRegions
+	_ func(context.Context, pricing.PricingAPI, *pricing.Cache) ([]pricing.Region, error)   = pricing.Regions
+	_ func(*pricing.Cache) []pricing.Region                                                 = (*pricing.Cache).LoadRegions
+	_ func(*pricing.Cache, []pricing.Region) error                                          = (*pricing.Cache).SaveRegions
+	_ func(pricing.RateSheet) []pricing.Anomaly                                             = pricing.CheckRates
+	_ func(pricing.Anomaly) string                                                          = pricing.Anomaly.String
+	_ func(pricing.AnomalyKind) string                                                      = pricing.AnomalyKind.String
+	_ func(*pricing.Chain, bool)                                                            = (*pricing.Chain).SetRejectSuspect
+	_ func(*pricing.Chain) bool                                                             = (*pricing.Chain).RejectSuspect
+	_ func(pricing.PricingAPI, string) *pricing.Recorder                                    = pricing.NewRecorder
+	_ func(string) *pricing.Replayer                                                        = pricing.NewReplayer
+	_ pricing.PricingAPI                                                                    = (*pricing.Recorder)(nil)
+	_ pricing.PricingAPI                                                                    = (*pricing.Replayer)(nil)
+	_ func(pricing.RateSheet, pricing.RateSheet) bool                                       = pricing.RateSheet.Equal
+)
+
+func TestAPIChainTypes(t *testing.T) {
+	_ = pricing.ChainConfig{Cache: nil, Client: nil, OfferFiles: nil, OverridesFile: "", RejectSuspect: false}
+	_ = pricing.Resolved{Region: "", Rates: pricing.RateSheet{}, Errors: nil, Anomalies: nil}
+	_ = pricing.Provenance{Source: pricing.SourceCache, Region: "", UsageType: "", Unit: "", FetchedAt: time.Time{}}
+	_ = pricing.Anomaly{Field: "", Kind: pricing.AnomalyZero, Value: 0, Default: 0, Unit: "", Source: "", Rejected: false}
+	_ = []pricing.AnomalyKind{pricing.AnomalyZero, pricing.AnomalyUnit, pricing.AnomalyHigh, pricing.AnomalyLow}
+	_ = []string{pricing.SourceOverrides, pricing.SourceAPI, pricing.SourceOffers, pricing.SourceDefaults}
+	_ = pricing.OverridesFile
+	_ = pricing.SourceHistory
+	_ = pricing.Region{Code: "", Name: ""}
+	_ = pricing.ErrNoRegions
+	_ = pricing.ErrNoFixture
+	_ = pricing.Snapshot{Rates: pricing.RateSheet{}, FetchedAt: time.Time{}}
+	_ = pricing.RateChange{Field: pricing.FieldArgoCDBasePerHour, From: 0, To: 0, At: time.Time{}}
+	_ = pricing.DefaultSourceOrder
+	_ = pricing.ErrIncomplete
+	_ = pricing.CacheEntry{Region: "", Rates: pricing.RateSheet{}, FetchedAt: time.Time{}, Expired: false}
+	_ = pricing.DefaultCacheTTL
+	_ = pricing.SourceError{Source: "", Err: nil}
+	_ = []pricing.ErrorKind{pricing.ErrorOther, pricing.ErrorAuth, pricing.ErrorThrottled, pricing.ErrorNetwork, pricing.ErrorNotFound}
+}
+
+func TestAPIRatesFields(t *testing.T) {
+	_ = pricing.Rates{
+		ArgoCDBasePerHour:   0,
+		ArgoCDAppPerHour:    0,
+		ACKBasePerHour:      0,
+		ACKResourcePerHour:  0,
+		KroBasePerHour:      0,
+		KroRGDPerHour:       0,
+		FargateVCPUPerHour:  0,
+		FargateMemGBPerHour: 0,
+		ALBPerHour:          0,
+		ALBLCUPerHour:       0,
+		EBSGBMonth:          0,
+		CloudWatchLogsPerGB: 0,
+	}
+	_ = pricing.RateSheet{
+		Rates:      pricing.Rates{},
+		Tiers:      map[pricing.Field]pricing.Schedule{},
+		Provenance: map[pricing.Field]pricing.Provenance{},
+	}
+	_ = pricing.Tier{BeginRange: 0, EndRange: 0, Rate: 0, Unit: "", Description: ""}
+}
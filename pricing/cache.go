@@ -0,0 +1,190 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached rates remain valid unless the cache is
+// given another TTL with SetTTL.
+const DefaultCacheTTL = 24 * time.Hour
+
+const cacheSubdir = "aws-eks-calculator"
+
+// cacheVersion is the version of the rate cache entry format. Entries of
+// any other version, including those written before entries were
+// versioned, are ignored and refetched rather than migrated: a refetch
+// costs one API call, while a wrong rate costs a wrong estimate. Bump it
+// whenever older entries would be read wrongly, e.g. when a Rates field is
+// added. Version 2 added the currency: without one, version 1 entries for
+// AWS China regions would be read as USD.
+const cacheVersion = 2
+
+// cacheLockFile serializes writers across calculator processes.
+const cacheLockFile = ".lock"
+
+var cacheJSONMarshal = json.Marshal
+
+// userCacheDir is os.UserCacheDir; swapped out in tests.
+var userCacheDir = os.UserCacheDir
+
+// cachedRates is the on-disk format for cached pricing data.
+type cachedRates struct {
+	Version   int       `json:"version"`
+	Rates     RateSheet `json:"rates"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Cache handles reading and writing pricing rates to files in a directory.
+// Files are replaced atomically, so readers never see a partial write, and
+// writers hold a lock on the directory, so concurrent processes can share
+// it.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewCache creates a cache under the user cache directory: $XDG_CACHE_HOME
+// or ~/.cache on Linux, ~/Library/Caches on macOS. If there is none, it
+// falls back to os.TempDir().
+func NewCache() *Cache {
+	dir, err := userCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return NewCacheDir(filepath.Join(dir, cacheSubdir))
+}
+
+// NewCacheDir creates a cache that stores files in dir.
+func NewCacheDir(dir string) *Cache {
+	return &Cache{
+		dir: dir,
+		now: time.Now,
+	}
+}
+
+func (c *Cache) path(region string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("rates-%s.json", region))
+}
+
+// Load returns cached rates for the given region if a valid (non-expired)
+// cache file exists. Returns nil if the cache is missing, expired, or corrupt.
+func (c *Cache) Load(region string) *Rates {
+	entry := c.load(region)
+	if entry == nil {
+		return nil
+	}
+	return &entry.Rates.Rates
+}
+
+// SetTTL sets how long entries remain valid. Zero or less restores
+// DefaultCacheTTL.
+func (c *Cache) SetTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+// expired reports whether data fetched at fetchedAt has outlived the TTL.
+func (c *Cache) expired(fetchedAt time.Time) bool {
+	ttl := c.ttl
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return c.now().Sub(fetchedAt) > ttl
+}
+
+// load returns the valid cache entry for region, or nil.
+func (c *Cache) load(region string) *cachedRates {
+	entry := c.read(region)
+	if entry == nil || c.expired(entry.FetchedAt) {
+		return nil
+	}
+	return entry
+}
+
+// read returns the cache entry for region whatever its age, or nil if it
+// is missing, corrupt or in another format version.
+func (c *Cache) read(region string) *cachedRates {
+	data, err := os.ReadFile(c.path(region))
+	if err != nil {
+		return nil
+	}
+
+	var entry cachedRates
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != cacheVersion {
+		return nil
+	}
+	return &entry
+}
+
+// Save writes rates to the cache file for the given region and, if they
+// differ from the last recorded snapshot, appends them to the region's
+// history. Errors are returned but callers may choose to ignore them.
+func (c *Cache) Save(region string, rates Rates) error {
+	return c.SaveSheet(region, RateSheet{Rates: rates})
+}
+
+// SaveSheet is Save for rates with their provenance and tiers, which are
+// kept in the cache and the history.
+func (c *Cache) SaveSheet(region string, sheet RateSheet) error {
+	entry := cachedRates{
+		Version:   cacheVersion,
+		Rates:     sheet,
+		FetchedAt: c.now(),
+	}
+
+	data, err := cacheJSONMarshal(entry)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := c.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := writeFileAtomic(c.path(region), data); err != nil {
+		return err
+	}
+	return c.appendHistory(region, Snapshot{Rates: entry.Rates, FetchedAt: entry.FetchedAt})
+}
+
+// lock creates the cache directory and takes an exclusive lock on it,
+// waiting for other writers. The returned func releases the lock.
+func (c *Cache) lock() (func(), error) {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(c.dir, cacheLockFile), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		return nil, errors.Join(fmt.Errorf("locking cache: %w", err), f.Close())
+	}
+	// Closing the file releases the lock.
+	return func() { _ = f.Close() }, nil
+}
+
+// writeFileAtomic replaces path with data by writing a temporary file in
+// the same directory and renaming it over path.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	err = errors.Join(err, f.Sync(), f.Close())
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
@@ -1,9 +1,12 @@
 package pricing
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 )
@@ -16,6 +19,32 @@ func newTestCache(t *testing.T) *Cache {
 	}
 }
 
+// withUserCacheDir points NewCache at a temporary user cache directory and
+// returns it.
+func withUserCacheDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	orig := userCacheDir
+	t.Cleanup(func() { userCacheDir = orig })
+	userCacheDir = func() (string, error) { return dir, nil }
+	return dir
+}
+
+// writeCacheEntry writes a fresh entry in the given format version.
+func writeCacheEntry(t *testing.T, c *Cache, region string, version int, rates Rates) {
+	t.Helper()
+	data, err := json.Marshal(cachedRates{Version: version, Rates: RateSheet{Rates: rates}, FetchedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.path(region), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestCacheSaveAndLoad(t *testing.T) {
 	c := newTestCache(t)
 	rates := Rates{
@@ -167,16 +196,159 @@ func TestCacheLoadRejectsZeroRates(t *testing.T) {
 }
 
 func TestNewCache(t *testing.T) {
+	dir := withUserCacheDir(t)
 	c := NewCache()
 	if c == nil {
 		t.Fatal("NewCache returned nil")
 	}
-	if c.dir == "" {
-		t.Error("NewCache dir should not be empty")
+	if want := filepath.Join(dir, cacheSubdir); c.dir != want {
+		t.Errorf("dir: got %q, want %q", c.dir, want)
 	}
 	if c.now == nil {
 		t.Error("NewCache now func should not be nil")
 	}
+
+	userCacheDir = func() (string, error) { return "", errors.New("no home") }
+	if want := filepath.Join(os.TempDir(), cacheSubdir); NewCache().dir != want {
+		t.Errorf("expected the temp dir without a user cache dir, got %q", NewCache().dir)
+	}
+}
+
+func TestCacheLoadIgnoresOtherVersions(t *testing.T) {
+	c := newTestCache(t)
+	// Version 1 entries predate the currency field.
+	for _, version := range []int{0, 1, cacheVersion + 1} {
+		writeCacheEntry(t, c, "us-east-1", version, DefaultRates())
+		if loaded := c.Load("us-east-1"); loaded != nil {
+			t.Errorf("expected version %d entries ignored", version)
+		}
+	}
+
+	writeCacheEntry(t, c, "us-east-1", cacheVersion, DefaultRates())
+	if loaded := c.Load("us-east-1"); loaded == nil {
+		t.Error("expected the current version loaded")
+	}
+}
+
+func TestCacheSaveWritesVersion(t *testing.T) {
+	c := newTestCache(t)
+	if err := c.Save("us-east-1", DefaultRates()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(c.path("us-east-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry cachedRates
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != cacheVersion {
+		t.Errorf("expected version %d, got %d (%v)", cacheVersion, entry.Version, err)
+	}
+
+	// Only the entry, its history and the lock file remain.
+	files, _ := os.ReadDir(c.dir)
+	if len(files) != 3 {
+		t.Errorf("expected no temporary files left behind, got %v", files)
+	}
+}
+
+func TestCacheSaveWaitsForLock(t *testing.T) {
+	c := newTestCache(t)
+	unlock, err := c.lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := make(chan error)
+	go func() { saved <- c.Save("us-east-1", DefaultRates()) }()
+
+	select {
+	case <-saved:
+		t.Fatal("Save should wait for the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	if err := <-saved; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCacheConcurrentSaves(t *testing.T) {
+	c := newTestCache(t)
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			rates := DefaultRates()
+			rates.ArgoCDBasePerHour = 0.01 * float64(i+1)
+			if err := c.Save("us-east-1", rates); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if c.Load("us-east-1") == nil {
+		t.Error("expected a valid entry after concurrent saves")
+	}
+	if history, _ := c.History("us-east-1"); len(history) != 10 {
+		t.Errorf("expected every save recorded once, got %d snapshots", len(history))
+	}
+}
+
+func TestCacheLockErrors(t *testing.T) {
+	c := newTestCache(t)
+	if err := os.Mkdir(filepath.Join(c.dir, cacheLockFile), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save("us-east-1", DefaultRates()); err == nil {
+		t.Error("expected an error opening the lock file")
+	}
+
+	orig := lockFile
+	defer func() { lockFile = orig }()
+	lockFile = func(*os.File) error { return errors.New("busy") }
+
+	c = newTestCache(t)
+	if err := c.Save("us-east-1", DefaultRates()); err == nil || err.Error() != "locking cache: busy" {
+		t.Errorf("expected the lock error, got %v", err)
+	}
+	if c.Load("us-east-1") != nil {
+		t.Error("nothing should be written without the lock")
+	}
+}
+
+func TestWriteFileAtomicErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeFileAtomic(filepath.Join(dir, "missing", "f.json"), nil); err == nil {
+		t.Error("expected an error in a missing directory")
+	}
+
+	// Renaming over a non-empty directory fails; the temporary file is
+	// removed.
+	target := filepath.Join(dir, "target")
+	if err := os.MkdirAll(filepath.Join(target, "child"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(target, []byte("{}")); err == nil {
+		t.Error("expected an error renaming over a directory")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("expected the temporary file removed, got %v", files)
+	}
+}
+
+func TestNewCacheDir(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCacheDir(dir)
+	if c.dir != dir {
+		t.Errorf("dir: got %q, want %q", c.dir, dir)
+	}
+	if err := c.Save("us-east-1", DefaultRates()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "rates-us-east-1.json")); err != nil {
+		t.Errorf("expected cache file in dir: %v", err)
+	}
 }
 
 func TestCacheSaveMkdirError(t *testing.T) {
@@ -232,3 +404,16 @@ func TestCacheSaveJsonMarshalError(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestCacheSaveWriteError(t *testing.T) {
+	c := newTestCache(t)
+	if err := os.Mkdir(c.path("us-east-1"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save("us-east-1", DefaultRates()); err == nil {
+		t.Error("expected an error writing over a directory")
+	}
+	if history, _ := c.History("us-east-1"); len(history) != 0 {
+		t.Errorf("a failed save should not be recorded, got %d snapshots", len(history))
+	}
+}
@@ -0,0 +1,406 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Names of the built-in sources, as accepted by BuildChain.
+const (
+	SourceOverrides = "overrides"
+	SourceCache     = "cache"
+	SourceAPI       = "api"
+	SourceOffers    = "offers"
+	SourceDefaults  = "defaults"
+
+	// SourceHistory names HistorySource. It is not accepted by BuildChain.
+	SourceHistory = "history"
+)
+
+// DefaultSourceOrder is the chain BuildChain uses when none is configured.
+// Overrides come first so that they apply on top of fetched rates.
+var DefaultSourceOrder = []string{SourceOverrides, SourceCache, SourceAPI, SourceDefaults}
+
+// ErrIncomplete is returned by Chain.Resolve when no source supplied one or
+// more fields.
+var ErrIncomplete = errors.New("no source supplied rates")
+
+// Source is one link in a Chain. Lookup returns only the rates the source
+// actually knows for the region, leaving the others zero; a source with
+// nothing to offer returns the zero RateSheet. Provenance is optional: the
+// chain fills in the source name and region when they are missing. A
+// source may return rates alongside an error.
+type Source interface {
+	Name() string
+	Lookup(ctx context.Context, region string) (RateSheet, error)
+}
+
+type funcSource struct {
+	name   string
+	lookup func(ctx context.Context, region string) (RateSheet, error)
+}
+
+func (s funcSource) Name() string { return s.name }
+
+func (s funcSource) Lookup(ctx context.Context, region string) (RateSheet, error) {
+	return s.lookup(ctx, region)
+}
+
+// NewSource returns a Source with the given name backed by lookup.
+func NewSource(name string, lookup func(ctx context.Context, region string) (RateSheet, error)) Source {
+	return funcSource{name: name, lookup: lookup}
+}
+
+// Resolved is the outcome of resolving a region through a Chain.
+type Resolved struct {
+	Region string
+
+	// Rates holds the resolved rates. Its Provenance has an entry for
+	// every field a source supplied; the others are zero.
+	Rates RateSheet
+
+	// Errors holds the errors returned by individual sources, as
+	// *SourceError, including those of sources later sources covered for.
+	Errors []error
+
+	// Anomalies holds the suspect rates sources supplied (see CheckRates),
+	// in the order they were met: those in Rates and, if the chain rejects
+	// suspect rates, those it passed over.
+	Anomalies []Anomaly
+}
+
+// SourceError is the error a source in a Chain returned.
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+func (e *SourceError) Error() string { return e.Source + ": " + e.Err.Error() }
+
+// Unwrap returns the source's error.
+func (e *SourceError) Unwrap() error { return e.Err }
+
+// FallbackErr returns the api source's error if any field had to be resolved
+// from the built-in defaults, and nil otherwise: a failed fetch matters only
+// when nothing else covered for it. Pass it to Classify to tell why.
+func (r Resolved) FallbackErr() error {
+	fellBack := false
+	for _, p := range r.Rates.Provenance {
+		fellBack = fellBack || p.Source == SourceDefaults
+	}
+	if !fellBack {
+		return nil
+	}
+	for _, err := range r.Errors {
+		var se *SourceError
+		if errors.As(err, &se) && se.Source == SourceAPI {
+			return se.Err
+		}
+	}
+	return nil
+}
+
+// Missing returns the fields no source supplied, in declaration order.
+func (r Resolved) Missing() []Field {
+	var missing []Field
+	for _, f := range Fields() {
+		if _, ok := r.Rates.Provenance[f]; !ok {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// Chain resolves rates by asking each source in order. Precedence is per
+// field: every field takes its value from the first source that supplies
+// it, so a bulk offer file covering only EKS can sit in front of the API
+// without hiding the API's Fargate rates. Sources after the point where
+// every field is resolved are not queried. Chain implements Provider.
+//
+// Suspect rates, as found by CheckRates, are accepted and reported in
+// Resolved.Anomalies unless SetRejectSuspect says otherwise.
+type Chain struct {
+	sources       []Source
+	rejectSuspect atomic.Bool
+}
+
+// NewChain returns a chain over sources, highest precedence first.
+func NewChain(sources ...Source) *Chain {
+	return &Chain{sources: sources}
+}
+
+// DefaultChain returns the local cache, then the AWS Pricing API using the
+// default credential chain (caching what it fetches), then DefaultRates.
+// This is DefaultSourceOrder without overrides, which need a file.
+func DefaultChain() *Chain {
+	cache := NewCache()
+	return NewChain(CacheSource(cache), APISource(nil, cache), DefaultsSource())
+}
+
+// SetRejectSuspect sets whether fields with a suspect rate are taken from
+// a later source instead, typically the built-in defaults. It is safe to
+// call while rates are being resolved.
+func (c *Chain) SetRejectSuspect(reject bool) {
+	c.rejectSuspect.Store(reject)
+}
+
+// RejectSuspect reports whether the chain rejects suspect rates.
+func (c *Chain) RejectSuspect() bool {
+	return c.rejectSuspect.Load()
+}
+
+// Names returns the names of the chain's sources in precedence order.
+func (c *Chain) Names() []string {
+	names := make([]string, len(c.sources))
+	for i, s := range c.sources {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// Resolve asks each source in turn until every field is resolved. The error
+// is non-nil only if some field remains unresolved; it wraps ErrIncomplete
+// and every source error.
+//
+// Rates are never mixed across currencies. The region resolves in the
+// currency of its Partition, skipping rates sources supply in another; only
+// if no source supplies any rate in it, as when AWS China prices cannot be
+// fetched, does it resolve in USD, from the built-in defaults for example.
+func (c *Chain) Resolve(ctx context.Context, region string) (Resolved, error) {
+	res := Resolved{Region: region}
+	var lookups []RateSheet
+	lookup := func(i int) RateSheet {
+		for len(lookups) <= i {
+			s := c.sources[len(lookups)]
+			found, err := s.Lookup(ctx, region)
+			if err != nil {
+				res.Errors = append(res.Errors, &SourceError{Source: s.Name(), Err: err})
+			}
+			lookups = append(lookups, found)
+		}
+		return lookups[i]
+	}
+
+	reject := c.RejectSuspect()
+	res.Rates, res.Anomalies = c.merge(region, PartitionOf(region).Currency, reject, lookup)
+	if len(res.Rates.Provenance) == 0 && res.Rates.CurrencyCode() != "USD" {
+		res.Rates, res.Anomalies = c.merge(region, "USD", reject, lookup)
+	}
+
+	if missing := res.Missing(); len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, f := range missing {
+			names[i] = string(f)
+		}
+		incomplete := fmt.Errorf("%w: %s", ErrIncomplete, strings.Join(names, ", "))
+		return res, errors.Join(append([]error{incomplete}, res.Errors...)...)
+	}
+	return res, nil
+}
+
+// merge takes every field from the first source, looked up with lookup,
+// that supplies it in currency, passing over suspect rates if reject is
+// set. It returns the anomalies among the rates it met.
+func (c *Chain) merge(region, currency string, reject bool, lookup func(i int) RateSheet) (RateSheet, []Anomaly) {
+	rates := RateSheet{Provenance: make(map[Field]Provenance)}
+	var anomalies []Anomaly
+	if currency != "USD" {
+		rates.Currency = currency
+	}
+	fields := Fields()
+
+	for i, s := range c.sources {
+		if len(rates.Provenance) == len(fields) {
+			break
+		}
+
+		found := lookup(i)
+		if found.CurrencyCode() != currency {
+			continue
+		}
+		for _, f := range fields {
+			if _, done := rates.Provenance[f]; done {
+				continue
+			}
+			v := found.Get(f)
+			if v <= 0 {
+				continue
+			}
+			p := found.Provenance[f]
+			if p.Source == "" {
+				p.Source = s.Name()
+			}
+			if p.Region == "" {
+				p.Region = region
+			}
+			if a, suspect := checkRate(f, v, p, currency); suspect {
+				a.Rejected = reject
+				anomalies = append(anomalies, a)
+				if reject {
+					continue
+				}
+			}
+			rates.setTiered(f, v, found.Tiers[f], p)
+		}
+	}
+	return rates, anomalies
+}
+
+// Rates resolves the region and returns the rates alone.
+func (c *Chain) Rates(ctx context.Context, region string) (Rates, error) {
+	res, err := c.Resolve(ctx, region)
+	return res.Rates.Rates, err
+}
+
+// CacheSource returns a source that answers from c. Entries written in an
+// older cache format are ignored, so that they are refetched rather than
+// mixed with newer rates.
+//
+// Cached rates are attributed to the cache source, keeping the usage type
+// and fetch time recorded when they were saved. Rates that were cached as
+// built-in defaults keep the defaults source.
+func CacheSource(c *Cache) Source {
+	return cacheSource(c, false)
+}
+
+// cacheSource returns CacheSource, or with anyAge one that also answers
+// from expired entries.
+func cacheSource(c *Cache, anyAge bool) Source {
+	return NewSource(SourceCache, func(_ context.Context, region string) (RateSheet, error) {
+		entry := c.read(region)
+		if entry == nil || (!anyAge && c.expired(entry.FetchedAt)) {
+			return RateSheet{}, nil
+		}
+		return servedFromCache(entry.Rates, entry.FetchedAt), nil
+	})
+}
+
+// APISource returns a source that queries the AWS Pricing API. A nil client
+// is created on each lookup from the default credential chain. When cache
+// is non-nil, successful lookups are saved to it, completed with
+// DefaultRates for any field the API had no product for in regions priced
+// in USD.
+func APISource(client PricingAPI, cache *Cache) Source {
+	return NewSource(SourceAPI, func(ctx context.Context, region string) (RateSheet, error) {
+		c := client
+		if c == nil {
+			c = NewClient(Credentials{})
+		}
+
+		fetched, err := fetchOnto(ctx, c, region, RateSheet{})
+		if err != nil {
+			return RateSheet{}, err
+		}
+
+		if cache != nil {
+			rates := baseRatesFor(region)
+			for f, p := range fetched.Provenance {
+				rates.setTiered(f, fetched.Get(f), fetched.Tiers[f], p)
+			}
+			_ = cache.SaveSheet(region, rates)
+		}
+		return fetched, nil
+	})
+}
+
+// DefaultsSource returns a source that answers every region with
+// DefaultRates.
+func DefaultsSource() Source {
+	return NewSource(SourceDefaults, func(_ context.Context, region string) (RateSheet, error) {
+		return defaultRatesFor(region), nil
+	})
+}
+
+// offerFilesSource returns a source backed by the offer files at paths.
+// Offer files can be large, so they are read on the first lookup rather
+// than when the chain is built.
+func offerFilesSource(paths []string) Source {
+	var (
+		once    sync.Once
+		offers  *Offers
+		loadErr error
+	)
+	return NewSource(SourceOffers, func(ctx context.Context, region string) (RateSheet, error) {
+		once.Do(func() { offers, loadErr = LoadOffers(paths...) })
+		if loadErr != nil {
+			return RateSheet{}, loadErr
+		}
+		return offers.Lookup(ctx, region)
+	})
+}
+
+// ChainConfig supplies what BuildChain's sources need.
+type ChainConfig struct {
+	// Cache backs the cache source and receives API results. Nil uses
+	// NewCache.
+	Cache *Cache
+
+	// Client is used by the api source. Nil uses the default credential
+	// chain.
+	Client PricingAPI
+
+	// OfferFiles are the bulk offer files read by the offers source.
+	OfferFiles []string
+
+	// OverridesFile is read by the overrides source. Empty means no
+	// overrides.
+	OverridesFile string
+
+	// Offline leaves out the api source, so that the chain never calls
+	// AWS, and lets the cache source answer from expired entries, which
+	// beat the defaults when nothing can be fetched.
+	Offline bool
+
+	// RejectSuspect makes the chain reject suspect rates; see
+	// Chain.SetRejectSuspect.
+	RejectSuspect bool
+}
+
+// BuildChain returns a chain of the named built-in sources in the given
+// order. An empty list uses DefaultSourceOrder. Unknown or repeated names,
+// and "offers" without any OfferFiles, are errors. With cfg.Offline, "api"
+// is accepted but left out.
+func BuildChain(names []string, cfg ChainConfig) (*Chain, error) {
+	if len(names) == 0 {
+		names = DefaultSourceOrder
+	}
+	if cfg.Cache == nil {
+		cfg.Cache = NewCache()
+	}
+
+	seen := make(map[string]bool)
+	sources := make([]Source, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			return nil, fmt.Errorf("rate source %q listed twice", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case SourceOverrides:
+			sources = append(sources, overridesFileSource(cfg.OverridesFile))
+		case SourceCache:
+			sources = append(sources, cacheSource(cfg.Cache, cfg.Offline))
+		case SourceAPI:
+			if !cfg.Offline {
+				sources = append(sources, APISource(cfg.Client, cfg.Cache))
+			}
+		case SourceOffers:
+			if len(cfg.OfferFiles) == 0 {
+				return nil, fmt.Errorf("rate source %q needs at least one offer file", name)
+			}
+			sources = append(sources, offerFilesSource(cfg.OfferFiles))
+		case SourceDefaults:
+			sources = append(sources, DefaultsSource())
+		default:
+			return nil, fmt.Errorf("unknown rate source %q", name)
+		}
+	}
+	chain := NewChain(sources...)
+	chain.SetRejectSuspect(cfg.RejectSuspect)
+	return chain, nil
+}
@@ -0,0 +1,403 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+func fixedSource(name string, r Rates, err error) Source {
+	return NewSource(name, func(context.Context, string) (RateSheet, error) {
+		return RateSheet{Rates: r}, err
+	})
+}
+
+func TestChainPrecedenceIsPerField(t *testing.T) {
+	chain := NewChain(
+		fixedSource("overrides", Rates{ALBPerHour: 0.5}, nil),
+		fixedSource("offers", Rates{ALBPerHour: 0.1, ArgoCDBasePerHour: 0.04}, nil),
+		DefaultsSource(),
+	)
+
+	res, err := chain.Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Region != "us-east-1" {
+		t.Errorf("expected region recorded, got %q", res.Region)
+	}
+	if res.Rates.ALBPerHour != 0.5 || res.Rates.Provenance[FieldALBPerHour].Source != "overrides" {
+		t.Errorf("ALB should come from the first source, got %v from %q", res.Rates.ALBPerHour, res.Rates.Provenance[FieldALBPerHour].Source)
+	}
+	if res.Rates.ArgoCDBasePerHour != 0.04 || res.Rates.Provenance[FieldArgoCDBasePerHour].Source != "offers" {
+		t.Errorf("ArgoCD base should come from offers, got %v from %q", res.Rates.ArgoCDBasePerHour, res.Rates.Provenance[FieldArgoCDBasePerHour].Source)
+	}
+	if res.Rates.EBSGBMonth != DefaultRates().EBSGBMonth || res.Rates.Provenance[FieldEBSGBMonth].Source != SourceDefaults {
+		t.Errorf("unsupplied fields should fall through to defaults, got %+v", res.Rates.Provenance[FieldEBSGBMonth])
+	}
+	if res.Rates.Provenance[FieldALBPerHour].Region != "us-east-1" {
+		t.Errorf("the chain should fill in the region, got %+v", res.Rates.Provenance[FieldALBPerHour])
+	}
+	if len(res.Missing()) != 0 {
+		t.Errorf("expected no missing fields, got %v", res.Missing())
+	}
+}
+
+func TestChainStopsOnceComplete(t *testing.T) {
+	called := false
+	chain := NewChain(DefaultsSource(), NewSource("later", func(context.Context, string) (RateSheet, error) {
+		called = true
+		return RateSheet{}, nil
+	}))
+
+	if _, err := chain.Rates(context.Background(), "us-east-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Error("sources after a complete resolution should not be queried")
+	}
+}
+
+func TestChainRecordsSourceErrors(t *testing.T) {
+	boom := errors.New("boom")
+	chain := NewChain(
+		fixedSource("flaky", Rates{ALBPerHour: 0.5}, boom),
+		DefaultsSource(),
+	)
+
+	res, err := chain.Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("a complete resolution should not fail, got %v", err)
+	}
+	if res.Rates.ALBPerHour != 0.5 {
+		t.Error("rates returned alongside an error should still be used")
+	}
+	if len(res.Errors) != 1 || !errors.Is(res.Errors[0], boom) || !strings.HasPrefix(res.Errors[0].Error(), "flaky: ") {
+		t.Errorf("expected the source error prefixed with its name, got %v", res.Errors)
+	}
+}
+
+func TestChainIncomplete(t *testing.T) {
+	boom := errors.New("boom")
+	chain := NewChain(fixedSource("partial", Rates{ALBPerHour: 0.5}, boom))
+
+	rates, err := chain.Rates(context.Background(), "us-east-1")
+	if !errors.Is(err, ErrIncomplete) || !errors.Is(err, boom) {
+		t.Fatalf("expected ErrIncomplete wrapping the source error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "CloudWatchLogsPerGB") {
+		t.Errorf("expected the missing fields to be named, got %v", err)
+	}
+	if rates.ALBPerHour != 0.5 || rates.ArgoCDBasePerHour != 0 {
+		t.Errorf("expected resolved fields set and missing ones zero, got %+v", rates)
+	}
+}
+
+func TestChainNames(t *testing.T) {
+	chain := NewChain(DefaultsSource(), fixedSource("x", Rates{}, nil))
+	if got := chain.Names(); len(got) != 2 || got[0] != SourceDefaults || got[1] != "x" {
+		t.Errorf("unexpected names %v", got)
+	}
+}
+
+func TestCacheSource(t *testing.T) {
+	c := newTestCache(t)
+	src := CacheSource(c)
+
+	if r, err := src.Lookup(context.Background(), "us-east-1"); err != nil || r.Rates != (Rates{}) {
+		t.Errorf("expected nothing for an empty cache, got %+v, %v", r, err)
+	}
+
+	rates := DefaultRates()
+	rates.ArgoCDBasePerHour = 0.42
+	_ = c.Save("us-east-1", rates)
+	r, _ := src.Lookup(context.Background(), "us-east-1")
+	if r.Rates != rates {
+		t.Errorf("expected every cached field, got %+v", r)
+	}
+	p := r.Provenance[FieldArgoCDBasePerHour]
+	if p.Source != SourceCache || p.FetchedAt.IsZero() {
+		t.Errorf("entries without provenance should be attributed to the cache with the entry's fetch time, got %+v", p)
+	}
+
+	rates.ALBPerHour = 0
+	_ = c.Save("us-east-1", rates)
+	r, _ = src.Lookup(context.Background(), "us-east-1")
+	if r.ArgoCDBasePerHour != 0.42 || r.ALBPerHour != 0 {
+		t.Errorf("expected the entry's rates alone, got %+v", r)
+	}
+	if _, ok := r.Provenance[FieldALBPerHour]; ok {
+		t.Error("fields missing from the entry should have no provenance")
+	}
+
+	writeCacheEntry(t, c, "us-east-1", cacheVersion-1, DefaultRates())
+	if r, _ := src.Lookup(context.Background(), "us-east-1"); r.Rates != (Rates{}) {
+		t.Errorf("entries in an older format should be ignored, got %+v", r)
+	}
+}
+
+func TestCacheSourceKeepsProvenance(t *testing.T) {
+	c := newTestCache(t)
+	fetchedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	rates := defaultRatesFor("us-east-1")
+	rates.setWithProvenance(FieldArgoCDBasePerHour, 0.03, Provenance{
+		Source: SourceAPI, Region: "us-east-1", UsageType: "USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability", FetchedAt: fetchedAt,
+	})
+	_ = c.SaveSheet("us-east-1", rates)
+
+	r, _ := CacheSource(c).Lookup(context.Background(), "us-east-1")
+	p := r.Provenance[FieldArgoCDBasePerHour]
+	if p.Source != SourceCache || !p.FetchedAt.Equal(fetchedAt) || p.UsageType != "USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability" {
+		t.Errorf("expected the saved usage type and fetch time under the cache source, got %+v", p)
+	}
+	if p := r.Provenance[FieldALBPerHour]; p.Source != SourceDefaults || !p.FetchedAt.IsZero() {
+		t.Errorf("cached defaults should stay attributed to defaults, got %+v", p)
+	}
+}
+
+func TestAPISourceCachesResult(t *testing.T) {
+	c := newTestCache(t)
+	mock := &mockPricingAPI{responses: allCapabilityProducts("us-east-1")}
+	src := APISource(mock, c)
+
+	r, err := src.Lookup(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.ArgoCDBasePerHour != 0.03 {
+		t.Errorf("expected fetched ArgoCD rate, got %v", r.ArgoCDBasePerHour)
+	}
+	p := r.Provenance[FieldArgoCDBasePerHour]
+	if p.Source != SourceAPI || p.Region != "us-east-1" || p.UsageType != "USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability" || p.FetchedAt.IsZero() {
+		t.Errorf("unexpected provenance %+v", p)
+	}
+	if _, ok := r.Provenance[FieldALBPerHour]; ok || r.ALBPerHour != 0 {
+		t.Error("fields the API has no product for should not be reported")
+	}
+
+	cached := c.load("us-east-1")
+	if cached == nil || cached.Rates.ArgoCDBasePerHour != 0.03 || cached.Rates.ALBPerHour != DefaultRates().ALBPerHour {
+		t.Fatalf("expected the fetched rates cached over defaults, got %+v", cached)
+	}
+	if cached.Rates.Provenance[FieldArgoCDBasePerHour].Source != SourceAPI || cached.Rates.Provenance[FieldALBPerHour].Source != SourceDefaults {
+		t.Errorf("expected provenance saved with the entry, got %+v", cached.Rates.Provenance)
+	}
+}
+
+func TestAPISourceError(t *testing.T) {
+	c := newTestCache(t)
+	src := APISource(&mockPricingAPI{err: fmt.Errorf("throttled")}, c)
+
+	if _, err := src.Lookup(context.Background(), "us-east-1"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if c.Load("us-east-1") != nil {
+		t.Error("failed lookups should not be cached")
+	}
+}
+
+func TestAPISourceDefaultClient(t *testing.T) {
+	origLoad, origNew := loadDefaultConfig, newPricingClient
+	defer func() { loadDefaultConfig, newPricingClient = origLoad, origNew }()
+
+	loadDefaultConfig = func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{}, fmt.Errorf("no creds")
+	}
+	if _, err := APISource(nil, nil).Lookup(context.Background(), "us-east-1"); err == nil || !strings.Contains(err.Error(), "no creds") {
+		t.Errorf("expected the config error, got %v", err)
+	}
+
+	loadDefaultConfig = func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{}, nil
+	}
+	newPricingClient = func(cfg aws.Config) PricingAPI {
+		return &mockPricingAPI{responses: allCapabilityProducts("us-east-1")}
+	}
+	r, err := APISource(nil, nil).Lookup(context.Background(), "us-east-1")
+	if err != nil || r.KroBasePerHour != 0.005 {
+		t.Errorf("expected rates from the default client, got %+v, %v", r, err)
+	}
+}
+
+func TestOffersLookup(t *testing.T) {
+	o, err := ParseOffers(strings.NewReader(eksOffer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Name() != SourceOffers {
+		t.Errorf("unexpected name %q", o.Name())
+	}
+
+	r, _ := o.Lookup(context.Background(), "us-east-1")
+	if len(r.Provenance) != 3 || r.ArgoCDBasePerHour != 0.031 || r.KroBasePerHour != 0 {
+		t.Errorf("expected only the three EKS rates in the file, got %+v", r)
+	}
+	p := r.Provenance[FieldArgoCDBasePerHour]
+	if p.Source != SourceOffers || p.Region != "us-east-1" || p.UsageType != "USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability" {
+		t.Errorf("unexpected provenance %+v", p)
+	}
+	r.Provenance[FieldArgoCDBasePerHour] = Provenance{}
+	if again, _ := o.Lookup(context.Background(), "us-east-1"); again.Provenance[FieldArgoCDBasePerHour].Source != SourceOffers {
+		t.Error("Lookup should return a copy")
+	}
+	if r, err := o.Lookup(context.Background(), "ap-south-2"); err != nil || r.Rates != (Rates{}) {
+		t.Errorf("expected nothing for an uncovered region, got %+v, %v", r, err)
+	}
+}
+
+func TestOffersInChainFallThrough(t *testing.T) {
+	o, _ := ParseOffers(strings.NewReader(eksOffer()))
+	res, err := NewChain(o, DefaultsSource()).Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rates.Provenance[FieldArgoCDBasePerHour].Source != SourceOffers || res.Rates.Provenance[FieldKroBasePerHour].Source != SourceDefaults {
+		t.Errorf("unexpected provenance %v", res.Rates.Provenance)
+	}
+}
+
+func TestBuildChain(t *testing.T) {
+	chain, err := BuildChain(nil, ChainConfig{Cache: newTestCache(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(chain.Names(), ","); got != "overrides,cache,api,defaults" {
+		t.Errorf("expected the default order, got %s", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "eks.json")
+	if err := os.WriteFile(path, []byte(eksOffer()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	chain, err = BuildChain([]string{"offers", "cache", "api", "defaults"}, ChainConfig{
+		Cache:      newTestCache(t),
+		Client:     &mockPricingAPI{responses: allCapabilityProducts("us-east-1")},
+		OfferFiles: []string{path},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := chain.Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rates.ArgoCDBasePerHour != 0.031 || res.Rates.Provenance[FieldArgoCDBasePerHour].Source != SourceOffers {
+		t.Errorf("offers should take precedence, got %+v", res.Rates.Provenance[FieldArgoCDBasePerHour])
+	}
+	if res.Rates.Provenance[FieldKroBasePerHour].Source != SourceAPI {
+		t.Errorf("fields missing from the offers should come from the API, got %+v", res.Rates.Provenance[FieldKroBasePerHour])
+	}
+	if res.Rates.Provenance[FieldALBPerHour].Source != SourceDefaults {
+		t.Errorf("fields no earlier source has should come from defaults, got %+v", res.Rates.Provenance[FieldALBPerHour])
+	}
+}
+
+func TestBuildChainOffline(t *testing.T) {
+	c := newTestCache(t)
+	saved := time.Now().Add(-48 * time.Hour)
+	c.now = func() time.Time { return saved }
+	rates := DefaultRates()
+	rates.ArgoCDBasePerHour = 0.04
+	_ = c.Save("eu-west-1", rates)
+	c.now = time.Now
+
+	client := &mockPricingAPI{err: errors.New("called AWS")}
+	chain, err := BuildChain([]string{"cache", "api", "defaults"}, ChainConfig{Cache: c, Client: client, Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(chain.Names(), ","); got != "cache,defaults" {
+		t.Errorf("expected the api source left out, got %s", got)
+	}
+
+	res, err := chain.Resolve(context.Background(), "eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rates.ArgoCDBasePerHour != 0.04 || res.Rates.Provenance[FieldArgoCDBasePerHour].Source != SourceCache {
+		t.Errorf("expected the expired entry used offline, got %+v", res.Rates.Provenance[FieldArgoCDBasePerHour])
+	}
+	if res, err := chain.Resolve(context.Background(), "us-east-1"); err != nil || len(res.Errors) != 0 {
+		t.Errorf("expected the defaults without calling AWS, got %v, %v", err, res.Errors)
+	}
+
+	online, _ := BuildChain([]string{"cache"}, ChainConfig{Cache: c})
+	if r, _ := online.Rates(context.Background(), "eu-west-1"); r.ArgoCDBasePerHour != 0 {
+		t.Error("expired entries should be ignored online")
+	}
+}
+
+func TestBuildChainOfferFileError(t *testing.T) {
+	chain, err := BuildChain([]string{"offers"}, ChainConfig{OfferFiles: []string{filepath.Join(t.TempDir(), "missing.json")}})
+	if err != nil {
+		t.Fatalf("offer files should be read lazily, got %v", err)
+	}
+	res, err := chain.Resolve(context.Background(), "us-east-1")
+	if !errors.Is(err, ErrIncomplete) || len(res.Errors) != 1 || !strings.HasPrefix(res.Errors[0].Error(), "offers: ") {
+		t.Errorf("expected the load error recorded, got %v (%v)", err, res.Errors)
+	}
+}
+
+func TestBuildChainErrors(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"cache", "bogus"}, `unknown rate source "bogus"`},
+		{[]string{"cache", "cache"}, `rate source "cache" listed twice`},
+		{[]string{"offers"}, `rate source "offers" needs at least one offer file`},
+	}
+	for _, tt := range tests {
+		if _, err := BuildChain(tt.names, ChainConfig{}); err == nil || err.Error() != tt.want {
+			t.Errorf("%v: expected %q, got %v", tt.names, tt.want, err)
+		}
+	}
+}
+
+func TestDefaultChain(t *testing.T) {
+	if got := strings.Join(DefaultChain().Names(), ","); got != "cache,api,defaults" {
+		t.Errorf("expected cache, api and defaults, got %s", got)
+	}
+	if _, ok := Default().(*Chain); !ok {
+		t.Error("Default should return the default chain")
+	}
+}
+
+func TestResolvedFallbackErr(t *testing.T) {
+	apiErr := apiError{"ThrottlingException"}
+	failing := NewSource(SourceAPI, func(context.Context, string) (RateSheet, error) { return RateSheet{}, apiErr })
+	broken := NewSource("finance", func(context.Context, string) (RateSheet, error) { return RateSheet{}, errors.New("bad file") })
+
+	res, err := NewChain(broken, failing, DefaultsSource()).Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got := res.FallbackErr(); !errors.Is(got, apiErr) || Classify(got) != ErrorThrottled {
+		t.Errorf("expected the api error, got %v", got)
+	}
+	var se *SourceError
+	if !errors.As(res.Errors[0], &se) || se.Source != "finance" || res.Errors[0].Error() != "finance: bad file" {
+		t.Errorf("expected source errors named after their source, got %v", res.Errors)
+	}
+
+	// Nothing fell back to defaults: the failed fetch did not matter.
+	static := NewSource("static", func(context.Context, string) (RateSheet, error) { return RateSheet{Rates: DefaultRates()}, nil })
+	res, _ = NewChain(failing, static).Resolve(context.Background(), "us-east-1")
+	if got := res.FallbackErr(); got != nil {
+		t.Errorf("expected no fallback error, got %v", got)
+	}
+
+	// Defaults without an api failure are not an error either.
+	res, _ = NewChain(broken, DefaultsSource()).Resolve(context.Background(), "us-east-1")
+	if got := res.FallbackErr(); got != nil {
+		t.Errorf("expected no fallback error without an api source, got %v", got)
+	}
+}
@@ -0,0 +1,353 @@
+package pricing
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// roleSessionName names the sessions created when assuming a role, so that
+// they can be told apart in CloudTrail.
+const roleSessionName = "aws-eks-calculator"
+
+// newSTSClient is a package-level var for testing.
+var newSTSClient = func(cfg aws.Config) stscreds.AssumeRoleAPIClient {
+	return sts.NewFromConfig(cfg)
+}
+
+// Credentials selects the AWS credentials Pricing API calls are made with.
+// The zero value uses the default credential chain.
+type Credentials struct {
+	// Profile names a profile in the shared config and credentials files.
+	// Empty uses AWS_PROFILE, or the default profile.
+	Profile string
+
+	// RoleARN is a role to assume with the profile's credentials. Empty
+	// uses the profile's credentials directly.
+	RoleARN string
+}
+
+// CredentialsError reports that the AWS credentials could not be loaded or
+// retrieved. RoleARN is set when assuming the role failed, and empty when
+// the profile's own credentials did. Classify reports it as ErrorAuth.
+type CredentialsError struct {
+	Profile string
+	RoleARN string
+	Err     error
+}
+
+func (e *CredentialsError) Error() string {
+	switch {
+	case e.RoleARN != "":
+		return fmt.Sprintf("assuming role %s: %v", e.RoleARN, e.Err)
+	case e.Profile != "":
+		return fmt.Sprintf("loading AWS credentials for profile %q: %v", e.Profile, e.Err)
+	default:
+		return fmt.Sprintf("loading AWS credentials: %v", e.Err)
+	}
+}
+
+// Unwrap returns the underlying error.
+func (e *CredentialsError) Unwrap() error { return e.Err }
+
+// checkedCredentials reports retrieval failures of provider as a
+// *CredentialsError, which the SDK would otherwise wrap in an untyped
+// error.
+type checkedCredentials struct {
+	provider aws.CredentialsProvider
+	profile  string
+	roleARN  string
+}
+
+func (c checkedCredentials) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	creds, err := c.provider.Retrieve(ctx)
+	if err == nil {
+		return creds, nil
+	}
+	// Assuming a role fails when the credentials it is assumed with do;
+	// report those rather than the role.
+	var credErr *CredentialsError
+	if errors.As(err, &credErr) {
+		return creds, credErr
+	}
+	return creds, &CredentialsError{Profile: c.profile, RoleARN: c.roleARN, Err: err}
+}
+
+// loadConfig loads the AWS configuration for the Pricing API endpoint in
+// region with creds.
+func loadConfig(ctx context.Context, creds Credentials, region string) (aws.Config, error) {
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if creds.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(creds.Profile))
+	}
+	cfg, err := loadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, &CredentialsError{Profile: creds.Profile, Err: err}
+	}
+
+	if cfg.Credentials != nil {
+		cfg.Credentials = checkedCredentials{provider: cfg.Credentials, profile: creds.Profile}
+	}
+	if creds.RoleARN != "" {
+		role := stscreds.NewAssumeRoleProvider(newSTSClient(cfg), creds.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = roleSessionName
+		})
+		cfg.Credentials = checkedCredentials{
+			provider: aws.NewCredentialsCache(role),
+			profile:  creds.Profile,
+			roleARN:  creds.RoleARN,
+		}
+	}
+	return cfg, nil
+}
+
+// Client calls the Pricing API with the selected Credentials, retrying
+// throttled and transient failures like NewRetryingClient. The AWS
+// configuration is loaded on first use, and again after SetCredentials, so
+// creating a Client never fails; a failure to load it is returned by the
+// call that needed it. Client is safe for concurrent use.
+//
+// Calls go to the endpoints of the partition whose prices they query: that
+// of the region in their regionCode filter, or for queries across regions,
+// that of the endpoint chosen with SetEndpoint. The chosen endpoint,
+// DefaultEndpoint by default, is tried first when it serves the partition.
+// When an endpoint cannot be reached or denies the call, the partition's
+// other endpoints are tried in turn, and the first that answers serves the
+// partition's following calls too. Later pages of a paginated query are
+// only requested from that endpoint. The endpoint that served each response
+// is recorded in the Provenance of the rates read from it.
+//
+// A Client uses one set of credentials, so it can only reach the
+// partitions those credentials belong to: AWS China needs China
+// credentials, while GovCloud prices are read with commercial ones.
+type Client struct {
+	mu       sync.Mutex
+	creds    Credentials
+	endpoint string
+	active   map[string]string // partition ID -> endpoint that last answered
+	cfg      *aws.Config
+	clients  map[string]PricingAPI
+}
+
+// NewClient returns a client that uses creds.
+func NewClient(creds Credentials) *Client {
+	return &Client{creds: creds, endpoint: DefaultEndpoint}
+}
+
+// Credentials returns the credentials the client uses.
+func (c *Client) Credentials() Credentials {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.creds
+}
+
+// SetCredentials switches the client to creds. Calls already in flight
+// finish with the previous ones.
+func (c *Client) SetCredentials(creds Credentials) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if creds != c.creds {
+		c.creds = creds
+		c.reset()
+	}
+}
+
+// Endpoint returns the region of the endpoint the client tries first.
+func (c *Client) Endpoint() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endpoint
+}
+
+// SetEndpoint makes the client try the endpoint in region first. Empty
+// selects DefaultEndpoint. Roles are assumed through STS in the same
+// region.
+func (c *Client) SetEndpoint(region string) {
+	if region == "" {
+		region = DefaultEndpoint
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if region != c.endpoint {
+		c.endpoint = region
+		c.reset()
+	}
+}
+
+// reset drops the loaded configuration and clients, and forgets which
+// endpoints answered. c.mu must be held.
+func (c *Client) reset() {
+	c.cfg = nil
+	c.clients = nil
+	c.active = nil
+}
+
+// GetProducts implements PricingAPI, failing over across the endpoints.
+// If every endpoint fails, the error of each is returned.
+func (c *Client) GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	partition, endpoints := c.endpoints(params)
+	var errs []error
+	for _, endpoint := range endpoints {
+		client, err := c.load(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := client.GetProducts(ctx, params, optFns...)
+		if err == nil {
+			c.served(partition, endpoint)
+			output.ResultMetadata.Set(endpointKey{}, endpoint)
+			return output, nil
+		}
+		errs = append(errs, fmt.Errorf("endpoint %s: %w", endpoint, err))
+		if !failsOver(ctx, err) {
+			if len(errs) == 1 {
+				return nil, err
+			}
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// endpoints returns the ID of the partition a call is for and the endpoints
+// to try, starting with the one that last answered for the partition. A
+// page after the first is only requested from the first endpoint.
+func (c *Client) endpoints(params *pricing.GetProductsInput) (string, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	partition, named := callPartition(params, c.endpoint)
+	candidates := partition.Endpoints
+	if !named || slices.Contains(candidates, c.endpoint) {
+		candidates = append([]string{c.endpoint}, candidates...)
+	}
+	if active := c.active[partition.ID]; active != "" {
+		candidates = append([]string{active}, candidates...)
+	}
+
+	var order []string
+	for _, region := range candidates {
+		if !slices.Contains(order, region) {
+			order = append(order, region)
+		}
+	}
+	if params.NextToken != nil {
+		order = order[:1]
+	}
+	return partition.ID, order
+}
+
+// served records that endpoint answered for the partition, so that its
+// later calls start there.
+func (c *Client) served(partition, endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active == nil {
+		c.active = make(map[string]string)
+	}
+	c.active[partition] = endpoint
+}
+
+// load returns the client for endpoint with the current credentials,
+// loading the configuration and creating the client if need be.
+func (c *Client) load(ctx context.Context, endpoint string) (PricingAPI, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cfg == nil {
+		cfg, err := loadConfig(ctx, c.creds, c.endpoint)
+		if err != nil {
+			return nil, err
+		}
+		c.cfg = &cfg
+		c.clients = make(map[string]PricingAPI)
+	}
+	client, ok := c.clients[endpoint]
+	if !ok {
+		cfg := c.cfg.Copy()
+		cfg.Region = endpoint
+		client = newPricingClient(cfg)
+		c.clients[endpoint] = client
+	}
+	return client, nil
+}
+
+// Profiles returns the names of the profiles in the shared config and
+// credentials files, sorted. Like the SDK, it honours AWS_CONFIG_FILE and
+// AWS_SHARED_CREDENTIALS_FILE. Missing files are not an error.
+func Profiles() ([]string, error) {
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = config.DefaultSharedConfigFilename()
+	}
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = config.DefaultSharedCredentialsFilename()
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range []struct {
+		path     string
+		prefixed bool
+	}{{configFile, true}, {credentialsFile, false}} {
+		names, err := readProfiles(f.path, f.prefixed)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			seen[name] = true
+		}
+	}
+
+	profiles := make([]string, 0, len(seen))
+	for name := range seen {
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// readProfiles returns the profile names in the section headers of the
+// file at path. The config file names profiles "[profile NAME]", except
+// for "[default]", and has other kinds of section; the credentials file
+// names them "[NAME]".
+func readProfiles(path string, prefixed bool) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		section := strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+		name, isProfile := section, true
+		if prefixed && section != "default" {
+			name, isProfile = strings.CutPrefix(section, "profile ")
+		}
+		if isProfile && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, scanner.Err()
+}
@@ -0,0 +1,271 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+// fakeSTS answers AssumeRole with fixed credentials, or err.
+type fakeSTS struct {
+	err   error
+	input *sts.AssumeRoleInput
+}
+
+func (f *fakeSTS) AssumeRole(_ context.Context, params *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	f.input = params
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sts.AssumeRoleOutput{Credentials: &ststypes.Credentials{
+		AccessKeyId:     aws.String("ASIAROLE"),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(time.Now().Add(time.Hour)),
+	}}, nil
+}
+
+// withConfig makes loadDefaultConfig return a config whose credentials
+// come from provider, recording the options it was called with.
+func withConfig(t *testing.T, provider aws.CredentialsProvider, err error) *config.LoadOptions {
+	t.Helper()
+	orig := loadDefaultConfig
+	t.Cleanup(func() { loadDefaultConfig = orig })
+
+	var opts config.LoadOptions
+	loadDefaultConfig = func(_ context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		opts = config.LoadOptions{}
+		for _, fn := range optFns {
+			_ = fn(&opts)
+		}
+		return aws.Config{Credentials: provider}, err
+	}
+	return &opts
+}
+
+func staticProvider(err error) aws.CredentialsProvider {
+	return aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKIABASE"}, err
+	})
+}
+
+func TestLoadConfigProfile(t *testing.T) {
+	opts := withConfig(t, staticProvider(nil), nil)
+
+	if _, err := loadConfig(context.Background(), Credentials{}, DefaultEndpoint); err != nil {
+		t.Fatal(err)
+	}
+	if opts.SharedConfigProfile != "" || opts.Region != "us-east-1" {
+		t.Errorf("expected the default profile and the Pricing API region, got %+v", opts)
+	}
+
+	cfg, err := loadConfig(context.Background(), Credentials{Profile: "prod"}, DefaultEndpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.SharedConfigProfile != "prod" {
+		t.Errorf("expected the prod profile selected, got %q", opts.SharedConfigProfile)
+	}
+	if creds, err := cfg.Credentials.Retrieve(context.Background()); err != nil || creds.AccessKeyID != "AKIABASE" {
+		t.Errorf("expected the profile's credentials, got %+v, %v", creds, err)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	withConfig(t, nil, errors.New("failed to get shared config profile, prod"))
+	_, err := loadConfig(context.Background(), Credentials{Profile: "prod"}, DefaultEndpoint)
+	var credErr *CredentialsError
+	if !errors.As(err, &credErr) || credErr.Profile != "prod" || credErr.RoleARN != "" {
+		t.Errorf("expected a credentials error for the profile, got %v", err)
+	}
+
+	withConfig(t, staticProvider(errors.New("no EC2 IMDS role found")), nil)
+	cfg, err := loadConfig(context.Background(), Credentials{Profile: "prod"}, DefaultEndpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cfg.Credentials.Retrieve(context.Background())
+	if !errors.As(err, &credErr) || credErr.Profile != "prod" || Classify(err) != ErrorAuth {
+		t.Errorf("expected retrieval failures reported as credentials errors, got %v", err)
+	}
+}
+
+func TestLoadConfigRole(t *testing.T) {
+	withConfig(t, staticProvider(nil), nil)
+	orig := newSTSClient
+	t.Cleanup(func() { newSTSClient = orig })
+	fake := &fakeSTS{}
+	newSTSClient = func(aws.Config) stscreds.AssumeRoleAPIClient { return fake }
+
+	const arn = "arn:aws:iam::123456789012:role/pricing"
+	cfg, err := loadConfig(context.Background(), Credentials{Profile: "prod", RoleARN: arn}, DefaultEndpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	if err != nil || creds.AccessKeyID != "ASIAROLE" {
+		t.Fatalf("expected the role's credentials, got %+v, %v", creds, err)
+	}
+	if *fake.input.RoleArn != arn || *fake.input.RoleSessionName != roleSessionName {
+		t.Errorf("unexpected AssumeRole input %+v", fake.input)
+	}
+
+	fake.err = apiError{"AccessDenied"}
+	cfg, _ = loadConfig(context.Background(), Credentials{Profile: "prod", RoleARN: arn}, DefaultEndpoint)
+	_, err = cfg.Credentials.Retrieve(context.Background())
+	var credErr *CredentialsError
+	if !errors.As(err, &credErr) || credErr.RoleARN != arn {
+		t.Errorf("expected a role error, got %v", err)
+	}
+
+	// The base credentials failing is reported as such, not as the role.
+	fake.err = &CredentialsError{Profile: "prod", Err: errors.New("no credentials")}
+	cfg, _ = loadConfig(context.Background(), Credentials{Profile: "prod", RoleARN: arn}, DefaultEndpoint)
+	_, err = cfg.Credentials.Retrieve(context.Background())
+	if !errors.As(err, &credErr) || credErr.RoleARN != "" {
+		t.Errorf("expected the profile's error, got %v", err)
+	}
+}
+
+func TestCredentialsErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  *CredentialsError
+		want string
+	}{
+		{&CredentialsError{Err: errors.New("boom")}, "loading AWS credentials: boom"},
+		{&CredentialsError{Profile: "prod", Err: errors.New("boom")}, `loading AWS credentials for profile "prod": boom`},
+		{&CredentialsError{Profile: "prod", RoleARN: "arn:role", Err: errors.New("boom")}, "assuming role arn:role: boom"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	opts := withConfig(t, staticProvider(nil), nil)
+	orig := newPricingClient
+	t.Cleanup(func() { newPricingClient = orig })
+	created := 0
+	newPricingClient = func(aws.Config) PricingAPI {
+		created++
+		return &mockPricingAPI{}
+	}
+
+	c := NewClient(Credentials{Profile: "dev"})
+	for range 2 {
+		if _, err := c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if created != 1 || opts.SharedConfigProfile != "dev" {
+		t.Errorf("expected one client for the dev profile, got %d for %q", created, opts.SharedConfigProfile)
+	}
+
+	c.SetCredentials(Credentials{Profile: "dev"})
+	_, _ = c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")})
+	if created != 1 {
+		t.Error("setting the same credentials should keep the client")
+	}
+
+	c.SetCredentials(Credentials{Profile: "prod"})
+	if c.Credentials().Profile != "prod" {
+		t.Errorf("expected the prod profile, got %+v", c.Credentials())
+	}
+	_, _ = c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")})
+	if created != 2 || opts.SharedConfigProfile != "prod" {
+		t.Errorf("expected a new client for the prod profile, got %d for %q", created, opts.SharedConfigProfile)
+	}
+}
+
+func TestClientLoadError(t *testing.T) {
+	withConfig(t, nil, errors.New("failed to get shared config profile, missing"))
+	c := NewClient(Credentials{Profile: "missing"})
+	_, err := c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")})
+	if Classify(err) != ErrorAuth {
+		t.Errorf("expected an auth error, got %v", err)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+
+	if profiles, err := Profiles(); err != nil || len(profiles) != 0 {
+		t.Fatalf("expected no profiles without files, got %v, %v", profiles, err)
+	}
+
+	_ = os.WriteFile(configFile, []byte(`# comment
+[default]
+region = us-east-1
+
+[profile dev]
+sso_session = corp
+
+[ profile  staging ]
+[sso-session corp]
+sso_start_url = https://example.awsapps.com/start
+[services local]
+`), 0o600)
+	_ = os.WriteFile(credentialsFile, []byte("[prod]\naws_access_key_id = AKIA\n[default]\n"), 0o600)
+
+	profiles, err := Profiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"default", "dev", "prod", "staging"}; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("got %v, want %v", profiles, want)
+	}
+}
+
+func TestProfilesErrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "missing"))
+
+	t.Setenv("AWS_CONFIG_FILE", dir)
+	if _, err := Profiles(); err == nil {
+		t.Error("expected an error reading a directory")
+	}
+
+	file := filepath.Join(dir, "file")
+	_ = os.WriteFile(file, nil, 0o600)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(file, "config"))
+	if _, err := Profiles(); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("expected an error opening the file, got %v", err)
+	}
+}
+
+func TestProfilesDefaultFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_CONFIG_FILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+	_ = os.MkdirAll(filepath.Join(home, ".aws"), 0o700)
+	_ = os.WriteFile(filepath.Join(home, ".aws", "config"), []byte("[profile dev]\n"), 0o600)
+
+	if profiles, err := Profiles(); err != nil || !reflect.DeepEqual(profiles, []string{"dev"}) {
+		t.Errorf("expected the profile in ~/.aws/config, got %v, %v", profiles, err)
+	}
+}
+
+func TestNewSTSClientDefault(t *testing.T) {
+	if newSTSClient(aws.Config{}) == nil {
+		t.Error("newSTSClient should return a non-nil client")
+	}
+}
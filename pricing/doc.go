@@ -0,0 +1,76 @@
+// Package pricing resolves the hourly AWS rates used by the calculator
+// package.
+//
+// A Provider returns Rates for a region. Default reads the local cache, then
+// the AWS Pricing API using the default credential chain, then falls back to
+// DefaultRates. NewAPIProvider queries the Pricing API with a client you
+// supply, and Static returns fixed rates, which is useful in tests:
+//
+//	rates, err := pricing.Default().Rates(ctx, "eu-west-1")
+//	input.BasePerHour, input.ResourcePerHour = rates.ForCapability(input.Capability)
+//
+// Default is a Chain of Sources. A Chain asks each Source in order and takes
+// every field from the first one that supplies it, so sources need only know
+// some rates. Sources answer with a RateSheet, the Rates plus the
+// Provenance (source, region, usage type and fetch time) and tiers of each;
+// Resolved.Rates records where every resolved rate came from:
+//
+//	chain, err := pricing.BuildChain([]string{"offers", "cache", "api", "defaults"},
+//		pricing.ChainConfig{OfferFiles: []string{"AmazonEKS.json"}})
+//	res, err := chain.Resolve(ctx, "eu-west-1")
+//	fmt.Println(res.Rates.Provenance[pricing.FieldArgoCDBasePerHour].Source) // "offers"
+//
+// CheckRates flags fetched rates that are zero, priced in an unexpected
+// unit, or an order of magnitude away from DefaultRates. A Chain reports
+// them in Resolved.Anomalies and, after Chain.SetRejectSuspect, takes those
+// fields from a later source instead.
+//
+// LoadOverrides reads a file of user-maintained rates, keyed by region
+// pattern and capability, that can head a chain to apply negotiated prices
+// on top of fetched rates.
+//
+// Regions lists the regions where the capabilities are sold, discovered
+// from the Pricing API and cached, or BuiltinRegions offline.
+//
+// Clients this package creates retry throttled and transient failures with
+// jittered backoff under a shared rate limit; NewRetryingClient wraps other
+// clients the same way. Classify tells why a fetch failed, and
+// Resolved.FallbackErr reports the fetch failure behind any fallback to the
+// defaults.
+//
+// NewClient calls the Pricing API with a shared config profile, and
+// optionally an assumed role, chosen through Credentials; Profiles lists
+// the profiles. Diagnose explains credential failures in a form fit to
+// show the user. A Client fails over across the Pricing API endpoints in
+// EndpointRegions, starting with the one chosen by Client.SetEndpoint, and
+// records the endpoint that served each rate in its Provenance. CallTimeout
+// is how long one call may take to do so.
+//
+// A Recorder saves the pages another PricingAPI returns to fixture files,
+// and a Replayer answers calls from them without calling AWS, so that
+// fetches can be checked against captured AWS data offline.
+//
+// Regions belong to a Partition, found with PartitionOf, that decides the
+// endpoints a Client calls for them and the currency of their Rates. AWS
+// China rates are in CNY, and a Chain never mixes rates in different
+// currencies.
+//
+// Products AWS prices in volume tiers keep their full Schedule in
+// RateSheet.Tiers; Schedule.RateTiers converts it for the calculator.
+//
+// Cache.Save appends rates that changed to a per-region history. Read it
+// with Cache.History and Changes, or resolve old rates with HistorySource.
+//
+// A Cache lives in the user cache directory and keeps entries for
+// DefaultCacheTTL unless told otherwise with Cache.SetTTL. Cache.Entries
+// lists what is cached, expired entries included, and Cache.Purge removes
+// entries.
+//
+// # Compatibility
+//
+// This package follows semantic versioning. Within a major version the
+// Provider, Source and PricingAPI interfaces do not gain methods, exported
+// functions keep their signatures, and fields are only added to Rates.
+// Rates stays comparable with ==; use RateSheet.Equal for sheets. The
+// on-disk cache format is not part of the API.
+package pricing
@@ -0,0 +1,92 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+// DefaultEndpoint is the region of the Pricing API endpoint tried first
+// unless another is chosen.
+const DefaultEndpoint = "us-east-1"
+
+// EndpointRegions returns the regions the Pricing API is served from,
+// those of the commercial partition first. Every endpoint of a partition
+// returns the same prices.
+func EndpointRegions() []string {
+	var regions []string
+	for _, p := range partitions {
+		for _, endpoint := range p.Endpoints {
+			if !slices.Contains(regions, endpoint) {
+				regions = append(regions, endpoint)
+			}
+		}
+	}
+	return regions
+}
+
+// ValidEndpoint reports whether the Pricing API is served from region.
+func ValidEndpoint(region string) bool {
+	return slices.Contains(EndpointRegions(), region)
+}
+
+// endpointPartition returns the partition whose prices the endpoint in
+// region serves. Endpoints of no partition are taken to be commercial.
+func endpointPartition(region string) Partition {
+	for _, p := range partitions {
+		if slices.Contains(p.Endpoints, region) {
+			return p.clone()
+		}
+	}
+	return partitions[0].clone()
+}
+
+// callPartition returns the partition a call is for: that of the region
+// its regionCode filter names, or, for a call across regions, that of the
+// endpoint in preferred. It reports whether the call named a region.
+func callPartition(params *pricing.GetProductsInput, preferred string) (Partition, bool) {
+	for _, f := range params.Filters {
+		if aws.ToString(f.Field) == "regionCode" {
+			return PartitionOf(aws.ToString(f.Value)), true
+		}
+	}
+	return endpointPartition(preferred), false
+}
+
+// deniedCodes are the AWS error codes for a call that was refused rather
+// than unauthenticated. Service control policies that deny a region are
+// reported this way.
+var deniedCodes = map[string]bool{
+	"AccessDenied": true, "AccessDeniedException": true, "UnauthorizedOperation": true,
+}
+
+// failsOver reports whether a call that failed with err may succeed at
+// another endpoint: the endpoint could not be reached, or calls to it were
+// denied. Credential failures and cancellation would recur at any endpoint.
+func failsOver(ctx context.Context, err error) bool {
+	var (
+		credErr *CredentialsError
+		apiErr  interface{ ErrorCode() string }
+	)
+	switch {
+	case ctx.Err() != nil, errors.As(err, &credErr):
+		return false
+	case errors.As(err, &apiErr) && deniedCodes[apiErr.ErrorCode()]:
+		return true
+	}
+	return Classify(err) == ErrorNetwork
+}
+
+// endpointKey is the result metadata key under which Client records the
+// endpoint that served a response.
+type endpointKey struct{}
+
+// servedBy returns the region of the endpoint that served output, or "" if
+// it was not recorded.
+func servedBy(output *pricing.GetProductsOutput) string {
+	endpoint, _ := output.ResultMetadata.Get(endpointKey{}).(string)
+	return endpoint
+}
@@ -0,0 +1,177 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+func TestEndpointRegions(t *testing.T) {
+	regions := EndpointRegions()
+	if want := []string{"us-east-1", "eu-central-1", "ap-south-1", "cn-northwest-1"}; !reflect.DeepEqual(regions, want) {
+		t.Errorf("got %v, want %v", regions, want)
+	}
+	regions[0] = "changed"
+	if EndpointRegions()[0] != DefaultEndpoint {
+		t.Error("EndpointRegions should return a copy")
+	}
+
+	if !ValidEndpoint("eu-central-1") || ValidEndpoint("eu-west-1") || ValidEndpoint("") {
+		t.Error("ValidEndpoint should accept only the endpoint regions")
+	}
+}
+
+func TestFailsOver(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"unreachable", context.Background(), &net.OpError{Op: "dial", Err: errors.New("refused")}, true},
+		{"server error", context.Background(), apiError{"ServiceUnavailable"}, true},
+		{"denied", context.Background(), apiError{"AccessDeniedException"}, true},
+		{"expired", context.Background(), apiError{"ExpiredTokenException"}, false},
+		{"credentials", context.Background(), &CredentialsError{Err: apiError{"AccessDenied"}}, false},
+		{"throttled", context.Background(), apiError{"ThrottlingException"}, false},
+		{"canceled", canceled, &net.OpError{Op: "dial", Err: errors.New("refused")}, false},
+	}
+	for _, tt := range tests {
+		if got := failsOver(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// endpointAPIs makes Client create, for each endpoint region, a client
+// that fails with the error in errs or answers with products, recording the
+// regions called.
+func endpointAPIs(t *testing.T, errs map[string]error, products map[string]*pricing.GetProductsOutput) *[]string {
+	t.Helper()
+	withConfig(t, staticProvider(nil), nil)
+	orig := newPricingClient
+	t.Cleanup(func() { newPricingClient = orig })
+
+	var calls []string
+	newPricingClient = func(cfg aws.Config) PricingAPI {
+		return endpointAPI{region: cfg.Region, calls: &calls, mock: &mockPricingAPI{responses: products, err: errs[cfg.Region]}}
+	}
+	return &calls
+}
+
+type endpointAPI struct {
+	region string
+	calls  *[]string
+	mock   *mockPricingAPI
+}
+
+func (e endpointAPI) GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	*e.calls = append(*e.calls, e.region)
+	return e.mock.GetProducts(ctx, params, optFns...)
+}
+
+func TestClientFailover(t *testing.T) {
+	denied := apiError{"AccessDeniedException"}
+	calls := endpointAPIs(t, map[string]error{"us-east-1": denied}, allCapabilityProducts("eu-west-1"))
+
+	c := NewClient(Credentials{})
+	rates, err := APISource(c, nil).Lookup(context.Background(), "eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := rates.Provenance[FieldArgoCDBasePerHour]; p.Endpoint != "eu-central-1" {
+		t.Errorf("expected the rate served by eu-central-1, got %+v", p)
+	}
+	if p := rates.Provenance[FieldFargateVCPUPerHour]; p.Endpoint != "eu-central-1" {
+		t.Errorf("expected the Fargate rate served by eu-central-1, got %+v", p)
+	}
+	// Once an endpoint answers, the next calls start there.
+	if (*calls)[0] != "us-east-1" || (*calls)[1] != "eu-central-1" || (*calls)[2] != "eu-central-1" {
+		t.Errorf("unexpected endpoints called: %v", *calls)
+	}
+}
+
+func TestClientPreferredEndpoint(t *testing.T) {
+	calls := endpointAPIs(t, nil, nil)
+	opts := withConfig(t, staticProvider(nil), nil)
+
+	c := NewClient(Credentials{})
+	c.SetEndpoint("ap-south-1")
+	if c.Endpoint() != "ap-south-1" {
+		t.Errorf("expected ap-south-1, got %q", c.Endpoint())
+	}
+	output, err := c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if servedBy(output) != "ap-south-1" || opts.Region != "ap-south-1" {
+		t.Errorf("expected ap-south-1 to serve the call and assume roles, got %q and %q", servedBy(output), opts.Region)
+	}
+
+	c.SetEndpoint("")
+	if c.Endpoint() != DefaultEndpoint {
+		t.Errorf("expected the default endpoint, got %q", c.Endpoint())
+	}
+	_, _ = c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")})
+	if want := []string{"ap-south-1", "us-east-1"}; !reflect.DeepEqual(*calls, want) {
+		t.Errorf("got %v, want %v", *calls, want)
+	}
+}
+
+func TestClientFailoverOrder(t *testing.T) {
+	unreachable := &net.OpError{Op: "dial", Err: errors.New("refused")}
+	calls := endpointAPIs(t, map[string]error{"us-east-1": unreachable, "eu-central-1": unreachable, "ap-south-1": unreachable}, nil)
+
+	c := NewClient(Credentials{})
+	c.SetEndpoint("eu-central-1")
+	_, err := c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")})
+	if want := []string{"eu-central-1", "us-east-1", "ap-south-1"}; !reflect.DeepEqual(*calls, want) {
+		t.Errorf("got %v, want %v", *calls, want)
+	}
+	if Classify(err) != ErrorNetwork || !strings.Contains(err.Error(), "endpoint ap-south-1: ") {
+		t.Errorf("expected every endpoint's error, got %v", err)
+	}
+
+	// Later pages are not requested from another endpoint.
+	*calls = nil
+	_, _ = c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS"), NextToken: aws.String("page2")})
+	if want := []string{"eu-central-1"}; !reflect.DeepEqual(*calls, want) {
+		t.Errorf("got %v, want %v", *calls, want)
+	}
+}
+
+func TestClientNoFailover(t *testing.T) {
+	expired := apiError{"ExpiredTokenException"}
+	calls := endpointAPIs(t, map[string]error{"us-east-1": expired}, nil)
+
+	c := NewClient(Credentials{})
+	_, err := c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")})
+	if !errors.Is(err, expired) || err.Error() != expired.Error() || len(*calls) != 1 {
+		t.Errorf("expected expired credentials returned as is after one call, got %v after %v", err, *calls)
+	}
+
+	// A failure that ends failover after another endpoint failed reports
+	// both.
+	denied := apiError{"AccessDenied"}
+	calls = endpointAPIs(t, map[string]error{"us-east-1": denied, "eu-central-1": expired}, nil)
+	c = NewClient(Credentials{})
+	_, err = c.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")})
+	if !strings.Contains(err.Error(), "endpoint us-east-1: ") || !strings.Contains(err.Error(), "endpoint eu-central-1: ") || len(*calls) != 2 {
+		t.Errorf("expected both endpoints' errors, got %v after %v", err, *calls)
+	}
+}
+
+func TestServedByUnrecorded(t *testing.T) {
+	if got := servedBy(&pricing.GetProductsOutput{}); got != "" {
+		t.Errorf("expected no endpoint, got %q", got)
+	}
+}
@@ -0,0 +1,123 @@
+package pricing
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CacheEntry describes the cached rates for one region.
+type CacheEntry struct {
+	Region string
+
+	// Rates holds the rates as they were saved, with the provenance they
+	// had then: a rate fetched from the API keeps the api source.
+	Rates     RateSheet
+	FetchedAt time.Time
+
+	// Expired reports that the entry has outlived the cache's TTL. Load
+	// and CacheSource ignore expired entries.
+	Expired bool
+}
+
+// Cached returns the entry's rates as CacheSource serves them, attributed
+// to the cache rather than to where they were first fetched from.
+func (e CacheEntry) Cached() RateSheet {
+	return servedFromCache(e.Rates, e.FetchedAt)
+}
+
+// servedFromCache returns saved rates as the cache serves them: every rate
+// that is not a default is attributed to the cache, keeping where it was
+// fetched from and when, fetchedAt if that was not recorded.
+func servedFromCache(saved RateSheet, fetchedAt time.Time) RateSheet {
+	rates := saved
+	rates.Provenance = make(map[Field]Provenance, len(Fields()))
+	for _, f := range Fields() {
+		if rates.Get(f) <= 0 {
+			continue
+		}
+		p := saved.Provenance[f]
+		if p.Source != SourceDefaults {
+			p.Source = SourceCache
+			if p.FetchedAt.IsZero() {
+				p.FetchedAt = fetchedAt
+			}
+		}
+		rates.Provenance[f] = p
+	}
+	return rates
+}
+
+// Entry returns the cached rates for region whatever their age. It reports
+// false if there is no entry, or only one in an older format.
+func (c *Cache) Entry(region string) (CacheEntry, bool) {
+	entry := c.read(region)
+	if entry == nil {
+		return CacheEntry{}, false
+	}
+	return CacheEntry{
+		Region:    region,
+		Rates:     entry.Rates,
+		FetchedAt: entry.FetchedAt,
+		Expired:   c.expired(entry.FetchedAt),
+	}, true
+}
+
+// Entries returns every region's entry, sorted by region, expired ones
+// included. Entries in an older format are left out.
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	paths, err := filepath.Glob(c.path("*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var entries []CacheEntry
+	for _, p := range paths {
+		region := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), "rates-"), ".json")
+		if e, ok := c.Entry(region); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// Purge deletes the cached rates for region, so that the next lookup
+// fetches them again. The region's history is kept.
+func (c *Cache) Purge(region string) error {
+	return c.remove(c.path(region))
+}
+
+// PurgeAll deletes the cached rates for every region and the cached region
+// list. Rate history is kept.
+func (c *Cache) PurgeAll() error {
+	paths, err := filepath.Glob(c.path("*"))
+	if err != nil {
+		return err
+	}
+	return c.remove(append(paths, c.regionsPath())...)
+}
+
+// remove deletes paths under the cache lock. Missing files are not an
+// error, and neither is a missing cache directory.
+func (c *Cache) remove(paths ...string) error {
+	if _, err := os.Stat(c.dir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	unlock, err := c.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
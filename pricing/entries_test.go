@@ -0,0 +1,125 @@
+package pricing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheTTL(t *testing.T) {
+	c := newTestCache(t)
+	saved := time.Now()
+	c.now = func() time.Time { return saved }
+	if err := c.Save("us-east-1", DefaultRates()); err != nil {
+		t.Fatal(err)
+	}
+
+	c.now = func() time.Time { return saved.Add(2 * time.Hour) }
+	if c.Load("us-east-1") == nil {
+		t.Error("expected the entry valid under the default TTL")
+	}
+	c.SetTTL(time.Hour)
+	if c.Load("us-east-1") != nil {
+		t.Error("expected the entry expired under a 1h TTL")
+	}
+	c.SetTTL(0)
+	if c.Load("us-east-1") == nil {
+		t.Error("a zero TTL should restore the default")
+	}
+}
+
+func TestCacheEntries(t *testing.T) {
+	c := newTestCache(t)
+	if entries, err := c.Entries(); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries in an empty cache, got %v, %v", entries, err)
+	}
+
+	saved := time.Now().Add(-30 * time.Hour)
+	c.now = func() time.Time { return saved }
+	_ = c.Save("eu-west-1", DefaultRates())
+	c.now = time.Now
+	_ = c.Save("ap-south-1", DefaultRates())
+	writeCacheEntry(t, c, "us-east-1", 0, DefaultRates())
+
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Region != "ap-south-1" || entries[1].Region != "eu-west-1" {
+		t.Fatalf("expected the current entries sorted by region, got %+v", entries)
+	}
+	if entries[0].Expired || !entries[1].Expired {
+		t.Errorf("expected only eu-west-1 expired, got %+v", entries)
+	}
+	if !entries[1].FetchedAt.Equal(saved) || entries[1].Rates.Rates != DefaultRates() {
+		t.Errorf("unexpected entry %+v", entries[1])
+	}
+	if p := entries[1].Cached().Provenance[FieldArgoCDBasePerHour]; p.Source != SourceCache || !p.FetchedAt.Equal(saved) {
+		t.Errorf("expected the rates attributed to the cache, got %+v", p)
+	}
+
+	if _, ok := c.Entry("us-east-1"); ok {
+		t.Error("entries in an older format should not be reported")
+	}
+}
+
+func TestCacheEntriesBadDir(t *testing.T) {
+	c := NewCacheDir(filepath.Join(t.TempDir(), "bad["))
+	if _, err := c.Entries(); err == nil {
+		t.Error("expected an error globbing a malformed path")
+	}
+	if err := c.PurgeAll(); err == nil {
+		t.Error("expected an error globbing a malformed path")
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	c := newTestCache(t)
+	_ = c.Save("us-east-1", DefaultRates())
+	_ = c.Save("eu-west-1", DefaultRates())
+	_ = c.SaveRegions([]Region{{Code: "us-east-1"}})
+
+	if err := c.Purge("us-east-1"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Load("us-east-1") != nil || c.Load("eu-west-1") == nil {
+		t.Error("expected only us-east-1 purged")
+	}
+	if err := c.Purge("us-east-1"); err != nil {
+		t.Errorf("purging a missing entry should succeed, got %v", err)
+	}
+
+	if err := c.PurgeAll(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Load("eu-west-1") != nil || c.LoadRegions() != nil {
+		t.Error("expected every entry and the region list purged")
+	}
+	if history, _ := c.History("eu-west-1"); len(history) != 1 {
+		t.Errorf("expected the history kept, got %d snapshots", len(history))
+	}
+}
+
+func TestCachePurgeErrors(t *testing.T) {
+	c := NewCacheDir(filepath.Join(t.TempDir(), "missing"))
+	if err := c.PurgeAll(); err != nil {
+		t.Errorf("purging a missing cache should succeed, got %v", err)
+	}
+
+	c = newTestCache(t)
+	if err := os.Mkdir(filepath.Join(c.dir, cacheLockFile), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Purge("us-east-1"); err == nil {
+		t.Error("expected the lock error")
+	}
+
+	c = newTestCache(t)
+	if err := os.MkdirAll(filepath.Join(c.path("us-east-1"), "child"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Purge("us-east-1"); err == nil {
+		t.Error("expected an error removing a non-empty directory")
+	}
+}
@@ -0,0 +1,177 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+)
+
+// ErrorKind classifies why fetching rates failed, so that callers can tell
+// the user what to fix.
+type ErrorKind int
+
+const (
+	// ErrorOther is any failure not covered by another kind.
+	ErrorOther ErrorKind = iota
+	// ErrorAuth means AWS credentials are missing, invalid, expired or not
+	// allowed to call the Pricing API.
+	ErrorAuth
+	// ErrorThrottled means the Pricing API rejected calls for exceeding its
+	// request rate.
+	ErrorThrottled
+	// ErrorNetwork means the Pricing API could not be reached, failed on its
+	// side or did not answer in time.
+	ErrorNetwork
+	// ErrorNotFound means no product or region matched the query.
+	ErrorNotFound
+)
+
+// String returns a short name for the kind.
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorAuth:
+		return "auth"
+	case ErrorThrottled:
+		return "throttled"
+	case ErrorNetwork:
+		return "network"
+	case ErrorNotFound:
+		return "not found"
+	default:
+		return "other"
+	}
+}
+
+// errNoProducts is returned when a query matches no product.
+var errNoProducts = errors.New("no products found")
+
+// AWS error codes, by kind. The Pricing API returns only some of them, but
+// the credential and signing errors come from STS and SSO as well.
+var (
+	throttlingCodes = map[string]bool{
+		"Throttling": true, "ThrottlingException": true, "ThrottledException": true,
+		"RequestThrottled": true, "RequestThrottledException": true,
+		"TooManyRequestsException": true, "RequestLimitExceeded": true,
+		"SlowDown": true, "PriorRequestNotComplete": true,
+	}
+	authCodes = map[string]bool{
+		"AccessDenied": true, "AccessDeniedException": true,
+		"UnrecognizedClientException": true, "InvalidClientTokenId": true,
+		"InvalidSignatureException": true, "SignatureDoesNotMatch": true,
+		"ExpiredToken": true, "ExpiredTokenException": true,
+		"MissingAuthenticationToken": true, "IncompleteSignature": true,
+		"UnauthorizedOperation": true,
+	}
+	notFoundCodes = map[string]bool{
+		"NotFoundException": true, "ResourceNotFoundException": true,
+	}
+	serverCodes = map[string]bool{
+		"InternalErrorException": true, "InternalFailure": true,
+		"ServiceUnavailable": true, "ServiceUnavailableException": true,
+	}
+)
+
+// Classify reports the kind of a rate-fetching error. It recognises AWS
+// error codes and HTTP status codes, credential and connection failures,
+// timeouts, and this package's not-found errors.
+func Classify(err error) ErrorKind {
+	var (
+		apiErr  interface{ ErrorCode() string }
+		respErr interface{ HTTPStatusCode() int }
+		connErr interface{ ConnectionError() bool }
+		netErr  net.Error
+		signErr *v4.SigningError
+		credErr *CredentialsError
+	)
+
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return ErrorOther
+	case errors.Is(err, errNoProducts), errors.Is(err, ErrRegionNotInOffers), errors.Is(err, ErrNoRegions):
+		return ErrorNotFound
+	case errors.As(err, &credErr), errors.As(err, &signErr):
+		return ErrorAuth
+	case errors.As(err, &apiErr) && apiErr.ErrorCode() != "":
+		code := apiErr.ErrorCode()
+		switch {
+		case throttlingCodes[code]:
+			return ErrorThrottled
+		case authCodes[code]:
+			return ErrorAuth
+		case notFoundCodes[code]:
+			return ErrorNotFound
+		case serverCodes[code]:
+			return ErrorNetwork
+		}
+	}
+
+	switch {
+	case errors.As(err, &respErr):
+		switch status := respErr.HTTPStatusCode(); {
+		case status == 429:
+			return ErrorThrottled
+		case status == 401 || status == 403:
+			return ErrorAuth
+		case status == 404:
+			return ErrorNotFound
+		case status >= 500:
+			return ErrorNetwork
+		}
+	case errors.As(err, &connErr) && connErr.ConnectionError(), errors.As(err, &netErr),
+		errors.Is(err, context.DeadlineExceeded):
+		return ErrorNetwork
+	}
+	return ErrorOther
+}
+
+// expiredCodes are the AWS error codes for expired credentials.
+var expiredCodes = map[string]bool{
+	"ExpiredToken": true, "ExpiredTokenException": true, "RequestExpired": true,
+}
+
+// Diagnose explains an ErrorAuth error as a clause fit to show the user,
+// naming what to fix: a profile missing from the shared config files, an
+// expired SSO session or credentials, a role that could not be assumed, or
+// no credentials at all. It returns "" for errors of any other kind.
+func Diagnose(err error) string {
+	if Classify(err) != ErrorAuth {
+		return ""
+	}
+
+	var (
+		credErr    *CredentialsError
+		profileErr config.SharedConfigProfileNotExistError
+		ssoErr     *ssocreds.InvalidTokenError
+		apiErr     interface{ ErrorCode() string }
+	)
+	hasCredErr := errors.As(err, &credErr)
+	profile := ""
+	if hasCredErr {
+		profile = credErr.Profile
+	}
+
+	switch {
+	case errors.As(err, &profileErr):
+		return fmt.Sprintf("AWS profile %q is not in the shared config files", profileErr.Profile)
+	case errors.As(err, &ssoErr):
+		if profile == "" {
+			return "the AWS SSO session has expired, run aws sso login"
+		}
+		return fmt.Sprintf("the AWS SSO session has expired, run aws sso login --profile %s", profile)
+	case errors.As(err, &apiErr) && expiredCodes[apiErr.ErrorCode()]:
+		return "AWS credentials have expired"
+	case hasCredErr && credErr.RoleARN != "":
+		return fmt.Sprintf("could not assume role %s", credErr.RoleARN)
+	case hasCredErr && profile != "":
+		return fmt.Sprintf("no usable AWS credentials for profile %q", profile)
+	case hasCredErr:
+		return "no AWS credentials found, configure a profile or set AWS_PROFILE"
+	default:
+		return "AWS credentials are missing, expired or not allowed to use the Pricing API"
+	}
+}
@@ -0,0 +1,115 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+)
+
+// apiError mimics the AWS SDK's API errors, which carry an error code.
+type apiError struct{ code string }
+
+func (e apiError) Error() string     { return "api error " + e.code }
+func (e apiError) ErrorCode() string { return e.code }
+
+// statusError mimics the SDK's HTTP response errors.
+type statusError struct{ status int }
+
+func (e statusError) Error() string       { return fmt.Sprintf("http %d", e.status) }
+func (e statusError) HTTPStatusCode() int { return e.status }
+
+// sendError mimics the SDK's request send errors.
+type sendError struct{}
+
+func (sendError) Error() string         { return "request send failed" }
+func (sendError) ConnectionError() bool { return true }
+
+func TestClassify(t *testing.T) {
+	wrap := func(err error) error { return fmt.Errorf("operation GetProducts: %w", err) }
+	tests := []struct {
+		err  error
+		want ErrorKind
+	}{
+		{nil, ErrorOther},
+		{errors.New("boom"), ErrorOther},
+		{context.Canceled, ErrorOther},
+		{wrap(apiError{"ThrottlingException"}), ErrorThrottled},
+		{wrap(apiError{"AccessDeniedException"}), ErrorAuth},
+		{wrap(apiError{"ExpiredTokenException"}), ErrorAuth},
+		{wrap(apiError{"NotFoundException"}), ErrorNotFound},
+		{wrap(apiError{"InternalErrorException"}), ErrorNetwork},
+		{wrap(apiError{"InvalidParameterException"}), ErrorOther},
+		{wrap(statusError{429}), ErrorThrottled},
+		{wrap(statusError{403}), ErrorAuth},
+		{wrap(statusError{404}), ErrorNotFound},
+		{wrap(statusError{503}), ErrorNetwork},
+		{wrap(statusError{400}), ErrorOther},
+		{wrap(sendError{}), ErrorNetwork},
+		{wrap(&net.DNSError{Err: "no such host", Name: "api.pricing.us-east-1.amazonaws.com"}), ErrorNetwork},
+		{wrap(context.DeadlineExceeded), ErrorNetwork},
+		{wrap(&v4.SigningError{Err: errors.New("failed to retrieve credentials")}), ErrorAuth},
+		{wrap(&CredentialsError{Profile: "prod", Err: errors.New("profile not found")}), ErrorAuth},
+		{fmt.Errorf("alb: %w", errNoProducts), ErrorNotFound},
+		{fmt.Errorf("x: %w", ErrRegionNotInOffers), ErrorNotFound},
+		{ErrNoRegions, ErrorNotFound},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Errorf("Classify(%v): got %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestErrorKindString(t *testing.T) {
+	want := map[ErrorKind]string{
+		ErrorOther:     "other",
+		ErrorAuth:      "auth",
+		ErrorThrottled: "throttled",
+		ErrorNetwork:   "network",
+		ErrorNotFound:  "not found",
+	}
+	for k, s := range want {
+		if k.String() != s {
+			t.Errorf("%d: got %q, want %q", k, k.String(), s)
+		}
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	wrap := func(err error) error { return fmt.Errorf("operation GetProducts: %w", err) }
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{wrap(apiError{"ThrottlingException"}), ""},
+		{&CredentialsError{Profile: "prod", Err: config.SharedConfigProfileNotExistError{Profile: "prod"}},
+			`AWS profile "prod" is not in the shared config files`},
+		{wrap(&CredentialsError{Profile: "dev", Err: &ssocreds.InvalidTokenError{}}),
+			"the AWS SSO session has expired, run aws sso login --profile dev"},
+		{wrap(&CredentialsError{Err: &ssocreds.InvalidTokenError{}}),
+			"the AWS SSO session has expired, run aws sso login"},
+		{wrap(apiError{"ExpiredTokenException"}), "AWS credentials have expired"},
+		{wrap(&CredentialsError{Profile: "prod", RoleARN: "arn:role", Err: apiError{"ExpiredToken"}}),
+			"AWS credentials have expired"},
+		{wrap(&CredentialsError{Profile: "prod", RoleARN: "arn:role", Err: apiError{"AccessDenied"}}),
+			"could not assume role arn:role"},
+		{wrap(&CredentialsError{Profile: "prod", Err: errors.New("no credentials")}),
+			`no usable AWS credentials for profile "prod"`},
+		{wrap(&CredentialsError{Err: errors.New("no EC2 IMDS role found")}),
+			"no AWS credentials found, configure a profile or set AWS_PROFILE"},
+		{wrap(apiError{"AccessDeniedException"}),
+			"AWS credentials are missing, expired or not allowed to use the Pricing API"},
+	}
+	for _, tt := range tests {
+		if got := Diagnose(tt.err); got != tt.want {
+			t.Errorf("Diagnose(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package pricing_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+func ExampleStatic() {
+	var provider pricing.Provider = pricing.Static(pricing.DefaultRates())
+
+	rates, err := provider.Rates(context.Background(), "us-east-1")
+	if err != nil {
+		panic(err)
+	}
+
+	base, resource := rates.ForCapability(calculator.CapabilityACK)
+	fmt.Printf("ACK base %.3f/hr, per resource %.5f/hr\n", base, resource)
+	// Output:
+	// ACK base 0.005/hr, per resource 0.00005/hr
+}
+
+func ExampleProviderFunc() {
+	// Wrap any function, e.g. an internal pricing service, as a Provider.
+	provider := pricing.ProviderFunc(func(ctx context.Context, region string) (pricing.Rates, error) {
+		rates := pricing.DefaultRates()
+		rates.ArgoCDBasePerHour = 0.025 // negotiated discount
+		return rates, nil
+	})
+
+	rates, _ := provider.Rates(context.Background(), "eu-west-1")
+	fmt.Println(rates.ArgoCDBasePerHour)
+	// Output:
+	// 0.025
+}
+
+func ExampleChain_Resolve() {
+	negotiated := pricing.NewSource("negotiated", func(ctx context.Context, region string) (pricing.RateSheet, error) {
+		return pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 0.025}}, nil
+	})
+	chain := pricing.NewChain(negotiated, pricing.DefaultsSource())
+
+	res, err := chain.Resolve(context.Background(), "eu-west-1")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, f := range []pricing.Field{pricing.FieldArgoCDBasePerHour, pricing.FieldArgoCDAppPerHour} {
+		fmt.Printf("%s = %v (%s)\n", f, res.Rates.Get(f), res.Rates.Provenance[f].Source)
+	}
+	// Output:
+	// ArgoCDBasePerHour = 0.025 (negotiated)
+	// ArgoCDAppPerHour = 0.0015 (defaults)
+}
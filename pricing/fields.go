@@ -0,0 +1,101 @@
+package pricing
+
+import "github.com/josegonzalez/aws-eks-calculator/calculator"
+
+// Field names one rate in Rates. Its value is the name of the struct field.
+type Field string
+
+// The fields of Rates, in declaration order.
+const (
+	FieldArgoCDBasePerHour   Field = "ArgoCDBasePerHour"
+	FieldArgoCDAppPerHour    Field = "ArgoCDAppPerHour"
+	FieldACKBasePerHour      Field = "ACKBasePerHour"
+	FieldACKResourcePerHour  Field = "ACKResourcePerHour"
+	FieldKroBasePerHour      Field = "KroBasePerHour"
+	FieldKroRGDPerHour       Field = "KroRGDPerHour"
+	FieldFargateVCPUPerHour  Field = "FargateVCPUPerHour"
+	FieldFargateMemGBPerHour Field = "FargateMemGBPerHour"
+	FieldALBPerHour          Field = "ALBPerHour"
+	FieldALBLCUPerHour       Field = "ALBLCUPerHour"
+	FieldEBSGBMonth          Field = "EBSGBMonth"
+	FieldCloudWatchLogsPerGB Field = "CloudWatchLogsPerGB"
+)
+
+// Fields returns every field of Rates in declaration order.
+func Fields() []Field {
+	return []Field{
+		FieldArgoCDBasePerHour,
+		FieldArgoCDAppPerHour,
+		FieldACKBasePerHour,
+		FieldACKResourcePerHour,
+		FieldKroBasePerHour,
+		FieldKroRGDPerHour,
+		FieldFargateVCPUPerHour,
+		FieldFargateMemGBPerHour,
+		FieldALBPerHour,
+		FieldALBLCUPerHour,
+		FieldEBSGBMonth,
+		FieldCloudWatchLogsPerGB,
+	}
+}
+
+// FieldsForCapability returns the fields holding the base and resource
+// rates of the given capability, matching Rates.ForCapability.
+func FieldsForCapability(cap calculator.Capability) (base, resource Field) {
+	switch cap {
+	case calculator.CapabilityArgoCD:
+		return FieldArgoCDBasePerHour, FieldArgoCDAppPerHour
+	case calculator.CapabilityACK:
+		return FieldACKBasePerHour, FieldACKResourcePerHour
+	case calculator.CapabilityKro:
+		return FieldKroBasePerHour, FieldKroRGDPerHour
+	default:
+		return "", ""
+	}
+}
+
+// Get returns the value of field f, or 0 for an unknown field.
+func (r Rates) Get(f Field) float64 {
+	if p := r.ptr(f); p != nil {
+		return *p
+	}
+	return 0
+}
+
+// Set stores v in field f. Unknown fields are ignored.
+func (r *Rates) Set(f Field, v float64) {
+	if p := r.ptr(f); p != nil {
+		*p = v
+	}
+}
+
+func (r *Rates) ptr(f Field) *float64 {
+	switch f {
+	case FieldArgoCDBasePerHour:
+		return &r.ArgoCDBasePerHour
+	case FieldArgoCDAppPerHour:
+		return &r.ArgoCDAppPerHour
+	case FieldACKBasePerHour:
+		return &r.ACKBasePerHour
+	case FieldACKResourcePerHour:
+		return &r.ACKResourcePerHour
+	case FieldKroBasePerHour:
+		return &r.KroBasePerHour
+	case FieldKroRGDPerHour:
+		return &r.KroRGDPerHour
+	case FieldFargateVCPUPerHour:
+		return &r.FargateVCPUPerHour
+	case FieldFargateMemGBPerHour:
+		return &r.FargateMemGBPerHour
+	case FieldALBPerHour:
+		return &r.ALBPerHour
+	case FieldALBLCUPerHour:
+		return &r.ALBLCUPerHour
+	case FieldEBSGBMonth:
+		return &r.EBSGBMonth
+	case FieldCloudWatchLogsPerGB:
+		return &r.CloudWatchLogsPerGB
+	default:
+		return nil
+	}
+}
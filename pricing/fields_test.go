@@ -0,0 +1,56 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+)
+
+func TestFieldsGetSetRoundTrip(t *testing.T) {
+	var r Rates
+	for i, f := range Fields() {
+		r.Set(f, float64(i+1))
+	}
+	for i, f := range Fields() {
+		if got := r.Get(f); got != float64(i+1) {
+			t.Errorf("%s: got %v, want %v", f, got, i+1)
+		}
+	}
+	if r.ArgoCDBasePerHour != 1 || r.CloudWatchLogsPerGB != 12 {
+		t.Errorf("fields should map onto struct fields in declaration order, got %+v", r)
+	}
+}
+
+func TestFieldsCoverDefaultRates(t *testing.T) {
+	d := DefaultRates()
+	for _, f := range Fields() {
+		if d.Get(f) <= 0 {
+			t.Errorf("%s: expected a default rate", f)
+		}
+	}
+}
+
+func TestUnknownField(t *testing.T) {
+	r := DefaultRates()
+	r.Set("Nope", 1)
+	if r != DefaultRates() {
+		t.Error("setting an unknown field should be a no-op")
+	}
+	if got := r.Get("Nope"); got != 0 {
+		t.Errorf("expected 0 for an unknown field, got %v", got)
+	}
+}
+
+func TestFieldsForCapability(t *testing.T) {
+	r := DefaultRates()
+	for _, cap := range calculator.AllCapabilities {
+		baseField, resField := FieldsForCapability(cap)
+		base, res := r.ForCapability(cap)
+		if r.Get(baseField) != base || r.Get(resField) != res {
+			t.Errorf("%s: fields %s/%s do not match ForCapability", cap, baseField, resField)
+		}
+	}
+	if b, r := FieldsForCapability(calculator.Capability(99)); b != "" || r != "" {
+		t.Errorf("expected no fields for an unknown capability, got %q, %q", b, r)
+	}
+}
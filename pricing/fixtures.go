@@ -0,0 +1,193 @@
+package pricing
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+// ErrNoFixture is returned by Replayer for a call that was not recorded.
+var ErrNoFixture = errors.New("no recorded fixture")
+
+// fixture is one recorded page of a GetProducts call, as stored in a
+// fixture file.
+type fixture struct {
+	Request fixtureRequest `json:"request"`
+
+	// Endpoint is the region of the Pricing API endpoint that served the
+	// page, if the recorded client reported it.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	FormatVersion string            `json:"format_version,omitempty"`
+	PriceList     []json.RawMessage `json:"price_list"`
+	NextToken     string            `json:"next_token,omitempty"`
+}
+
+// fixtureRequest identifies a GetProducts call. Filters are sorted, so
+// that calls differing only in filter order match.
+type fixtureRequest struct {
+	ServiceCode   string          `json:"service_code"`
+	Filters       []fixtureFilter `json:"filters,omitempty"`
+	FormatVersion string          `json:"format_version,omitempty"`
+	MaxResults    int32           `json:"max_results,omitempty"`
+	NextToken     string          `json:"next_token,omitempty"`
+}
+
+type fixtureFilter struct {
+	Type  string `json:"type"`
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+func newFixtureRequest(params *pricing.GetProductsInput) fixtureRequest {
+	req := fixtureRequest{
+		ServiceCode:   aws.ToString(params.ServiceCode),
+		FormatVersion: aws.ToString(params.FormatVersion),
+		MaxResults:    aws.ToInt32(params.MaxResults),
+		NextToken:     aws.ToString(params.NextToken),
+	}
+	for _, f := range params.Filters {
+		req.Filters = append(req.Filters, fixtureFilter{Type: string(f.Type), Field: aws.ToString(f.Field), Value: aws.ToString(f.Value)})
+	}
+	sort.Slice(req.Filters, func(i, j int) bool {
+		a, b := req.Filters[i], req.Filters[j]
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		return a.Type < b.Type
+	})
+	return req
+}
+
+// String describes the call, e.g. "AmazonEKS regionCode=us-east-1".
+func (r fixtureRequest) String() string {
+	parts := []string{r.ServiceCode}
+	for _, f := range r.Filters {
+		parts = append(parts, f.Field+"="+f.Value)
+	}
+	if r.NextToken != "" {
+		parts = append(parts, "page "+r.NextToken)
+	}
+	return strings.Join(parts, " ")
+}
+
+// path returns the file the call is recorded in under dir: the service code
+// and a hash of the call.
+func (r fixtureRequest) path(dir string) string {
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return filepath.Join(dir, r.ServiceCode+"-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// Recorder is a PricingAPI that passes calls to another one and saves every
+// page it returns as a fixture file, for a Replayer to return later.
+type Recorder struct {
+	api PricingAPI
+	dir string
+}
+
+// NewRecorder returns a Recorder calling api and saving pages to dir, which
+// is created if needed. A page recorded again replaces the earlier one.
+func NewRecorder(api PricingAPI, dir string) *Recorder {
+	return &Recorder{api: api, dir: dir}
+}
+
+// GetProducts implements PricingAPI. Failed calls are not recorded, and a
+// page that cannot be saved fails the call, so that a recording is never
+// silently incomplete.
+func (r *Recorder) GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	output, err := r.api.GetProducts(ctx, params, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := newFixtureRequest(params)
+	f := fixture{
+		Request:       req,
+		Endpoint:      servedBy(output),
+		FormatVersion: aws.ToString(output.FormatVersion),
+		PriceList:     make([]json.RawMessage, len(output.PriceList)),
+		NextToken:     aws.ToString(output.NextToken),
+	}
+	for i, item := range output.PriceList {
+		f.PriceList[i] = json.RawMessage(item)
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(r.dir, 0o755)
+	}
+	if err == nil {
+		err = writeFileAtomic(req.path(r.dir), append(data, '\n'))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("recording %s: %w", req, err)
+	}
+	return output, nil
+}
+
+// Replayer is a PricingAPI that answers calls from the fixture files a
+// Recorder saved, without calling AWS. Calls that were not recorded fail
+// with ErrNoFixture.
+type Replayer struct {
+	dir string
+}
+
+// NewReplayer returns a Replayer reading fixture files from dir.
+func NewReplayer(dir string) *Replayer {
+	return &Replayer{dir: dir}
+}
+
+// GetProducts implements PricingAPI. Pages keep the endpoint that served
+// them when they were recorded. Products are returned as compact JSON, as
+// AWS sends them.
+func (r *Replayer) GetProducts(ctx context.Context, params *pricing.GetProductsInput, _ ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	req := newFixtureRequest(params)
+	data, err := os.ReadFile(req.path(r.dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s in %s", ErrNoFixture, req, r.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("reading fixture for %s: %w", req, err)
+	}
+
+	output := &pricing.GetProductsOutput{PriceList: make([]string, len(f.PriceList))}
+	for i, item := range f.PriceList {
+		// Unmarshal has validated the item, so compacting cannot fail.
+		var b bytes.Buffer
+		_ = json.Compact(&b, item)
+		output.PriceList[i] = b.String()
+	}
+	if f.FormatVersion != "" {
+		output.FormatVersion = aws.String(f.FormatVersion)
+	}
+	if f.NextToken != "" {
+		output.NextToken = aws.String(f.NextToken)
+	}
+	if f.Endpoint != "" {
+		output.ResultMetadata.Set(endpointKey{}, f.Endpoint)
+	}
+	return output, nil
+}
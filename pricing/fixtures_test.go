@@ -0,0 +1,142 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+)
+
+func TestRecordReplay(t *testing.T) {
+	endpointAPIs(t, nil, allCapabilityProducts("eu-west-1"))
+	dir := filepath.Join(t.TempDir(), "fixtures")
+
+	recorded, err := APISource(NewRecorder(NewClient(Credentials{}), dir), nil).Lookup(context.Background(), "eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := APISource(NewReplayer(dir), nil).Lookup(context.Background(), "eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range Fields() {
+		want, got := recorded.Provenance[f], replayed.Provenance[f]
+		if replayed.Get(f) != recorded.Get(f) || got.UsageType != want.UsageType || got.Unit != want.Unit || got.Endpoint != want.Endpoint {
+			t.Errorf("%s: replayed %v %+v, recorded %v %+v", f, replayed.Get(f), got, recorded.Get(f), want)
+		}
+	}
+	if recorded.Provenance[FieldArgoCDBasePerHour].Endpoint != "us-east-1" {
+		t.Errorf("expected the serving endpoint recorded, got %+v", recorded.Provenance[FieldArgoCDBasePerHour])
+	}
+}
+
+// pagedAPI answers every call with two pages.
+type pagedAPI struct{}
+
+func (pagedAPI) GetProducts(_ context.Context, params *pricing.GetProductsInput, _ ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	if params.NextToken == nil {
+		return &pricing.GetProductsOutput{PriceList: []string{`{"page": 1}`}, NextToken: aws.String("t2"), FormatVersion: aws.String("aws_v1")}, nil
+	}
+	return &pricing.GetProductsOutput{PriceList: []string{`{"page": 2}`}}, nil
+}
+
+func TestReplayPages(t *testing.T) {
+	dir := t.TempDir()
+	rec := NewRecorder(pagedAPI{}, dir)
+	filters := []types.Filter{
+		{Type: types.FilterTypeTermMatch, Field: aws.String("usagetype"), Value: aws.String("x")},
+		{Type: types.FilterTypeTermMatch, Field: aws.String("regionCode"), Value: aws.String("b")},
+		{Type: types.FilterTypeTermMatch, Field: aws.String("regionCode"), Value: aws.String("a")},
+		{Type: types.FilterTypeAnyOf, Field: aws.String("regionCode"), Value: aws.String("a")},
+	}
+	input := &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS"), Filters: filters}
+	for _, token := range []*string{nil, aws.String("t2")} {
+		input.NextToken = token
+		if _, err := rec.GetProducts(context.Background(), input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Calls match whatever the order of their filters.
+	reversed := make([]types.Filter, len(filters))
+	for i, f := range filters {
+		reversed[len(filters)-1-i] = f
+	}
+	replay := NewReplayer(dir)
+	first, err := replay.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS"), Filters: reversed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first.PriceList, []string{`{"page":1}`}) || aws.ToString(first.NextToken) != "t2" || aws.ToString(first.FormatVersion) != "aws_v1" || servedBy(first) != "" {
+		t.Errorf("unexpected first page %+v", first)
+	}
+	second, err := replay.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS"), Filters: filters, NextToken: first.NextToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(second.PriceList, []string{`{"page":2}`}) || second.NextToken != nil || second.FormatVersion != nil {
+		t.Errorf("unexpected second page %+v", second)
+	}
+
+	_, err = replay.GetProducts(context.Background(), &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS"), Filters: filters[:1], NextToken: aws.String("t3")})
+	if !errors.Is(err, ErrNoFixture) || !strings.Contains(err.Error(), "for AmazonEKS usagetype=x page t3 in "+dir) {
+		t.Errorf("expected ErrNoFixture naming the call, got %v", err)
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	dir := t.TempDir()
+	replay := NewReplayer(dir)
+	input := &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")}
+	path := newFixtureRequest(input).path(dir)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := replay.GetProducts(canceled, input); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context's error, got %v", err)
+	}
+
+	_ = os.WriteFile(path, []byte("{"), 0o644)
+	if _, err := replay.GetProducts(context.Background(), input); err == nil || !strings.Contains(err.Error(), "reading fixture for AmazonEKS") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+
+	_ = os.Remove(path)
+	_ = os.Mkdir(path, 0o755)
+	if _, err := replay.GetProducts(context.Background(), input); err == nil || errors.Is(err, ErrNoFixture) {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
+
+func TestRecorderErrors(t *testing.T) {
+	input := &pricing.GetProductsInput{ServiceCode: aws.String("AmazonEKS")}
+
+	// Failed calls are returned, and not recorded.
+	dir := t.TempDir()
+	failed := errors.New("throttled")
+	if _, err := NewRecorder(&mockPricingAPI{err: failed}, dir).GetProducts(context.Background(), input); !errors.Is(err, failed) {
+		t.Errorf("expected the call's error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected nothing recorded, got %v", entries)
+	}
+
+	invalid := &mockPricingAPI{responses: map[string]*pricing.GetProductsOutput{"AmazonEKS": {PriceList: []string{"{"}}}}
+	if _, err := NewRecorder(invalid, dir).GetProducts(context.Background(), input); err == nil || !strings.Contains(err.Error(), "recording AmazonEKS") {
+		t.Errorf("expected an invalid page to fail, got %v", err)
+	}
+
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	_ = os.WriteFile(blocker, nil, 0o644)
+	if _, err := NewRecorder(&mockPricingAPI{}, filepath.Join(blocker, "sub")).GetProducts(context.Background(), input); err == nil {
+		t.Error("expected an unwritable directory to fail the call")
+	}
+}
@@ -0,0 +1,171 @@
+package pricing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Snapshot is one entry in a region's rate history.
+type Snapshot struct {
+	Rates     RateSheet `json:"rates"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// RateChange records a rate that differs between two snapshots.
+type RateChange struct {
+	Field Field
+	From  float64
+	To    float64
+
+	// At is when the snapshot holding the new value was fetched. Zero for
+	// changes returned by CompareRates.
+	At time.Time
+}
+
+// Percent returns the change relative to the old rate, e.g. 8 for +8%.
+func (c RateChange) Percent() float64 {
+	return (c.To - c.From) / c.From * 100
+}
+
+func (c *Cache) historyPath(region string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("history-%s.jsonl", region))
+}
+
+// History returns the snapshots recorded for region, oldest first. Every
+// Save that changes a region's rates appends a snapshot; the history is
+// never rewritten. A region without history returns no snapshots and no
+// error. Lines that cannot be parsed are skipped.
+func (c *Cache) History(region string) ([]Snapshot, error) {
+	f, err := os.Open(c.historyPath(region))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading rate history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var snapshots []Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var s Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			continue
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading rate history: %w", err)
+	}
+	return snapshots, nil
+}
+
+// appendHistory adds s to the region's history unless its rates equal those
+// of the latest snapshot.
+func (c *Cache) appendHistory(region string, s Snapshot) error {
+	history, err := c.History(region)
+	if err != nil {
+		return err
+	}
+	if n := len(history); n > 0 && history[n-1].Rates.Equal(s.Rates) {
+		return nil
+	}
+
+	data, err := cacheJSONMarshal(s)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(c.historyPath(region), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return errors.Join(err, f.Close())
+}
+
+// SnapshotAt returns the latest snapshot in history fetched at or before t:
+// the rates that applied at t. It reports false if history starts after t.
+func SnapshotAt(history []Snapshot, t time.Time) (Snapshot, bool) {
+	var found Snapshot
+	ok := false
+	for _, s := range history {
+		if s.FetchedAt.After(t) {
+			continue
+		}
+		if !ok || !s.FetchedAt.Before(found.FetchedAt) {
+			found, ok = s, true
+		}
+	}
+	return found, ok
+}
+
+// CompareRates returns the fields whose value differs between from and to,
+// in declaration order. Fields that are zero in either are skipped.
+func CompareRates(from, to Rates) []RateChange {
+	var changes []RateChange
+	for _, f := range Fields() {
+		a, b := from.Get(f), to.Get(f)
+		if a <= 0 || b <= 0 || a == b {
+			continue
+		}
+		changes = append(changes, RateChange{Field: f, From: a, To: b})
+	}
+	return changes
+}
+
+// Changes returns every change between consecutive snapshots in history,
+// oldest first.
+func Changes(history []Snapshot) []RateChange {
+	var changes []RateChange
+	for i := 1; i < len(history); i++ {
+		for _, c := range CompareRates(history[i-1].Rates.Rates, history[i].Rates.Rates) {
+			c.At = history[i].FetchedAt
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// HistorySource returns a source that answers with the rates recorded in
+// c's history at asOf, so that an old scenario can be re-run against the
+// rates that applied then. Regions without history before asOf supply
+// nothing.
+//
+// Rates are attributed to the history source, keeping the usage type and
+// fetch time recorded with them. Rates that were recorded as built-in
+// defaults keep the defaults source.
+func HistorySource(c *Cache, asOf time.Time) Source {
+	return NewSource(SourceHistory, func(_ context.Context, region string) (RateSheet, error) {
+		history, err := c.History(region)
+		if err != nil {
+			return RateSheet{}, err
+		}
+		s, ok := SnapshotAt(history, asOf)
+		if !ok {
+			return RateSheet{}, nil
+		}
+
+		rates := s.Rates
+		rates.Provenance = make(map[Field]Provenance, len(Fields()))
+		for _, f := range Fields() {
+			p := s.Rates.Provenance[f]
+			if p.Source != SourceDefaults {
+				p.Source = SourceHistory
+				if p.FetchedAt.IsZero() {
+					p.FetchedAt = s.FetchedAt
+				}
+			}
+			rates.Provenance[f] = p
+		}
+		return rates, nil
+	})
+}
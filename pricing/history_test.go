@@ -0,0 +1,258 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var historyStart = time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+// saveAt saves rates to c as if fetched at t.
+func saveAt(t *testing.T, c *Cache, region string, at time.Time, rates RateSheet) {
+	t.Helper()
+	c.now = func() time.Time { return at }
+	if err := c.SaveSheet(region, rates); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCacheHistory(t *testing.T) {
+	c := newTestCache(t)
+
+	first := RateSheet{Rates: DefaultRates()}
+	second := first
+	second.ArgoCDBasePerHour = 0.027
+
+	saveAt(t, c, "us-east-1", historyStart, first)
+	saveAt(t, c, "us-east-1", historyStart.Add(24*time.Hour), first)
+	saveAt(t, c, "us-east-1", historyStart.Add(48*time.Hour), second)
+	saveAt(t, c, "eu-west-1", historyStart, first)
+
+	history, err := c.History("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected unchanged rates not to be recorded again, got %d snapshots", len(history))
+	}
+	if !history[0].FetchedAt.Equal(historyStart) || history[0].Rates.Rates != first.Rates {
+		t.Errorf("unexpected first snapshot %+v", history[0])
+	}
+	if !history[1].FetchedAt.Equal(historyStart.Add(48*time.Hour)) || history[1].Rates.Rates != second.Rates {
+		t.Errorf("unexpected second snapshot %+v", history[1])
+	}
+
+	if other, _ := c.History("eu-west-1"); len(other) != 1 {
+		t.Errorf("expected regions to have separate histories, got %d snapshots", len(other))
+	}
+}
+
+func TestCacheHistoryMissing(t *testing.T) {
+	history, err := newTestCache(t).History("us-east-1")
+	if err != nil || history != nil {
+		t.Errorf("expected no history and no error, got %v, %v", history, err)
+	}
+}
+
+func TestCacheHistorySkipsCorruptLines(t *testing.T) {
+	c := newTestCache(t)
+	saveAt(t, c, "us-east-1", historyStart, RateSheet{Rates: DefaultRates()})
+
+	f, err := os.OpenFile(c.historyPath("us-east-1"), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("{truncated\n")
+	_ = f.Close()
+
+	history, err := c.History("us-east-1")
+	if err != nil || len(history) != 1 {
+		t.Errorf("expected the corrupt line skipped, got %d snapshots, %v", len(history), err)
+	}
+}
+
+func TestCacheHistoryErrors(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&Cache{dir: blocker, now: time.Now}).History("us-east-1"); err == nil || !strings.HasPrefix(err.Error(), "reading rate history: ") {
+		t.Errorf("expected an open error, got %v", err)
+	}
+
+	c := newTestCache(t)
+	if err := os.Mkdir(c.historyPath("us-east-1"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.History("us-east-1"); err == nil || !strings.HasPrefix(err.Error(), "reading rate history: ") {
+		t.Errorf("expected a read error, got %v", err)
+	}
+	if err := c.Save("us-east-1", DefaultRates()); err == nil {
+		t.Error("Save should report a history it cannot read")
+	}
+}
+
+func TestCacheSaveHistoryWriteError(t *testing.T) {
+	c := newTestCache(t)
+	if err := os.Symlink(filepath.Join(c.dir, "missing", "history.jsonl"), c.historyPath("us-east-1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save("us-east-1", DefaultRates()); err == nil {
+		t.Error("expected an error appending to the history")
+	}
+}
+
+func TestCacheSaveHistoryMarshalError(t *testing.T) {
+	orig := cacheJSONMarshal
+	defer func() { cacheJSONMarshal = orig }()
+
+	calls := 0
+	cacheJSONMarshal = func(v any) ([]byte, error) {
+		calls++
+		if calls > 1 {
+			return nil, fmt.Errorf("marshal error")
+		}
+		return orig(v)
+	}
+
+	c := newTestCache(t)
+	if err := c.Save("us-east-1", DefaultRates()); err == nil || err.Error() != "marshal error" {
+		t.Errorf("expected the history marshal error, got %v", err)
+	}
+	if c.Load("us-east-1") == nil {
+		t.Error("the cache entry should still be written")
+	}
+}
+
+func TestSnapshotAt(t *testing.T) {
+	history := []Snapshot{
+		{Rates: RateSheet{Rates: Rates{ArgoCDBasePerHour: 1}}, FetchedAt: historyStart},
+		{Rates: RateSheet{Rates: Rates{ArgoCDBasePerHour: 2}}, FetchedAt: historyStart.Add(48 * time.Hour)},
+	}
+
+	tests := []struct {
+		at   time.Time
+		want float64
+		ok   bool
+	}{
+		{historyStart.Add(-time.Second), 0, false},
+		{historyStart, 1, true},
+		{historyStart.Add(47 * time.Hour), 1, true},
+		{historyStart.Add(48 * time.Hour), 2, true},
+		{historyStart.Add(1000 * time.Hour), 2, true},
+	}
+	for _, tt := range tests {
+		s, ok := SnapshotAt(history, tt.at)
+		if ok != tt.ok || s.Rates.ArgoCDBasePerHour != tt.want {
+			t.Errorf("%v: expected %v (%v), got %v (%v)", tt.at, tt.want, tt.ok, s.Rates.ArgoCDBasePerHour, ok)
+		}
+	}
+
+	// Out-of-order snapshots, e.g. after the clock was changed, still
+	// resolve to the latest fetch.
+	reversed := []Snapshot{history[1], history[0]}
+	if s, _ := SnapshotAt(reversed, historyStart.Add(1000*time.Hour)); s.Rates.ArgoCDBasePerHour != 2 {
+		t.Errorf("expected the latest snapshot, got %v", s.Rates.ArgoCDBasePerHour)
+	}
+}
+
+func TestCompareRates(t *testing.T) {
+	from := Rates{ArgoCDBasePerHour: 0.025, ACKBasePerHour: 0.03, EBSGBMonth: 0.08}
+	to := Rates{ArgoCDBasePerHour: 0.027, ACKBasePerHour: 0.03, KroBasePerHour: 0.03, EBSGBMonth: 0.07}
+
+	changes := CompareRates(from, to)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Field != FieldArgoCDBasePerHour || changes[0].From != 0.025 || changes[0].To != 0.027 {
+		t.Errorf("unexpected change %+v", changes[0])
+	}
+	if math.Abs(changes[0].Percent()-8) > 1e-9 {
+		t.Errorf("expected +8%%, got %v", changes[0].Percent())
+	}
+	if changes[1].Field != FieldEBSGBMonth || math.Abs(changes[1].Percent()+12.5) > 1e-9 {
+		t.Errorf("unexpected change %+v (%v%%)", changes[1], changes[1].Percent())
+	}
+}
+
+func TestChanges(t *testing.T) {
+	history := []Snapshot{
+		{Rates: RateSheet{Rates: Rates{ArgoCDBasePerHour: 0.025, EBSGBMonth: 0.08}}, FetchedAt: historyStart},
+		{Rates: RateSheet{Rates: Rates{ArgoCDBasePerHour: 0.027, EBSGBMonth: 0.08}}, FetchedAt: historyStart.Add(24 * time.Hour)},
+		{Rates: RateSheet{Rates: Rates{ArgoCDBasePerHour: 0.027, EBSGBMonth: 0.09}}, FetchedAt: historyStart.Add(48 * time.Hour)},
+	}
+
+	changes := Changes(history)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Field != FieldArgoCDBasePerHour || !changes[0].At.Equal(history[1].FetchedAt) {
+		t.Errorf("unexpected first change %+v", changes[0])
+	}
+	if changes[1].Field != FieldEBSGBMonth || !changes[1].At.Equal(history[2].FetchedAt) {
+		t.Errorf("unexpected second change %+v", changes[1])
+	}
+	if Changes(history[:1]) != nil {
+		t.Error("a single snapshot has no changes")
+	}
+}
+
+func TestHistorySource(t *testing.T) {
+	c := newTestCache(t)
+	ctx := context.Background()
+
+	fetched := defaultRatesFor("us-east-1")
+	fetched.setWithProvenance(FieldArgoCDBasePerHour, 0.025, Provenance{Source: SourceAPI, Region: "us-east-1", UsageType: "USE1-ArgoCD"})
+	saveAt(t, c, "us-east-1", historyStart, fetched)
+
+	later := fetched
+	later.Provenance = nil
+	later.ArgoCDBasePerHour = 0.027
+	saveAt(t, c, "us-east-1", historyStart.Add(72*time.Hour), later)
+
+	src := HistorySource(c, historyStart.Add(24*time.Hour))
+	if src.Name() != SourceHistory {
+		t.Errorf("unexpected name %q", src.Name())
+	}
+
+	rates, err := src.Lookup(ctx, "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rates.ArgoCDBasePerHour != 0.025 {
+		t.Errorf("expected the rate that applied then, got %v", rates.ArgoCDBasePerHour)
+	}
+	p := rates.Provenance[FieldArgoCDBasePerHour]
+	if p.Source != SourceHistory || p.UsageType != "USE1-ArgoCD" || !p.FetchedAt.Equal(historyStart) {
+		t.Errorf("unexpected provenance %+v", p)
+	}
+	if rates.Provenance[FieldKroBasePerHour].Source != SourceDefaults {
+		t.Errorf("defaults should stay defaults, got %+v", rates.Provenance[FieldKroBasePerHour])
+	}
+
+	rates, _ = HistorySource(c, historyStart.Add(100*time.Hour)).Lookup(ctx, "us-east-1")
+	if rates.ArgoCDBasePerHour != 0.027 || !rates.Provenance[FieldArgoCDBasePerHour].FetchedAt.Equal(historyStart.Add(72*time.Hour)) {
+		t.Errorf("expected the later snapshot, got %v %+v", rates.ArgoCDBasePerHour, rates.Provenance[FieldArgoCDBasePerHour])
+	}
+
+	if rates, err := src.Lookup(ctx, "eu-west-1"); err != nil || len(rates.Provenance) != 0 {
+		t.Errorf("a region without history should supply nothing, got %v, %v", rates.Provenance, err)
+	}
+	if rates, _ := HistorySource(c, historyStart.Add(-time.Hour)).Lookup(ctx, "us-east-1"); len(rates.Provenance) != 0 {
+		t.Errorf("nothing was recorded before the history started, got %v", rates.Provenance)
+	}
+
+	if err := os.Mkdir(c.historyPath("eu-west-1"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Lookup(ctx, "eu-west-1"); err == nil {
+		t.Error("expected the history read error")
+	}
+}
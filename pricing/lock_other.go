@@ -0,0 +1,10 @@
+//go:build !unix
+
+package pricing
+
+import "os"
+
+// lockFile is a no-op where flock is unavailable. Cache files are still
+// replaced atomically, but concurrent processes may interleave history
+// appends.
+var lockFile = func(*os.File) error { return nil }
@@ -0,0 +1,14 @@
+//go:build unix
+
+package pricing
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive advisory lock on f, waiting until it is
+// free. Swapped out in tests.
+var lockFile = func(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
@@ -0,0 +1,334 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrRegionNotInOffers is returned by Offers.Rates for a region that none of
+// the loaded offer files contain.
+var ErrRegionNotInOffers = errors.New("region not found in offer files")
+
+// offerRule describes how to recognise one rate in an AWS Price List bulk
+// offer file. A product matches when its servicecode and every attribute in
+// attrs match, and its usage type ends in suffix (any usage type if empty).
+type offerRule struct {
+	field   Field
+	service string
+	attrs   map[string]string
+	suffix  string
+}
+
+// offerRules mirrors the Pricing API queries made by FetchRatesWithClient.
+var offerRules = []offerRule{
+	{FieldArgoCDBasePerHour, "AmazonEKS", nil, allCapSuffixes[0].suffixes.baseSuffix},
+	{FieldArgoCDAppPerHour, "AmazonEKS", nil, allCapSuffixes[0].suffixes.resourceSuffix},
+	{FieldACKBasePerHour, "AmazonEKS", nil, allCapSuffixes[1].suffixes.baseSuffix},
+	{FieldACKResourcePerHour, "AmazonEKS", nil, allCapSuffixes[1].suffixes.resourceSuffix},
+	{FieldKroBasePerHour, "AmazonEKS", nil, allCapSuffixes[2].suffixes.baseSuffix},
+	{FieldKroRGDPerHour, "AmazonEKS", nil, allCapSuffixes[2].suffixes.resourceSuffix},
+	{FieldFargateVCPUPerHour, "AmazonECS", map[string]string{"productFamily": "Compute", "cputype": "perCPU"}, fargateVCPUSuffix},
+	{FieldFargateMemGBPerHour, "AmazonECS", map[string]string{"productFamily": "Compute", "memorytype": "perGB"}, fargateMemSuffix},
+	{FieldALBPerHour, "AWSELB", map[string]string{"productFamily": "Load Balancer-Application"}, albHoursSuffix},
+	{FieldALBLCUPerHour, "AWSELB", map[string]string{"productFamily": "Load Balancer-Application"}, albLCUSuffix},
+	{FieldEBSGBMonth, "AmazonEC2", map[string]string{"productFamily": "Storage", "volumeApiName": "gp3"}, ""},
+	{FieldCloudWatchLogsPerGB, "AmazonCloudWatch", map[string]string{"productFamily": "Data Payload"}, cloudWatchLogsSuffix},
+}
+
+// Usage type suffixes for on-demand x86 Fargate in bulk offer files. The API
+// path filters by attribute instead; bulk files also contain Spot, ARM and
+// Windows products with the same attributes.
+const (
+	fargateVCPUSuffix = "Fargate-vCPU-Hours:perCPU"
+	fargateMemSuffix  = "Fargate-GB-Hours"
+)
+
+func (rule offerRule) matches(attrs map[string]string) bool {
+	if attrs["servicecode"] != rule.service {
+		return false
+	}
+	for k, v := range rule.attrs {
+		if attrs[k] != v {
+			return false
+		}
+	}
+	return rule.suffix == "" || usageTypeHasSuffix(attrs["usagetype"], rule.suffix)
+}
+
+// Offers holds rates read from one or more AWS Price List bulk offer files
+// (for example the AmazonEKS and AmazonECS offers, or region-specific
+// slices of them), keyed by region. It implements Provider and Source.
+type Offers struct {
+	// regions maps region code -> the rates found for it, with provenance.
+	regions map[string]*RateSheet
+}
+
+// LoadOffers reads and merges the offer files at the given paths.
+func LoadOffers(paths ...string) (*Offers, error) {
+	o := &Offers{regions: make(map[string]*RateSheet)}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening offer file: %w", err)
+		}
+		err = o.parse(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return o, nil
+}
+
+// ParseOffers reads and merges offer files from readers.
+func ParseOffers(readers ...io.Reader) (*Offers, error) {
+	o := &Offers{regions: make(map[string]*RateSheet)}
+	for _, r := range readers {
+		if err := o.parse(r); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Regions returns the sorted region codes with at least one rate.
+func (o *Offers) Regions() []string {
+	regions := make([]string, 0, len(o.regions))
+	for region := range o.regions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// Rates returns DefaultRates overlaid with every rate the offer files
+// contain for the region, or ErrRegionNotInOffers. In regions not priced
+// in USD, only the offer files' rates are returned.
+func (o *Offers) Rates(_ context.Context, region string) (Rates, error) {
+	rates := baseRatesFor(region)
+	if !o.apply(region, &rates) {
+		return rates.Rates, fmt.Errorf("%s: %w", region, ErrRegionNotInOffers)
+	}
+	return rates.Rates, nil
+}
+
+// Name returns "offers", so Offers can be used as a chain Source.
+func (o *Offers) Name() string { return SourceOffers }
+
+// Lookup returns only the rates the offer files contain for the region, and
+// the zero RateSheet for a region they do not cover. Offers implements
+// Source.
+func (o *Offers) Lookup(_ context.Context, region string) (RateSheet, error) {
+	var rates RateSheet
+	o.apply(region, &rates)
+	return rates, nil
+}
+
+// apply writes the region's rates into rates and reports whether the region
+// was present.
+func (o *Offers) apply(region string, rates *RateSheet) bool {
+	found, ok := o.regions[region]
+	if !ok {
+		return false
+	}
+	rates.Currency = found.Currency
+	for f, p := range found.Provenance {
+		rates.setTiered(f, found.Get(f), found.Tiers[f], p)
+	}
+	return true
+}
+
+// Populate writes every region in the offer files to the cache. Rates
+// already cached for a region are kept for any field the offer files do not
+// cover, so the EKS and ECS offers can be imported separately. Returns the
+// regions written.
+func (o *Offers) Populate(c *Cache) ([]string, error) {
+	regions := o.Regions()
+	for _, region := range regions {
+		rates := baseRatesFor(region)
+		if cached := c.load(region); cached != nil {
+			rates = cached.Rates
+		}
+		o.apply(region, &rates)
+		if err := c.SaveSheet(region, rates); err != nil {
+			return nil, fmt.Errorf("caching %s: %w", region, err)
+		}
+	}
+	return regions, nil
+}
+
+// bulkProduct is one entry of the products map in a bulk offer file.
+type bulkProduct struct {
+	ProductFamily string            `json:"productFamily"`
+	Attributes    map[string]string `json:"attributes"`
+}
+
+// matchedSKU records which rule a product matched and where.
+type matchedSKU struct {
+	rule   offerRule
+	region string
+	doc    productDoc
+}
+
+// parse streams a bulk offer file. Offer files can be hundreds of megabytes,
+// so only products that match an offerRule are kept, and only their OnDemand
+// terms are decoded. Products appear before terms in AWS offer files.
+func (o *Offers) parse(r io.Reader) error {
+	matched, published, err := scanOffer(json.NewDecoder(r))
+	if err != nil {
+		return fmt.Errorf("parsing offer file: %w", err)
+	}
+
+	for _, m := range matched {
+		q, err := quoteFromDoc(m.doc)
+		if err != nil || q.rate <= 0 {
+			continue
+		}
+		if o.regions[m.region] == nil {
+			o.regions[m.region] = &RateSheet{Rates: Rates{Currency: regionCurrency(m.region)}}
+		}
+		o.regions[m.region].setTiered(m.rule.field, q.rate, q.tiers, Provenance{
+			Source:    SourceOffers,
+			Region:    m.region,
+			UsageType: q.usageType,
+			Unit:      q.unit,
+			FetchedAt: published,
+		})
+	}
+
+	return nil
+}
+
+// scanOffer returns the matching products of an offer file with their
+// OnDemand terms attached, and the file's publication date if it has one.
+func scanOffer(dec *json.Decoder) (map[string]*matchedSKU, time.Time, error) {
+	matched := make(map[string]*matchedSKU)
+	claimed := make(map[string]bool) // region + field already matched; the first product wins
+	var published time.Time
+	err := eachEntry(dec, func(key string) error {
+		switch key {
+		case "publicationDate":
+			var date string
+			if err := dec.Decode(&date); err != nil {
+				return err
+			}
+			published, _ = time.Parse(time.RFC3339, date)
+			return nil
+		case "products":
+			return eachEntry(dec, func(sku string) error {
+				var p bulkProduct
+				if err := dec.Decode(&p); err != nil {
+					return err
+				}
+				if m := matchProduct(p); m != nil && !claimed[m.region+"/"+string(m.rule.field)] {
+					claimed[m.region+"/"+string(m.rule.field)] = true
+					matched[sku] = m
+				}
+				return nil
+			})
+		case "terms":
+			return eachEntry(dec, func(termType string) error {
+				if termType != "OnDemand" {
+					return skipValue(dec)
+				}
+				return eachEntry(dec, func(sku string) error {
+					m, ok := matched[sku]
+					if !ok {
+						return skipValue(dec)
+					}
+					return dec.Decode(&m.doc.Terms.OnDemand)
+				})
+			})
+		default:
+			return skipValue(dec)
+		}
+	})
+	return matched, published, err
+}
+
+// matchProduct returns the rule a product satisfies, or nil.
+func matchProduct(p bulkProduct) *matchedSKU {
+	region := p.Attributes["regionCode"]
+	if region == "" {
+		return nil
+	}
+
+	attrs := make(map[string]string, len(p.Attributes)+1)
+	for k, v := range p.Attributes {
+		attrs[k] = v
+	}
+	if p.ProductFamily != "" {
+		attrs["productFamily"] = p.ProductFamily
+	}
+
+	for _, rule := range offerRules {
+		if rule.matches(attrs) {
+			m := &matchedSKU{rule: rule, region: region}
+			m.doc.Product.Attributes = attrs
+			return m
+		}
+	}
+	return nil
+}
+
+// usageTypeHasSuffix reports whether usageType is suffix, optionally
+// preceded by a single region prefix such as "USE1-". Longer prefixes such
+// as "USE1-SpotUsage-" denote a different product and do not match.
+func usageTypeHasSuffix(usageType, suffix string) bool {
+	prefix, ok := strings.CutSuffix(usageType, suffix)
+	if !ok {
+		return false
+	}
+	return prefix == "" || (strings.HasSuffix(prefix, "-") && strings.Count(prefix, "-") == 1)
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+func readKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	// Inside an object the decoder only yields string keys or the closing
+	// delimiter, which eachEntry consumes via More.
+	return tok.(string), nil
+}
+
+// eachEntry walks a JSON object, calling fn with each key. fn must consume
+// the value.
+func eachEntry(dec *json.Decoder, fn func(key string) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return err
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func skipValue(dec *json.Decoder) error {
+	var v json.RawMessage
+	return dec.Decode(&v)
+}
@@ -0,0 +1,304 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// offerProduct is one product in a test offer file.
+type offerProduct struct {
+	sku, family, rate, unit string
+	attrs                   map[string]string
+}
+
+// offerFileJSON builds a bulk offer file in the AWS Price List format.
+func offerFileJSON(offerCode string, products ...offerProduct) string {
+	var prods, terms []string
+	for _, p := range products {
+		attrs := []string{fmt.Sprintf(`"servicecode": %q`, offerCode)}
+		for k, v := range p.attrs {
+			attrs = append(attrs, fmt.Sprintf(`%q: %q`, k, v))
+		}
+		prods = append(prods, fmt.Sprintf(`%q: {"sku": %q, "productFamily": %q, "attributes": {%s}}`,
+			p.sku, p.sku, p.family, strings.Join(attrs, ", ")))
+		terms = append(terms, fmt.Sprintf(`%q: {%q: {"offerTermCode": "JRTCKXETXF", "priceDimensions": {"d1": {"unit": %q, "pricePerUnit": {"USD": %q}}}}}`,
+			p.sku, p.sku+".JRTCKXETXF", p.unit, p.rate))
+	}
+
+	return fmt.Sprintf(`{
+		"formatVersion": "v1.0",
+		"disclaimer": "test",
+		"offerCode": %q,
+		"version": "20261001000000",
+		"publicationDate": "2026-10-01T00:00:00Z",
+		"products": {%s},
+		"terms": {
+			"OnDemand": {%s},
+			"Reserved": {"IGNORED": {"x": {"priceDimensions": {}}}}
+		},
+		"attributesList": {}
+	}`, offerCode, strings.Join(prods, ",\n"), strings.Join(terms, ",\n"))
+}
+
+func eksOffer() string {
+	usage := func(region, prefix, suffix string) map[string]string {
+		return map[string]string{"regionCode": region, "usagetype": prefix + suffix}
+	}
+	return offerFileJSON("AmazonEKS",
+		offerProduct{"A1", "EKS Capabilities", "0.031", "Hours", usage("us-east-1", "USE1-", "AmazonEKSCapabilities-ArgoCD-Hours:perCapability")},
+		offerProduct{"A2", "EKS Capabilities", "0.0016", "Hours", usage("us-east-1", "USE1-", "AmazonEKSCapabilities-ArgoCD-CR-Hours:perCustomResource")},
+		offerProduct{"A3", "EKS Capabilities", "0.006", "Hours", usage("us-east-1", "USE1-", "AmazonEKSCapabilities-ACK-Hours:perCapability")},
+		offerProduct{"B1", "EKS Capabilities", "0.035", "Hours", usage("eu-west-1", "EU-", "AmazonEKSCapabilities-ArgoCD-Hours:perCapability")},
+		offerProduct{"X1", "Compute", "0.10", "Hours", usage("us-east-1", "USE1-", "AmazonEKS-Hours:perCluster")},
+		offerProduct{"X2", "EKS Capabilities", "0.50", "Hours", map[string]string{"usagetype": "AmazonEKSCapabilities-ArgoCD-Hours:perCapability"}},
+	)
+}
+
+func ecsOffer() string {
+	return offerFileJSON("AmazonECS",
+		offerProduct{"F0", "Compute", "0.0000099", "Second", map[string]string{
+			"regionCode": "us-east-1", "cputype": "perCPU", "usagetype": "USE1-SpotUsage-Fargate-vCPU-Hours:perCPU"}},
+		offerProduct{"F1", "Compute", "0.000011244", "Second", map[string]string{
+			"regionCode": "us-east-1", "cputype": "perCPU", "usagetype": "USE1-Fargate-vCPU-Hours:perCPU"}},
+		offerProduct{"F2", "Compute", "0.000001235", "Second", map[string]string{
+			"regionCode": "us-east-1", "memorytype": "perGB", "usagetype": "USE1-Fargate-GB-Hours"}},
+		offerProduct{"F3", "Compute", "0.05", "Hours", map[string]string{
+			"regionCode": "us-east-1", "cputype": "perCPU", "usagetype": "USE1-Fargate-ARM-vCPU-Hours:perCPU"}},
+	)
+}
+
+func TestParseOffersEKS(t *testing.T) {
+	o, err := ParseOffers(strings.NewReader(eksOffer()))
+	if err != nil {
+		t.Fatalf("ParseOffers: %v", err)
+	}
+
+	if got := strings.Join(o.Regions(), ","); got != "eu-west-1,us-east-1" {
+		t.Errorf("Regions: got %q", got)
+	}
+
+	rates, err := o.Rates(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("Rates: %v", err)
+	}
+	if rates.ArgoCDBasePerHour != 0.031 || rates.ArgoCDAppPerHour != 0.0016 || rates.ACKBasePerHour != 0.006 {
+		t.Errorf("unexpected EKS rates: %+v", rates)
+	}
+	// Not in the file: defaults are kept
+	if rates.KroBasePerHour != DefaultRates().KroBasePerHour {
+		t.Errorf("KroBasePerHour should keep default, got %f", rates.KroBasePerHour)
+	}
+
+	sheet, _ := o.Lookup(context.Background(), "us-east-1")
+	p := sheet.Provenance[FieldArgoCDBasePerHour]
+	published := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	if p.Source != SourceOffers || p.UsageType != "USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability" || !p.FetchedAt.Equal(published) {
+		t.Errorf("expected offer provenance with the publication date, got %+v", p)
+	}
+	if _, ok := sheet.Provenance[FieldKroBasePerHour]; ok {
+		t.Errorf("fields not in the file should have no provenance, got %+v", sheet.Provenance)
+	}
+
+	eu, _ := o.Rates(context.Background(), "eu-west-1")
+	if eu.ArgoCDBasePerHour != 0.035 {
+		t.Errorf("eu-west-1 ArgoCDBasePerHour: got %f", eu.ArgoCDBasePerHour)
+	}
+}
+
+func TestParseOffersFargateSkipsSpotAndARM(t *testing.T) {
+	o, err := ParseOffers(strings.NewReader(ecsOffer()))
+	if err != nil {
+		t.Fatalf("ParseOffers: %v", err)
+	}
+
+	rates, _ := o.Rates(context.Background(), "us-east-1")
+	// Per-second prices are converted to per-hour
+	if math.Abs(rates.FargateVCPUPerHour-0.000011244*3600) > 0.0001 {
+		t.Errorf("FargateVCPUPerHour: got %f", rates.FargateVCPUPerHour)
+	}
+	if math.Abs(rates.FargateMemGBPerHour-0.000001235*3600) > 0.0001 {
+		t.Errorf("FargateMemGBPerHour: got %f", rates.FargateMemGBPerHour)
+	}
+}
+
+func TestParseOffersAncillary(t *testing.T) {
+	elb := offerFileJSON("AWSELB",
+		offerProduct{"N1", "Load Balancer-Network", "0.9", "Hrs", map[string]string{"regionCode": "us-east-1", "usagetype": "USE1-LoadBalancerUsage"}},
+		offerProduct{"L1", "Load Balancer-Application", "0.0252", "Hrs", map[string]string{"regionCode": "us-east-1", "usagetype": "USE1-LoadBalancerUsage"}},
+		offerProduct{"L2", "Load Balancer-Application", "0.009", "LCU-Hrs", map[string]string{"regionCode": "us-east-1", "usagetype": "USE1-LCUUsage"}},
+	)
+	ec2 := offerFileJSON("AmazonEC2",
+		offerProduct{"E1", "Storage", "0.10", "GB-Mo", map[string]string{"regionCode": "us-east-1", "volumeApiName": "gp2"}},
+		offerProduct{"E2", "Storage", "0.088", "GB-Mo", map[string]string{"regionCode": "us-east-1", "volumeApiName": "gp3"}},
+	)
+	cw := offerFileJSON("AmazonCloudWatch",
+		offerProduct{"C1", "Data Payload", "0.57", "GB", map[string]string{"regionCode": "us-east-1", "usagetype": "USE1-DataProcessing-Bytes"}},
+	)
+
+	o, err := ParseOffers(strings.NewReader(elb), strings.NewReader(ec2), strings.NewReader(cw))
+	if err != nil {
+		t.Fatalf("ParseOffers: %v", err)
+	}
+
+	rates, _ := o.Rates(context.Background(), "us-east-1")
+	if rates.ALBPerHour != 0.0252 || rates.ALBLCUPerHour != 0.009 {
+		t.Errorf("ALB: got %f / %f", rates.ALBPerHour, rates.ALBLCUPerHour)
+	}
+	if rates.EBSGBMonth != 0.088 {
+		t.Errorf("EBSGBMonth: got %f", rates.EBSGBMonth)
+	}
+	if rates.CloudWatchLogsPerGB != 0.57 {
+		t.Errorf("CloudWatchLogsPerGB: got %f", rates.CloudWatchLogsPerGB)
+	}
+}
+
+func TestParseOffersFirstProductWins(t *testing.T) {
+	file := offerFileJSON("AmazonEC2",
+		offerProduct{"E1", "Storage", "0.08", "GB-Mo", map[string]string{"regionCode": "us-east-1", "volumeApiName": "gp3"}},
+		offerProduct{"E2", "Storage", "0.99", "GB-Mo", map[string]string{"regionCode": "us-east-1", "volumeApiName": "gp3"}},
+	)
+	o, err := ParseOffers(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates, _ := o.Rates(context.Background(), "us-east-1")
+	if rates.EBSGBMonth != 0.08 {
+		t.Errorf("expected first matching product, got %f", rates.EBSGBMonth)
+	}
+}
+
+func TestParseOffersSkipsUnpricedProducts(t *testing.T) {
+	file := offerFileJSON("AmazonEKS",
+		offerProduct{"A1", "EKS Capabilities", "0", "Hours", map[string]string{"regionCode": "us-east-1", "usagetype": "USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability"}},
+		offerProduct{"A2", "EKS Capabilities", "abc", "Hours", map[string]string{"regionCode": "us-east-1", "usagetype": "USE1-AmazonEKSCapabilities-ACK-Hours:perCapability"}},
+	)
+	o, err := ParseOffers(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(o.Regions()) != 0 {
+		t.Errorf("expected no regions, got %v", o.Regions())
+	}
+}
+
+func TestOffersRatesUnknownRegion(t *testing.T) {
+	o, _ := ParseOffers(strings.NewReader(eksOffer()))
+
+	rates, err := o.Rates(context.Background(), "ap-south-1")
+	if !errors.Is(err, ErrRegionNotInOffers) {
+		t.Errorf("expected ErrRegionNotInOffers, got %v", err)
+	}
+	if rates != DefaultRates() {
+		t.Error("expected default rates for unknown region")
+	}
+}
+
+func TestParseOffersMalformed(t *testing.T) {
+	tests := map[string]string{
+		"not json":       `nope`,
+		"not an object":  `[]`,
+		"truncated":      `{"products": {"A1": {"attributes": {}}`,
+		"bad product":    `{"products": {"A1": []}}`,
+		"bad terms":      `{"terms": {"OnDemand": []}}`,
+		"unclosed terms": `{"products": {}, "terms": {"OnDemand": {`,
+		"bad date":       `{"publicationDate": 5}`,
+	}
+	for name, file := range tests {
+		if _, err := ParseOffers(strings.NewReader(file)); err == nil || !strings.Contains(err.Error(), "parsing offer file") {
+			t.Errorf("%s: expected parse error, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadOffers(t *testing.T) {
+	dir := t.TempDir()
+	eks := filepath.Join(dir, "AmazonEKS.json")
+	ecs := filepath.Join(dir, "AmazonECS.json")
+	_ = os.WriteFile(eks, []byte(eksOffer()), 0o644)
+	_ = os.WriteFile(ecs, []byte(ecsOffer()), 0o644)
+
+	o, err := LoadOffers(eks, ecs)
+	if err != nil {
+		t.Fatalf("LoadOffers: %v", err)
+	}
+	rates, _ := o.Rates(context.Background(), "us-east-1")
+	if rates.ArgoCDBasePerHour != 0.031 || rates.FargateVCPUPerHour == DefaultRates().FargateVCPUPerHour {
+		t.Errorf("expected rates merged from both files: %+v", rates)
+	}
+}
+
+func TestLoadOffersErrors(t *testing.T) {
+	if _, err := LoadOffers(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	_ = os.WriteFile(bad, []byte("{"), 0o644)
+	_, err := LoadOffers(bad)
+	if err == nil || !strings.Contains(err.Error(), "bad.json") {
+		t.Errorf("expected error naming the file, got %v", err)
+	}
+}
+
+func TestOffersPopulate(t *testing.T) {
+	c := &Cache{dir: t.TempDir(), now: time.Now}
+
+	// An existing entry keeps the fields the offer file does not cover.
+	existing := DefaultRates()
+	existing.FargateVCPUPerHour = 0.09
+	_ = c.Save("us-east-1", existing)
+
+	o, _ := ParseOffers(strings.NewReader(eksOffer()))
+	regions, err := o.Populate(c)
+	if err != nil {
+		t.Fatalf("Populate: %v", err)
+	}
+	if strings.Join(regions, ",") != "eu-west-1,us-east-1" {
+		t.Errorf("regions: got %v", regions)
+	}
+
+	use1 := c.Load("us-east-1")
+	if use1 == nil || use1.ArgoCDBasePerHour != 0.031 || use1.FargateVCPUPerHour != 0.09 {
+		t.Errorf("us-east-1 cache: got %+v", use1)
+	}
+	euw1 := c.Load("eu-west-1")
+	if euw1 == nil || euw1.ArgoCDBasePerHour != 0.035 || !euw1.HasAllCapabilityRates() {
+		t.Errorf("eu-west-1 cache: got %+v", euw1)
+	}
+}
+
+func TestOffersPopulateSaveError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	_ = os.WriteFile(file, nil, 0o644)
+	c := &Cache{dir: filepath.Join(file, "sub"), now: time.Now}
+
+	o, _ := ParseOffers(strings.NewReader(eksOffer()))
+	if _, err := o.Populate(c); err == nil {
+		t.Error("expected error when the cache directory cannot be created")
+	}
+}
+
+func TestUsageTypeHasSuffix(t *testing.T) {
+	tests := []struct {
+		usageType string
+		want      bool
+	}{
+		{"Fargate-GB-Hours", true},
+		{"USE1-Fargate-GB-Hours", true},
+		{"USE1-SpotUsage-Fargate-GB-Hours", false},
+		{"USE1Fargate-GB-Hours", false},
+		{"USE1-Fargate-GB-Hours-Extra", false},
+	}
+	for _, tt := range tests {
+		if got := usageTypeHasSuffix(tt.usageType, "Fargate-GB-Hours"); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.usageType, got, tt.want)
+		}
+	}
+}
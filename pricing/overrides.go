@@ -0,0 +1,204 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+)
+
+// OverridesFile is the conventional name of the rate overrides file.
+const OverridesFile = "rate-overrides.json"
+
+// Rate names accepted in an overrides file under a capability key, in
+// addition to the Field names.
+const (
+	overrideBase     = "base"
+	overrideResource = "resource"
+)
+
+// Overrides holds user-maintained rates, such as privately negotiated
+// prices, that take precedence over fetched rates. An overrides file maps a
+// region pattern to a capability (or "*") to rate names and values:
+//
+//	{
+//	  "*":         {"*": {"FargateVCPUPerHour": 0.035}},
+//	  "eu-*":      {"*": {"base": 0.028}},
+//	  "us-east-1": {"ArgoCD": {"base": 0.025, "resource": 0.0012}}
+//	}
+//
+// Region patterns use path.Match syntax. Under a capability, "base" and
+// "resource" name that capability's rates; under "*" they apply to every
+// capability, and any Field name may be used. When several entries set the
+// same rate, the most specific region wins (an exact region, then the
+// longest pattern, then "*"), then the most specific capability.
+// Overrides implements Source.
+type Overrides struct {
+	entries []overrideEntry
+}
+
+// overrideEntry is one rate set by an overrides file.
+type overrideEntry struct {
+	region    string
+	field     Field
+	value     float64
+	capweight int // 2: named capability, 1: Field name under "*", 0: base/resource under "*"
+}
+
+// LoadOverrides reads the overrides file at path. A missing file yields no
+// overrides and no error.
+func LoadOverrides(filename string) (*Overrides, error) {
+	f, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Overrides{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening overrides file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	o, err := ParseOverrides(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return o, nil
+}
+
+// ParseOverrides reads an overrides file from r.
+func ParseOverrides(r io.Reader) (*Overrides, error) {
+	var file map[string]map[string]map[string]float64
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("parsing overrides: %w", err)
+	}
+
+	o := &Overrides{}
+	for region, caps := range file {
+		if _, err := path.Match(region, ""); err != nil {
+			return nil, fmt.Errorf("region %q: %w", region, err)
+		}
+		for capName, rates := range caps {
+			for name, value := range rates {
+				if value <= 0 {
+					return nil, fmt.Errorf("%s/%s/%s: rate must be positive", region, capName, name)
+				}
+				entries, err := expandOverride(capName, name)
+				if err != nil {
+					return nil, fmt.Errorf("%s/%s: %w", region, capName, err)
+				}
+				for _, e := range entries {
+					e.region, e.value = region, value
+					o.entries = append(o.entries, e)
+				}
+			}
+		}
+	}
+	return o, nil
+}
+
+// expandOverride returns the fields a rate name under a capability key sets.
+func expandOverride(capName, name string) ([]overrideEntry, error) {
+	if capName == "*" {
+		if name == overrideBase || name == overrideResource {
+			var entries []overrideEntry
+			for _, cap := range calculator.AllCapabilities {
+				entries = append(entries, overrideEntry{field: capabilityField(cap, name)})
+			}
+			return entries, nil
+		}
+		for _, f := range Fields() {
+			if string(f) == name {
+				return []overrideEntry{{field: f, capweight: 1}}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown rate %q", name)
+	}
+
+	for _, cap := range calculator.AllCapabilities {
+		if !strings.EqualFold(cap.String(), capName) {
+			continue
+		}
+		if name != overrideBase && name != overrideResource {
+			return nil, fmt.Errorf("unknown rate %q: use %q or %q, or a rate name under \"*\"", name, overrideBase, overrideResource)
+		}
+		return []overrideEntry{{field: capabilityField(cap, name), capweight: 2}}, nil
+	}
+	return nil, fmt.Errorf("unknown capability %q", capName)
+}
+
+func capabilityField(cap calculator.Capability, name string) Field {
+	base, resource := FieldsForCapability(cap)
+	if name == overrideBase {
+		return base
+	}
+	return resource
+}
+
+// regionWeight ranks a region pattern: exact regions first, then longer
+// patterns, with "*" last.
+func regionWeight(pattern string) int {
+	if !strings.ContainsAny(pattern, `*?[\`) {
+		return 1 << 16
+	}
+	if pattern == "*" {
+		return 0
+	}
+	return len(pattern)
+}
+
+// moreSpecific reports whether a should win over b.
+func (a overrideEntry) moreSpecific(b overrideEntry) bool {
+	if wa, wb := regionWeight(a.region), regionWeight(b.region); wa != wb {
+		return wa > wb
+	}
+	if a.capweight != b.capweight {
+		return a.capweight > b.capweight
+	}
+	return a.region < b.region
+}
+
+// Name returns "overrides", so Overrides can be used as a chain Source.
+func (o *Overrides) Name() string { return SourceOverrides }
+
+// Lookup returns the overridden rates for the region, leaving the others
+// zero. Overrides are in the currency of the region's Partition, so a "*"
+// entry sets CNY rates in AWS China.
+func (o *Overrides) Lookup(_ context.Context, region string) (RateSheet, error) {
+	best := make(map[Field]overrideEntry)
+	for _, e := range o.entries {
+		if ok, _ := path.Match(e.region, region); !ok {
+			continue
+		}
+		if cur, seen := best[e.field]; !seen || e.moreSpecific(cur) {
+			best[e.field] = e
+		}
+	}
+
+	rates := RateSheet{Rates: Rates{Currency: regionCurrency(region)}}
+	for f, e := range best {
+		rates.setWithProvenance(f, e.value, Provenance{Source: SourceOverrides, Region: region})
+	}
+	return rates, nil
+}
+
+// overridesFileSource returns a source backed by the overrides file. The
+// file is re-read on every lookup so edits apply on the next region switch;
+// an empty filename supplies nothing.
+func overridesFileSource(filename string) Source {
+	return NewSource(SourceOverrides, func(ctx context.Context, region string) (RateSheet, error) {
+		if filename == "" {
+			return RateSheet{}, nil
+		}
+		o, err := LoadOverrides(filename)
+		if err != nil {
+			return RateSheet{}, err
+		}
+		return o.Lookup(ctx, region)
+	})
+}
@@ -0,0 +1,208 @@
+package pricing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testOverrides = `{
+  "*":         {"*": {"FargateVCPUPerHour": 0.035, "base": 0.02}},
+  "eu-*":      {"*": {"base": 0.028}, "Kro": {"base": 0.027}},
+  "eu-west-*": {"*": {"base": 0.029}},
+  "us-east-1": {"ArgoCD": {"base": 0.025, "resource": 0.0012}, "*": {"ALBPerHour": 0.02}}
+}`
+
+func parseTestOverrides(t *testing.T, s string) *Overrides {
+	t.Helper()
+	o, err := ParseOverrides(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestOverridesLookup(t *testing.T) {
+	o := parseTestOverrides(t, testOverrides)
+
+	tests := []struct {
+		region string
+		want   map[Field]float64
+	}{
+		{"us-east-1", map[Field]float64{
+			FieldArgoCDBasePerHour:  0.025,
+			FieldArgoCDAppPerHour:   0.0012,
+			FieldACKBasePerHour:     0.02,
+			FieldKroBasePerHour:     0.02,
+			FieldFargateVCPUPerHour: 0.035,
+			FieldALBPerHour:         0.02,
+		}},
+		{"eu-central-1", map[Field]float64{
+			FieldArgoCDBasePerHour:  0.028,
+			FieldACKBasePerHour:     0.028,
+			FieldKroBasePerHour:     0.027,
+			FieldFargateVCPUPerHour: 0.035,
+		}},
+		{"eu-west-1", map[Field]float64{
+			FieldArgoCDBasePerHour:  0.029,
+			FieldACKBasePerHour:     0.029,
+			FieldKroBasePerHour:     0.029, // the region is more specific than eu-*'s Kro entry
+			FieldFargateVCPUPerHour: 0.035,
+		}},
+	}
+	for _, tt := range tests {
+		rates, err := o.Lookup(context.Background(), tt.region)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range Fields() {
+			if got := rates.Get(f); got != tt.want[f] {
+				t.Errorf("%s %s: expected %v, got %v", tt.region, f, tt.want[f], got)
+			}
+		}
+		if len(rates.Provenance) != len(tt.want) {
+			t.Errorf("%s: expected provenance for %d fields, got %v", tt.region, len(tt.want), rates.Provenance)
+		}
+		for f, p := range rates.Provenance {
+			if p.Source != SourceOverrides || p.Region != tt.region {
+				t.Errorf("%s %s: unexpected provenance %+v", tt.region, f, p)
+			}
+		}
+	}
+}
+
+func TestOverridesCapabilityCaseInsensitive(t *testing.T) {
+	o := parseTestOverrides(t, `{"*": {"argocd": {"base": 0.03}}}`)
+	rates, _ := o.Lookup(context.Background(), "us-west-2")
+	if rates.ArgoCDBasePerHour != 0.03 {
+		t.Errorf("expected the capability matched regardless of case, got %v", rates.ArgoCDBasePerHour)
+	}
+}
+
+func TestOverridesSamePatternLength(t *testing.T) {
+	// Equally specific patterns are resolved by name so that the outcome
+	// does not depend on map order.
+	o := parseTestOverrides(t, `{"us-eas*": {"*": {"EBSGBMonth": 0.07}}, "us-e*-1": {"*": {"EBSGBMonth": 0.06}}}`)
+	for range 10 {
+		rates, _ := o.Lookup(context.Background(), "us-east-1")
+		if rates.EBSGBMonth != 0.06 {
+			t.Fatalf("expected a stable winner, got %v", rates.EBSGBMonth)
+		}
+	}
+}
+
+func TestParseOverridesErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`not json`, "parsing overrides: "},
+		{`{"us-[": {}}`, `region "us-[": syntax error in pattern`},
+		{`{"*": {"*": {"base": 0}}}`, "*/*/base: rate must be positive"},
+		{`{"*": {"*": {"bogus": 1}}}`, `*/*: unknown rate "bogus"`},
+		{`{"*": {"ArgoCD": {"EBSGBMonth": 1}}}`, `*/ArgoCD: unknown rate "EBSGBMonth": use "base" or "resource", or a rate name under "*"`},
+		{`{"*": {"Flux": {"base": 1}}}`, `*/Flux: unknown capability "Flux"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseOverrides(strings.NewReader(tt.input))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.input, tt.want, err)
+		}
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	dir := t.TempDir()
+
+	o, err := LoadOverrides(filepath.Join(dir, OverridesFile))
+	if err != nil {
+		t.Fatalf("a missing file should not be an error, got %v", err)
+	}
+	if rates, _ := o.Lookup(context.Background(), "us-east-1"); len(rates.Provenance) != 0 {
+		t.Errorf("expected no overrides, got %v", rates.Provenance)
+	}
+
+	path := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(path, []byte(`{"*": {"Flux": {"base": 1}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOverrides(path); err == nil || !strings.HasPrefix(err.Error(), path+": ") {
+		t.Errorf("expected the error prefixed with the path, got %v", err)
+	}
+
+	if _, err := LoadOverrides(dir); err == nil {
+		t.Error("expected an error reading a directory")
+	}
+
+	unreadable := filepath.Join(dir, "file", OverridesFile)
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOverrides(unreadable); err == nil || !strings.HasPrefix(err.Error(), "opening overrides file: ") {
+		t.Errorf("expected an open error, got %v", err)
+	}
+}
+
+func TestOverridesName(t *testing.T) {
+	if got := (&Overrides{}).Name(); got != SourceOverrides {
+		t.Errorf("expected %q, got %q", SourceOverrides, got)
+	}
+}
+
+func TestOverridesFileSource(t *testing.T) {
+	ctx := context.Background()
+	if rates, err := overridesFileSource("").Lookup(ctx, "us-east-1"); err != nil || len(rates.Provenance) != 0 {
+		t.Errorf("an empty path should supply nothing, got %v, %v", rates.Provenance, err)
+	}
+
+	path := filepath.Join(t.TempDir(), OverridesFile)
+	src := overridesFileSource(path)
+	if err := os.WriteFile(path, []byte(`{"*": {"*": {"EBSGBMonth": 0.07}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if rates, err := src.Lookup(ctx, "us-east-1"); err != nil || rates.EBSGBMonth != 0.07 {
+		t.Errorf("expected the override, got %v, %v", rates.EBSGBMonth, err)
+	}
+
+	// Edits apply on the next lookup.
+	if err := os.WriteFile(path, []byte(`{"*": {"*": {"EBSGBMonth": 0.06}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if rates, _ := src.Lookup(ctx, "us-east-1"); rates.EBSGBMonth != 0.06 {
+		t.Errorf("expected the edited override, got %v", rates.EBSGBMonth)
+	}
+
+	if err := os.WriteFile(path, []byte(`nope`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Lookup(ctx, "us-east-1"); err == nil {
+		t.Error("expected the parse error")
+	}
+}
+
+func TestBuildChainOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), OverridesFile)
+	if err := os.WriteFile(path, []byte(`{"us-east-1": {"ArgoCD": {"base": 0.025}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	chain, err := BuildChain(nil, ChainConfig{
+		Cache:         newTestCache(t),
+		Client:        &mockPricingAPI{responses: allCapabilityProducts("us-east-1")},
+		OverridesFile: path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := chain.Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rates.ArgoCDBasePerHour != 0.025 || res.Rates.Provenance[FieldArgoCDBasePerHour].Source != SourceOverrides {
+		t.Errorf("the override should win, got %v %+v", res.Rates.ArgoCDBasePerHour, res.Rates.Provenance[FieldArgoCDBasePerHour])
+	}
+	if res.Rates.Provenance[FieldArgoCDAppPerHour].Source != SourceAPI {
+		t.Errorf("rates without an override should be fetched, got %+v", res.Rates.Provenance[FieldArgoCDAppPerHour])
+	}
+}
@@ -0,0 +1,76 @@
+package pricing
+
+import (
+	"slices"
+	"strings"
+)
+
+// Partition is a group of AWS regions that share a price list: the
+// currency prices are published in and the Pricing API endpoints that
+// serve them.
+type Partition struct {
+	// ID is the AWS partition name: "aws", "aws-us-gov" or "aws-cn".
+	ID string
+
+	// Name is a human-readable name, e.g. "AWS GovCloud (US)".
+	Name string
+
+	// Currency is the ISO 4217 code prices are published in.
+	Currency string
+
+	// Endpoints are the regions of the Pricing API endpoints that serve the
+	// partition's prices, in the order Client fails over to them. GovCloud
+	// prices are published in the commercial price list, so they are served
+	// by the commercial endpoints, with commercial credentials.
+	Endpoints []string
+}
+
+// partitions are the supported partitions, the commercial one first.
+var partitions = []Partition{
+	{ID: "aws", Name: "AWS", Currency: "USD", Endpoints: []string{DefaultEndpoint, "eu-central-1", "ap-south-1"}},
+	{ID: "aws-us-gov", Name: "AWS GovCloud (US)", Currency: "USD", Endpoints: []string{DefaultEndpoint, "eu-central-1", "ap-south-1"}},
+	{ID: "aws-cn", Name: "AWS China", Currency: "CNY", Endpoints: []string{"cn-northwest-1"}},
+}
+
+// regionPrefixes maps region code prefixes to the partitions other than
+// the commercial one.
+var regionPrefixes = map[string]string{
+	"us-gov-": "aws-us-gov",
+	"cn-":     "aws-cn",
+}
+
+// Partitions returns the supported partitions, the commercial one first.
+func Partitions() []Partition {
+	out := make([]Partition, len(partitions))
+	for i, p := range partitions {
+		out[i] = p.clone()
+	}
+	return out
+}
+
+// PartitionOf returns the partition of a region code. Regions of no other
+// partition, including unknown ones, are commercial.
+func PartitionOf(region string) Partition {
+	id := "aws"
+	for prefix, p := range regionPrefixes {
+		if strings.HasPrefix(region, prefix) {
+			id = p
+		}
+	}
+	i := slices.IndexFunc(partitions, func(p Partition) bool { return p.ID == id })
+	return partitions[i].clone()
+}
+
+func (p Partition) clone() Partition {
+	p.Endpoints = slices.Clone(p.Endpoints)
+	return p
+}
+
+// regionCurrency returns the currency the region's rates are in, as stored
+// in Rates.Currency: "" for USD.
+func regionCurrency(region string) string {
+	if c := PartitionOf(region).Currency; c != "USD" {
+		return c
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+// cnyProductJSON is a product in an AWS China region, priced in CNY.
+func cnyProductJSON(region, usagetype, rate string) string {
+	return fmt.Sprintf(`{
+		"product": {"attributes": {"usagetype": %q, "regionCode": %q}},
+		"terms": {"OnDemand": {"o": {"priceDimensions": {
+			"d": {"pricePerUnit": {"CNY": %q}, "unit": "Hour"}
+		}}}}
+	}`, usagetype, region, rate)
+}
+
+func TestPartitionOf(t *testing.T) {
+	tests := []struct {
+		region, id, currency string
+		endpoints            []string
+	}{
+		{"us-east-1", "aws", "USD", []string{"us-east-1", "eu-central-1", "ap-south-1"}},
+		{"eu-west-1", "aws", "USD", []string{"us-east-1", "eu-central-1", "ap-south-1"}},
+		{"us-gov-west-1", "aws-us-gov", "USD", []string{"us-east-1", "eu-central-1", "ap-south-1"}},
+		{"cn-north-1", "aws-cn", "CNY", []string{"cn-northwest-1"}},
+		{"", "aws", "USD", []string{"us-east-1", "eu-central-1", "ap-south-1"}},
+	}
+	for _, tt := range tests {
+		p := PartitionOf(tt.region)
+		if p.ID != tt.id || p.Currency != tt.currency || !reflect.DeepEqual(p.Endpoints, tt.endpoints) {
+			t.Errorf("%q: got %+v", tt.region, p)
+		}
+	}
+
+	PartitionOf("cn-north-1").Endpoints[0] = "changed"
+	if PartitionOf("cn-north-1").Endpoints[0] != "cn-northwest-1" {
+		t.Error("PartitionOf should return a copy")
+	}
+}
+
+func TestPartitions(t *testing.T) {
+	ps := Partitions()
+	var ids []string
+	for _, p := range ps {
+		ids = append(ids, p.ID)
+	}
+	if want := []string{"aws", "aws-us-gov", "aws-cn"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	ps[0].Endpoints[0] = "changed"
+	if Partitions()[0].Endpoints[0] != DefaultEndpoint {
+		t.Error("Partitions should return copies")
+	}
+	if got := (Region{Code: "us-gov-east-1"}).Partition().Name; got != "AWS GovCloud (US)" {
+		t.Errorf("expected the GovCloud partition, got %q", got)
+	}
+}
+
+func TestClientPartitionEndpoints(t *testing.T) {
+	calls := endpointAPIs(t, nil, map[string]*pricing.GetProductsOutput{
+		"AmazonEKS:regionCode=cn-north-1": {PriceList: []string{
+			cnyProductJSON("cn-north-1", "CNN1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability", "0.2"),
+			cnyProductJSON("cn-north-1", "CNN1-AmazonEKSCapabilities-ArgoCD-CR-Hours:perCustomResource", "0.01"),
+		}},
+	})
+
+	c := NewClient(Credentials{})
+	rates, err := APISource(c, nil).Lookup(context.Background(), "cn-north-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, endpoint := range *calls {
+		if endpoint != "cn-northwest-1" {
+			t.Fatalf("expected only the China endpoint called, got %v", *calls)
+		}
+	}
+	if rates.Currency != "CNY" || rates.ArgoCDBasePerHour != 0.2 || rates.Provenance[FieldArgoCDBasePerHour].Endpoint != "cn-northwest-1" {
+		t.Errorf("expected CNY rates from the China endpoint, got %+v", rates)
+	}
+	// The USD defaults are not mixed in.
+	if rates.ACKBasePerHour != 0 || rates.FargateVCPUPerHour != 0 {
+		t.Errorf("expected no USD defaults in CNY rates, got %+v", rates)
+	}
+
+	// GovCloud prices come from the commercial endpoints, and calls across
+	// regions go to the chosen endpoint's partition.
+	*calls = nil
+	_, _ = FetchRatesWithClient(context.Background(), c, "us-gov-west-1")
+	c.SetEndpoint("cn-northwest-1")
+	_, _ = DiscoverRegions(context.Background(), c)
+	if (*calls)[0] != "us-east-1" || (*calls)[len(*calls)-1] != "cn-northwest-1" {
+		t.Errorf("unexpected endpoints called: %v", *calls)
+	}
+}
+
+func TestClientChosenEndpointOtherPartition(t *testing.T) {
+	calls := endpointAPIs(t, map[string]error{"cn-northwest-1": apiError{"AccessDeniedException"}}, nil)
+
+	// An endpoint chosen for one partition is not tried for another.
+	c := NewClient(Credentials{})
+	c.SetEndpoint("cn-northwest-1")
+	if _, err := FetchRatesWithClient(context.Background(), c, "eu-west-1"); err != nil {
+		t.Fatal(err)
+	}
+	if (*calls)[0] != "us-east-1" {
+		t.Errorf("expected the commercial endpoints, got %v", *calls)
+	}
+
+	// An unknown endpoint is taken to be commercial.
+	*calls = nil
+	c.SetEndpoint("eu-west-9")
+	_, _ = DiscoverRegions(context.Background(), c)
+	if (*calls)[0] != "eu-west-9" {
+		t.Errorf("expected the chosen endpoint tried first, got %v", *calls)
+	}
+
+	// China has no other endpoint to fail over to.
+	*calls = nil
+	_, err := FetchRatesWithClient(context.Background(), c, "cn-north-1")
+	if !reflect.DeepEqual(*calls, []string{"cn-northwest-1"}) || Classify(err) != ErrorAuth {
+		t.Errorf("expected one denied call, got %v after %v", err, *calls)
+	}
+}
+
+func TestChainCurrency(t *testing.T) {
+	cny := NewSource("cny", func(context.Context, string) (RateSheet, error) {
+		return RateSheet{Rates: Rates{Currency: "CNY", ArgoCDBasePerHour: 0.2}}, nil
+	})
+	usd := NewSource("usd", func(context.Context, string) (RateSheet, error) {
+		return RateSheet{Rates: Rates{Currency: "USD", ArgoCDAppPerHour: 0.002}}, nil
+	})
+
+	// A China region resolves in CNY, skipping USD rates.
+	res, err := NewChain(usd, cny, DefaultsSource()).Resolve(context.Background(), "cn-north-1")
+	if !errors.Is(err, ErrIncomplete) {
+		t.Errorf("expected the fields without CNY rates missing, got %v", err)
+	}
+	if res.Rates.CurrencyCode() != "CNY" || res.Rates.ArgoCDBasePerHour != 0.2 || res.Rates.ArgoCDAppPerHour != 0 {
+		t.Errorf("unexpected rates %+v", res.Rates)
+	}
+
+	// Without any CNY rate, it resolves in USD.
+	res, err = NewChain(usd, DefaultsSource()).Resolve(context.Background(), "cn-north-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rates.Currency != "" || res.Rates.ArgoCDAppPerHour != 0.002 || res.Rates.ArgoCDBasePerHour != DefaultRates().ArgoCDBasePerHour {
+		t.Errorf("expected USD rates, got %+v", res.Rates)
+	}
+
+	// A commercial region skips CNY rates.
+	res, _ = NewChain(cny, DefaultsSource()).Resolve(context.Background(), "us-east-1")
+	if res.Rates.Currency != "" || res.Rates.ArgoCDBasePerHour != DefaultRates().ArgoCDBasePerHour {
+		t.Errorf("expected the USD defaults, got %+v", res.Rates)
+	}
+}
+
+func TestChainCurrencyLooksUpOnce(t *testing.T) {
+	lookups := 0
+	counted := NewSource("counted", func(context.Context, string) (RateSheet, error) {
+		lookups++
+		return RateSheet{}, errors.New("unavailable")
+	})
+	res, err := NewChain(counted, DefaultsSource()).Resolve(context.Background(), "cn-northwest-1")
+	if err != nil || lookups != 1 || len(res.Errors) != 1 {
+		t.Errorf("expected one lookup and its error, got %d, %v, %v", lookups, res.Errors, err)
+	}
+}
+
+func TestRatesCurrency(t *testing.T) {
+	if (Rates{}).CurrencyCode() != "USD" || (Rates{Currency: "CNY"}).CurrencyCode() != "CNY" {
+		t.Error("unexpected currency codes")
+	}
+	cny, usd := RateSheet{Rates: Rates{Currency: "CNY"}}, RateSheet{Rates: Rates{Currency: "USD"}}
+	if cny.Equal(RateSheet{}) || !usd.Equal(RateSheet{}) {
+		t.Error("Equal should compare currencies")
+	}
+}
+
+func TestOverridesCurrency(t *testing.T) {
+	o, err := ParseOverrides(strings.NewReader(`{"*": {"*": {"EBSGBMonth": 0.5}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cn, _ := o.Lookup(context.Background(), "cn-north-1")
+	us, _ := o.Lookup(context.Background(), "us-east-1")
+	if cn.Currency != "CNY" || us.Currency != "" {
+		t.Errorf("expected overrides in each region's currency, got %q and %q", cn.Currency, us.Currency)
+	}
+}
+
+func TestBaseRatesFor(t *testing.T) {
+	if r := baseRatesFor("cn-north-1"); r.Currency != "CNY" || len(r.Provenance) != 0 {
+		t.Errorf("expected no rates in CNY, got %+v", r)
+	}
+	if r := baseRatesFor("us-gov-west-1"); r.Rates != DefaultRates() {
+		t.Errorf("expected the defaults in GovCloud, got %+v", r)
+	}
+}
@@ -0,0 +1,396 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+)
+
+// Rates holds the hourly pricing rates fetched from AWS.
+type Rates struct {
+	ArgoCDBasePerHour   float64
+	ArgoCDAppPerHour    float64
+	ACKBasePerHour      float64
+	ACKResourcePerHour  float64
+	KroBasePerHour      float64
+	KroRGDPerHour       float64
+	FargateVCPUPerHour  float64
+	FargateMemGBPerHour float64
+
+	// Ancillary self-managed infrastructure rates.
+	ALBPerHour          float64 // Application Load Balancer hour
+	ALBLCUPerHour       float64 // ALB Load Balancer Capacity Unit hour
+	EBSGBMonth          float64 // gp3 EBS storage per GB-month
+	CloudWatchLogsPerGB float64 // CloudWatch Logs ingestion per GB
+
+	// Currency is the ISO 4217 code every rate is in: that of the region's
+	// Partition, such as "CNY" for AWS China. Empty means USD.
+	Currency string `json:"currency,omitempty"`
+}
+
+// CurrencyCode returns the code of the currency the rates are in.
+func (r Rates) CurrencyCode() string {
+	if r.Currency == "" {
+		return "USD"
+	}
+	return r.Currency
+}
+
+// ForCapability returns the base and resource hourly rates for the given capability.
+func (r Rates) ForCapability(cap calculator.Capability) (base, resource float64) {
+	switch cap {
+	case calculator.CapabilityArgoCD:
+		return r.ArgoCDBasePerHour, r.ArgoCDAppPerHour
+	case calculator.CapabilityACK:
+		return r.ACKBasePerHour, r.ACKResourcePerHour
+	case calculator.CapabilityKro:
+		return r.KroBasePerHour, r.KroRGDPerHour
+	default:
+		return 0, 0
+	}
+}
+
+// HasAllCapabilityRates returns true if all capability rates are populated (> 0).
+func (r Rates) HasAllCapabilityRates() bool {
+	return r.ArgoCDBasePerHour > 0 && r.ArgoCDAppPerHour > 0 &&
+		r.ACKBasePerHour > 0 && r.ACKResourcePerHour > 0 &&
+		r.KroBasePerHour > 0 && r.KroRGDPerHour > 0
+}
+
+// HasAncillaryRates returns true if all ancillary self-managed rates are
+// populated (> 0).
+func (r Rates) HasAncillaryRates() bool {
+	return r.ALBPerHour > 0 && r.ALBLCUPerHour > 0 &&
+		r.EBSGBMonth > 0 && r.CloudWatchLogsPerGB > 0
+}
+
+// DefaultRates returns the hardcoded fallback rates.
+func DefaultRates() Rates {
+	return Rates{
+		ArgoCDBasePerHour:   0.03,
+		ArgoCDAppPerHour:    0.0015,
+		ACKBasePerHour:      0.005,
+		ACKResourcePerHour:  0.00005,
+		KroBasePerHour:      0.005,
+		KroRGDPerHour:       0.00005,
+		FargateVCPUPerHour:  0.04048,
+		FargateMemGBPerHour: 0.004446,
+		ALBPerHour:          0.0225,
+		ALBLCUPerHour:       0.008,
+		EBSGBMonth:          0.08,
+		CloudWatchLogsPerGB: 0.50,
+	}
+}
+
+// loadDefaultConfig and newPricingClient are package-level vars for testing.
+var loadDefaultConfig = config.LoadDefaultConfig
+var newPricingClient = func(cfg aws.Config) PricingAPI {
+	return NewRetryingClient(pricing.NewFromConfig(cfg, func(o *pricing.Options) {
+		o.RetryMaxAttempts = 1
+	}))
+}
+
+// PricingAPI is the interface for the AWS Pricing GetProducts call.
+type PricingAPI interface {
+	GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error)
+}
+
+// FetchRates resolves rates for the region through DefaultChain: the local
+// cache, then the AWS Pricing API (caching the result), then DefaultRates.
+// Source errors are not returned, since DefaultRates always completes the
+// chain.
+func FetchRates(ctx context.Context, region string) (Rates, error) {
+	return DefaultChain().Rates(ctx, region)
+}
+
+// capabilitySuffixes defines the usage type suffixes for each EKS capability.
+type capabilitySuffixes struct {
+	baseSuffix     string
+	resourceSuffix string
+}
+
+var allCapSuffixes = []struct {
+	name           string
+	suffixes       capabilitySuffixes
+	base, resource Field
+}{
+	{"ArgoCD", capabilitySuffixes{
+		baseSuffix:     "AmazonEKSCapabilities-ArgoCD-Hours:perCapability",
+		resourceSuffix: "AmazonEKSCapabilities-ArgoCD-CR-Hours:perCustomResource",
+	}, FieldArgoCDBasePerHour, FieldArgoCDAppPerHour},
+	{"ACK", capabilitySuffixes{
+		baseSuffix:     "AmazonEKSCapabilities-ACK-Hours:perCapability",
+		resourceSuffix: "AmazonEKSCapabilities-ACK-CR-Hours:perCustomResource",
+	}, FieldACKBasePerHour, FieldACKResourcePerHour},
+	{"kro", capabilitySuffixes{
+		baseSuffix:     "AmazonEKSCapabilities-KRO-Hours:perCapability",
+		resourceSuffix: "AmazonEKSCapabilities-KRO-CR-Hours:perCustomResource",
+	}, FieldKroBasePerHour, FieldKroRGDPerHour},
+}
+
+// quote is a rate together with its full schedule and unit, the usage type
+// of the product it was read from and the Pricing API endpoint that served
+// it.
+type quote struct {
+	rate      float64
+	tiers     Schedule
+	unit      string
+	usageType string
+	endpoint  string
+}
+
+// FetchRatesWithClient fetches live pricing using the provided client.
+// EKS capabilities are fetched in a single pass to minimize API calls.
+// Missing capability products are not treated as errors (defaults are used).
+// Only actual API failures (network, auth) are returned as errors.
+func FetchRatesWithClient(ctx context.Context, client PricingAPI, region string) (Rates, error) {
+	sheet, err := fetchOnto(ctx, client, region, baseRatesFor(region))
+	return sheet.Rates, err
+}
+
+// fetchOnto writes every rate the API returns for region over rates,
+// attributed to the api source. Fields the API has no product for keep
+// their value and provenance from rates, which must be in the region's
+// currency.
+func fetchOnto(ctx context.Context, client PricingAPI, region string, rates RateSheet) (RateSheet, error) {
+	fetchedAt := timeNow()
+	rates.Currency = regionCurrency(region)
+	set := func(f Field, q quote) {
+		rates.setTiered(f, q.rate, q.tiers, Provenance{
+			Source:    SourceAPI,
+			Region:    region,
+			UsageType: q.usageType,
+			Unit:      q.unit,
+			Endpoint:  q.endpoint,
+			FetchedAt: fetchedAt,
+		})
+	}
+
+	found, err := fetchAllEKSCapabilities(ctx, client, region)
+	if err != nil {
+		return rates, fmt.Errorf("fetching EKS pricing: %w", err)
+	}
+
+	// Apply any rates that were found; missing ones keep defaults
+	for _, cs := range allCapSuffixes {
+		base := found[cs.suffixes.baseSuffix]
+		res := found[cs.suffixes.resourceSuffix]
+		if base.rate > 0 && res.rate > 0 {
+			set(cs.base, base)
+			set(cs.resource, res)
+		}
+	}
+
+	vcpu, mem, err := fetchFargate(ctx, client, region)
+	if err == nil {
+		set(FieldFargateVCPUPerHour, vcpu)
+		set(FieldFargateMemGBPerHour, mem)
+	}
+
+	applyAncillary(ctx, client, region, set)
+
+	return rates, nil
+}
+
+// fetchAllEKSCapabilities fetches all EKS capability rates in a single
+// paginated query. It returns a map from suffix to quote for each product
+// found. This avoids making 3 separate paginated queries (one per capability).
+func fetchAllEKSCapabilities(ctx context.Context, client PricingAPI, region string) (map[string]quote, error) {
+	var suffixes []string
+	for _, cs := range allCapSuffixes {
+		suffixes = append(suffixes, cs.suffixes.baseSuffix, cs.suffixes.resourceSuffix)
+	}
+
+	return fetchBySuffix(ctx, client, "AmazonEKS", []types.Filter{regionFilter(region)}, suffixes)
+}
+
+// fetchBySuffix pages through every product matching the given service code
+// and filters, returning a map from usage type suffix to quote for each
+// suffix that was found. Paging stops early once every suffix is matched.
+func fetchBySuffix(ctx context.Context, client PricingAPI, serviceCode string, filters []types.Filter, suffixes []string) (map[string]quote, error) {
+	allSuffixes := make(map[string]bool)
+	for _, suffix := range suffixes {
+		allSuffixes[suffix] = false
+	}
+
+	found := make(map[string]quote)
+	var nextToken *string
+
+	for {
+		input := &pricing.GetProductsInput{
+			ServiceCode: aws.String(serviceCode),
+			Filters:     filters,
+			MaxResults:  aws.Int32(100),
+			NextToken:   nextToken,
+		}
+
+		output, err := client.GetProducts(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, priceJSON := range output.PriceList {
+			var doc productDoc
+			if err := json.Unmarshal([]byte(priceJSON), &doc); err != nil {
+				continue
+			}
+
+			usageType := doc.Product.Attributes["usagetype"]
+			for suffix, matched := range allSuffixes {
+				if !matched && usageTypeHasSuffix(usageType, suffix) {
+					if q, err := quoteFromDoc(doc); err == nil {
+						q.endpoint = servedBy(output)
+						found[suffix] = q
+						allSuffixes[suffix] = true
+					}
+				}
+			}
+
+			// Early return once every suffix has been found
+			if len(found) == len(allSuffixes) {
+				return found, nil
+			}
+		}
+
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return found, nil
+}
+
+// regionFilter returns the regionCode term-match filter shared by every query.
+func regionFilter(region string) types.Filter {
+	return types.Filter{
+		Type:  types.FilterTypeTermMatch,
+		Field: aws.String("regionCode"),
+		Value: aws.String(region),
+	}
+}
+
+func fetchFargate(ctx context.Context, client PricingAPI, region string) (vcpu, mem quote, err error) {
+	vcpuInput := &pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonECS"),
+		Filters: []types.Filter{
+			{
+				Type:  types.FilterTypeTermMatch,
+				Field: aws.String("regionCode"),
+				Value: aws.String(region),
+			},
+			{
+				Type:  types.FilterTypeTermMatch,
+				Field: aws.String("productFamily"),
+				Value: aws.String("Compute"),
+			},
+			{
+				Type:  types.FilterTypeTermMatch,
+				Field: aws.String("cputype"),
+				Value: aws.String("perCPU"),
+			},
+		},
+		MaxResults: aws.Int32(1),
+	}
+
+	vcpu, err = fetchSingleRate(ctx, client, vcpuInput)
+	if err != nil {
+		return quote{}, quote{}, fmt.Errorf("fargate vCPU: %w", err)
+	}
+
+	memInput := &pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonECS"),
+		Filters: []types.Filter{
+			{
+				Type:  types.FilterTypeTermMatch,
+				Field: aws.String("regionCode"),
+				Value: aws.String(region),
+			},
+			{
+				Type:  types.FilterTypeTermMatch,
+				Field: aws.String("productFamily"),
+				Value: aws.String("Compute"),
+			},
+			{
+				Type:  types.FilterTypeTermMatch,
+				Field: aws.String("memorytype"),
+				Value: aws.String("perGB"),
+			},
+		},
+		MaxResults: aws.Int32(1),
+	}
+
+	mem, err = fetchSingleRate(ctx, client, memInput)
+	if err != nil {
+		return quote{}, quote{}, fmt.Errorf("fargate memory: %w", err)
+	}
+
+	return vcpu, mem, nil
+}
+
+// productDoc represents the JSON structure returned by the Pricing API.
+type productDoc struct {
+	Product struct {
+		Attributes map[string]string `json:"attributes"`
+	} `json:"product"`
+	Terms struct {
+		OnDemand map[string]offerTerm `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+// offerTerm is one OnDemand term of a product.
+type offerTerm struct {
+	PriceDimensions map[string]priceDimension `json:"priceDimensions"`
+}
+
+// priceDimension is one price of a term. Products priced in volume tiers
+// have one dimension per tier, bounded by BeginRange and EndRange ("Inf"
+// for the last).
+type priceDimension struct {
+	PricePerUnit map[string]string `json:"pricePerUnit"`
+	Unit         string            `json:"unit"`
+	BeginRange   string            `json:"beginRange"`
+	EndRange     string            `json:"endRange"`
+	Description  string            `json:"description"`
+}
+
+func fetchSingleRate(ctx context.Context, client PricingAPI, input *pricing.GetProductsInput) (quote, error) {
+	output, err := client.GetProducts(ctx, input)
+	if err != nil {
+		return quote{}, err
+	}
+
+	if len(output.PriceList) == 0 {
+		return quote{}, errNoProducts
+	}
+
+	q, err := parseQuote(output.PriceList[0])
+	q.endpoint = servedBy(output)
+	return q, err
+}
+
+func parseQuote(priceJSON string) (quote, error) {
+	var doc productDoc
+	if err := json.Unmarshal([]byte(priceJSON), &doc); err != nil {
+		return quote{}, fmt.Errorf("parsing price JSON: %w", err)
+	}
+
+	return quoteFromDoc(doc)
+}
+
+// quoteFromDoc reads the schedule of a product into a quote, in the
+// currency of the partition of the product's region.
+func quoteFromDoc(doc productDoc) (quote, error) {
+	s, err := parseSchedule(doc, PartitionOf(doc.Product.Attributes["regionCode"]).Currency)
+	if err != nil {
+		return quote{}, err
+	}
+	return quote{rate: s.Rate(), tiers: s, unit: s.unit(), usageType: doc.Product.Attributes["usagetype"]}, nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"os"
 	"testing"
 	"time"
 
@@ -12,7 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/pricing"
 
-	"github.com/josegonzalez/aws-eks-calculator/internal/calculator"
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
 )
 
 type mockPricingAPI struct {
@@ -275,38 +274,41 @@ func TestForCapability(t *testing.T) {
 	}
 }
 
-func TestParseRateHourly(t *testing.T) {
+func TestParseQuoteHourly(t *testing.T) {
 	json := eksProductJSON("USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability", "0.05")
-	rate, err := parseRate(json)
+	q, err := parseQuote(json)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if rate != 0.05 {
-		t.Errorf("got %f, want 0.05", rate)
+	if q.rate != 0.05 {
+		t.Errorf("got %f, want 0.05", q.rate)
+	}
+	if q.usageType != "USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability" {
+		t.Errorf("unexpected usage type %q", q.usageType)
 	}
 }
 
-func TestParseRatePerSecond(t *testing.T) {
+func TestParseQuotePerSecond(t *testing.T) {
 	json := fargateJSON("0.001", "Second")
-	rate, err := parseRate(json)
+	q, err := parseQuote(json)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	expected := 0.001 * 3600
-	if math.Abs(rate-expected) > 0.001 {
-		t.Errorf("got %f, want %f", rate, expected)
+	if math.Abs(q.rate-expected) > 0.001 {
+		t.Errorf("got %f, want %f", q.rate, expected)
 	}
 }
 
-func TestParseRateNoOnDemand(t *testing.T) {
+func TestParseQuoteNoOnDemand(t *testing.T) {
 	json := `{"product": {"attributes": {}}, "terms": {"OnDemand": {}}}`
-	_, err := parseRate(json)
+	_, err := parseQuote(json)
 	if err == nil {
 		t.Fatal("expected error for empty OnDemand")
 	}
 }
 
-func TestParseRateNoUSD(t *testing.T) {
+func TestParseQuoteNoUSD(t *testing.T) {
 	json := `{
 		"product": {"attributes": {}},
 		"terms": {
@@ -322,7 +324,7 @@ func TestParseRateNoUSD(t *testing.T) {
 			}
 		}
 	}`
-	_, err := parseRate(json)
+	_, err := parseQuote(json)
 	if err == nil {
 		t.Fatal("expected error for missing USD")
 	}
@@ -562,26 +564,18 @@ func TestFetchRatesZeroRateProducts(t *testing.T) {
 	}
 }
 
-func TestParseRateMalformedJSON(t *testing.T) {
-	_, err := parseRate("{invalid}")
+func TestParseQuoteMalformedJSON(t *testing.T) {
+	_, err := parseQuote("{invalid}")
 	if err == nil {
 		t.Fatal("expected error for malformed JSON")
 	}
 }
 
-func TestExtractRateInvalidUSD(t *testing.T) {
+func TestParseScheduleInvalidUSD(t *testing.T) {
 	doc := productDoc{}
-	doc.Terms.OnDemand = map[string]struct {
-		PriceDimensions map[string]struct {
-			PricePerUnit map[string]string `json:"pricePerUnit"`
-			Unit         string            `json:"unit"`
-		} `json:"priceDimensions"`
-	}{
+	doc.Terms.OnDemand = map[string]offerTerm{
 		"offer1": {
-			PriceDimensions: map[string]struct {
-				PricePerUnit map[string]string `json:"pricePerUnit"`
-				Unit         string            `json:"unit"`
-			}{
+			PriceDimensions: map[string]priceDimension{
 				"dim1": {
 					PricePerUnit: map[string]string{"USD": "notanumber"},
 					Unit:         "Hour",
@@ -590,7 +584,7 @@ func TestExtractRateInvalidUSD(t *testing.T) {
 		},
 	}
 
-	_, err := extractRateFromDoc(doc)
+	_, err := parseSchedule(doc, "USD")
 	if err == nil {
 		t.Fatal("expected error for invalid USD value")
 	}
@@ -716,6 +710,7 @@ func TestNewPricingClientDefault(t *testing.T) {
 }
 
 func TestFetchRatesConfigError(t *testing.T) {
+	withUserCacheDir(t)
 	origLoad := loadDefaultConfig
 	defer func() { loadDefaultConfig = origLoad }()
 
@@ -734,6 +729,7 @@ func TestFetchRatesConfigError(t *testing.T) {
 }
 
 func TestFetchRatesClientSuccess(t *testing.T) {
+	withUserCacheDir(t)
 	origLoad := loadDefaultConfig
 	origClient := newPricingClient
 	defer func() {
@@ -758,13 +754,10 @@ func TestFetchRatesClientSuccess(t *testing.T) {
 	if got.ArgoCDBasePerHour != 0.03 {
 		t.Errorf("expected ArgoCD rate 0.03, got %f", got.ArgoCDBasePerHour)
 	}
-
-	// Clean up cached entry
-	c := NewCache()
-	_ = os.Remove(c.path(region))
 }
 
 func TestFetchRatesClientError(t *testing.T) {
+	withUserCacheDir(t)
 	origLoad := loadDefaultConfig
 	origClient := newPricingClient
 	defer func() {
@@ -791,6 +784,7 @@ func TestFetchRatesClientError(t *testing.T) {
 }
 
 func TestFetchRatesCacheHit(t *testing.T) {
+	withUserCacheDir(t)
 	c := NewCache()
 	rates := DefaultRates()
 	rates.ArgoCDBasePerHour = 0.42
@@ -805,39 +799,54 @@ func TestFetchRatesCacheHit(t *testing.T) {
 	if got.ArgoCDBasePerHour != 0.42 {
 		t.Errorf("expected cached rate 0.42, got %f", got.ArgoCDBasePerHour)
 	}
+}
+
+func TestFetchRatesCacheOldFormat(t *testing.T) {
+	// Entries written before the cache format was versioned are refetched
+	withUserCacheDir(t)
+	c := NewCache()
+	rates := DefaultRates()
+	rates.ArgoCDBasePerHour = 0.42
+	writeCacheEntry(t, c, "stale-test-region", 0, rates)
 
-	// Clean up
-	_ = c.Save("ap-south-1", Rates{}) // overwrite with empty to not affect other tests
+	origLoad := loadDefaultConfig
+	defer func() { loadDefaultConfig = origLoad }()
+	loadDefaultConfig = func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{}, fmt.Errorf("no creds")
+	}
+
+	got, err := FetchRates(context.Background(), "stale-test-region")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != DefaultRates() {
+		t.Errorf("expected default rates on old cache entry + config error, got %+v", got)
+	}
 }
 
-func TestFetchRatesCacheStale(t *testing.T) {
-	// Save rates with missing capability fields — HasAllCapabilityRates returns false
+func TestFetchRatesCachePartialEntry(t *testing.T) {
+	// A current entry supplies the rates it has; the others are resolved
+	// further down the chain
+	withUserCacheDir(t)
 	c := NewCache()
-	staleRates := Rates{
-		ArgoCDBasePerHour:   0.03,
-		ArgoCDAppPerHour:    0.0015,
-		FargateVCPUPerHour:  0.04048,
-		FargateMemGBPerHour: 0.004446,
-		// ACK and KRO fields are zero
-	}
-	if err := c.Save("stale-test-region", staleRates); err != nil {
+	rates := DefaultRates()
+	rates.ArgoCDBasePerHour = 0.42
+	rates.ALBPerHour = 0
+	if err := c.Save("partial-region", rates); err != nil {
 		t.Fatalf("cache save: %v", err)
 	}
 
-	// FetchRates should fall through to client since HasAllCapabilityRates is false.
-	// Since loadDefaultConfig will likely fail without AWS creds, we get defaults.
 	origLoad := loadDefaultConfig
 	defer func() { loadDefaultConfig = origLoad }()
 	loadDefaultConfig = func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
 		return aws.Config{}, fmt.Errorf("no creds")
 	}
 
-	got, err := FetchRates(context.Background(), "stale-test-region")
+	got, err := FetchRates(context.Background(), "partial-region")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	defaults := DefaultRates()
-	if got != defaults {
-		t.Errorf("expected default rates on stale cache + config error, got %+v", got)
+	if got.ArgoCDBasePerHour != 0.42 || got.ALBPerHour != DefaultRates().ALBPerHour {
+		t.Errorf("expected the cached ArgoCD rate and the default ALB rate, got %+v", got)
 	}
 }
@@ -0,0 +1,101 @@
+package pricing
+
+import (
+	"slices"
+	"time"
+)
+
+// timeNow is a package-level var for testing.
+var timeNow = time.Now
+
+// Provenance records where a rate came from.
+type Provenance struct {
+	// Source names the source that supplied the rate: "overrides", "api",
+	// "cache", "offers", "history", "defaults" or the name of a custom
+	// Source.
+	Source string `json:"source"`
+
+	// Region is the region the rate applies to.
+	Region string `json:"region,omitempty"`
+
+	// UsageType is the AWS usage type of the product the rate was read
+	// from, e.g. "USE1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability".
+	// Empty for built-in defaults.
+	UsageType string `json:"usage_type,omitempty"`
+
+	// Unit is the unit AWS priced the rate in, e.g. "Hrs" or "GB-Mo".
+	// Per-second prices are converted to per-hour but keep their unit.
+	// Empty for built-in defaults and overrides.
+	Unit string `json:"unit,omitempty"`
+
+	// Endpoint is the region of the Pricing API endpoint that served the
+	// rate. Empty for rates not read from the Pricing API, and for clients
+	// other than Client.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// FetchedAt is when the rate was fetched from the Pricing API, or the
+	// publication date of the offer file it was read from. Zero for
+	// built-in defaults.
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
+}
+
+// RateSheet is a set of Rates together with what is known about each
+// rate: where it came from and, for fields AWS prices in more than one
+// volume tier, the full schedule. It is what sources, the cache and the
+// rate history deal in; Rates itself stays a comparable value.
+type RateSheet struct {
+	Rates
+
+	// Tiers holds the full schedule of each field AWS prices in more than
+	// one volume tier. The field itself holds the schedule's Rate.
+	Tiers map[Field]Schedule `json:"tiers,omitempty"`
+
+	// Provenance records where each rate came from.
+	Provenance map[Field]Provenance `json:"provenance,omitempty"`
+}
+
+// Equal reports whether s and o hold the same rates and tiers in the same
+// currency, ignoring provenance.
+func (s RateSheet) Equal(o RateSheet) bool {
+	if s.CurrencyCode() != o.CurrencyCode() {
+		return false
+	}
+	for _, f := range Fields() {
+		if s.Get(f) != o.Get(f) || !slices.Equal(s.Tiers[f], o.Tiers[f]) {
+			return false
+		}
+	}
+	return true
+}
+
+// setWithProvenance stores v in field f and records where it came from. Any
+// tiers f had are dropped; see setTiered.
+func (s *RateSheet) setWithProvenance(f Field, v float64, p Provenance) {
+	s.Set(f, v)
+	delete(s.Tiers, f)
+	if s.Provenance == nil {
+		s.Provenance = make(map[Field]Provenance)
+	}
+	s.Provenance[f] = p
+}
+
+// defaultRatesFor returns DefaultRates with every field attributed to the
+// defaults source for region.
+func defaultRatesFor(region string) RateSheet {
+	defaults := DefaultRates()
+	var sheet RateSheet
+	for _, f := range Fields() {
+		sheet.setWithProvenance(f, defaults.Get(f), Provenance{Source: SourceDefaults, Region: region})
+	}
+	return sheet
+}
+
+// baseRatesFor returns the rates that fetched rates for region are laid
+// over: defaultRatesFor in regions priced in USD, and no rates in others,
+// since the defaults are in USD.
+func baseRatesFor(region string) RateSheet {
+	if c := regionCurrency(region); c != "" {
+		return RateSheet{Rates: Rates{Currency: c}}
+	}
+	return defaultRatesFor(region)
+}
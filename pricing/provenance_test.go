@@ -0,0 +1,57 @@
+package pricing
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateSheetEqualIgnoresProvenance(t *testing.T) {
+	a := defaultRatesFor("us-east-1")
+	if !a.Equal(RateSheet{Rates: DefaultRates()}) {
+		t.Error("rates with provenance should equal the same rates without")
+	}
+	a.KroRGDPerHour = 1
+	if a.Equal(RateSheet{Rates: DefaultRates()}) {
+		t.Error("rates with different values should not be equal")
+	}
+}
+
+func TestDefaultRatesFor(t *testing.T) {
+	r := defaultRatesFor("eu-west-1")
+	if len(r.Provenance) != len(Fields()) {
+		t.Fatalf("expected provenance for every field, got %d", len(r.Provenance))
+	}
+	p := r.Provenance[FieldEBSGBMonth]
+	if p.Source != SourceDefaults || p.Region != "eu-west-1" || p.UsageType != "" || !p.FetchedAt.IsZero() {
+		t.Errorf("unexpected provenance %+v", p)
+	}
+	if r.Rates != DefaultRates() {
+		t.Errorf("expected the default rates, got %+v", r.Rates)
+	}
+}
+
+func TestProvenanceJSON(t *testing.T) {
+	r := RateSheet{Rates: Rates{ALBPerHour: 0.02}}
+	r.setWithProvenance(FieldALBPerHour, 0.0225, Provenance{
+		Source:    SourceAPI,
+		Region:    "us-east-1",
+		UsageType: "USE1-LoadBalancerUsage",
+		FetchedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+	})
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"provenance":{"ALBPerHour":{"source":"api","region":"us-east-1","usage_type":"USE1-LoadBalancerUsage","fetched_at":"2026-10-18T12:00:00Z"}}`
+	if !strings.Contains(string(data), want) || !strings.Contains(string(data), `"ALBPerHour":0.0225`) {
+		t.Errorf("expected %s next to the rates in %s", want, data)
+	}
+
+	data, _ = json.Marshal(Provenance{Source: SourceDefaults})
+	if string(data) != `{"source":"defaults"}` {
+		t.Errorf("expected empty details omitted, got %s", data)
+	}
+}
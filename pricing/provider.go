@@ -0,0 +1,40 @@
+package pricing
+
+import "context"
+
+// Provider supplies pricing rates for a region. Implementations should
+// return usable rates (typically falling back to DefaultRates) alongside any
+// error so callers can decide whether to surface it.
+type Provider interface {
+	Rates(ctx context.Context, region string) (Rates, error)
+}
+
+// ProviderFunc adapts an ordinary function to a Provider.
+type ProviderFunc func(ctx context.Context, region string) (Rates, error)
+
+// Rates calls f(ctx, region).
+func (f ProviderFunc) Rates(ctx context.Context, region string) (Rates, error) {
+	return f(ctx, region)
+}
+
+// Default returns the provider used by the calculator, DefaultChain: the
+// local cache, then the AWS Pricing API using the default credential chain,
+// then DefaultRates.
+func Default() Provider {
+	return DefaultChain()
+}
+
+// NewAPIProvider returns a provider that queries the AWS Pricing API with
+// the given client on every call, without caching. See FetchRatesWithClient.
+func NewAPIProvider(client PricingAPI) Provider {
+	return ProviderFunc(func(ctx context.Context, region string) (Rates, error) {
+		return FetchRatesWithClient(ctx, client, region)
+	})
+}
+
+// Static returns a provider that answers every region with the given rates.
+func Static(rates Rates) Provider {
+	return ProviderFunc(func(context.Context, string) (Rates, error) {
+		return rates, nil
+	})
+}
@@ -0,0 +1,70 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+func TestProviderFunc(t *testing.T) {
+	var gotRegion string
+	p := ProviderFunc(func(_ context.Context, region string) (Rates, error) {
+		gotRegion = region
+		return Rates{ArgoCDBasePerHour: 0.5}, nil
+	})
+
+	rates, err := p.Rates(context.Background(), "eu-west-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotRegion != "eu-west-1" || rates.ArgoCDBasePerHour != 0.5 {
+		t.Errorf("got region %q rates %+v", gotRegion, rates)
+	}
+}
+
+func TestNewAPIProvider(t *testing.T) {
+	mock := &mockPricingAPI{responses: allCapabilityProducts("us-west-2")}
+	rates, err := NewAPIProvider(mock).Rates(context.Background(), "us-west-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rates.ArgoCDBasePerHour != 0.03 {
+		t.Errorf("ArgoCDBasePerHour: got %f, want 0.03", rates.ArgoCDBasePerHour)
+	}
+
+	_, err = NewAPIProvider(&mockPricingAPI{err: fmt.Errorf("throttled")}).Rates(context.Background(), "us-west-2")
+	if err == nil {
+		t.Error("expected API error to be returned")
+	}
+}
+
+func TestStatic(t *testing.T) {
+	want := DefaultRates()
+	want.KroBasePerHour = 1
+
+	got, err := Static(want).Rates(context.Background(), "any")
+	if err != nil || got != want {
+		t.Errorf("got %+v, %v", got, err)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	withUserCacheDir(t)
+	origLoad := loadDefaultConfig
+	defer func() { loadDefaultConfig = origLoad }()
+
+	loadDefaultConfig = func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{}, fmt.Errorf("no credentials")
+	}
+
+	rates, err := Default().Rates(context.Background(), "provider-default-test-region")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rates != DefaultRates() {
+		t.Errorf("expected default rates, got %+v", rates)
+	}
+}
@@ -0,0 +1,248 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+)
+
+// Region is an AWS region with its human-readable name.
+type Region struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Partition returns the partition the region belongs to.
+func (r Region) Partition() Partition {
+	return PartitionOf(r.Code)
+}
+
+// ErrNoRegions is returned by DiscoverRegions when the Pricing API lists no
+// region with EKS capability products.
+var ErrNoRegions = errors.New("no regions with EKS capability products")
+
+// builtinRegions is the offline region list, in display order.
+var builtinRegions = []Region{
+	{"us-east-1", "US East (N. Virginia)"},
+	{"us-east-2", "US East (Ohio)"},
+	{"us-west-1", "US West (N. California)"},
+	{"us-west-2", "US West (Oregon)"},
+	{"eu-west-1", "Europe (Ireland)"},
+	{"eu-west-2", "Europe (London)"},
+	{"eu-west-3", "Europe (Paris)"},
+	{"eu-central-1", "Europe (Frankfurt)"},
+	{"eu-central-2", "Europe (Zurich)"},
+	{"eu-north-1", "Europe (Stockholm)"},
+	{"eu-south-1", "Europe (Milan)"},
+	{"eu-south-2", "Europe (Spain)"},
+	{"ap-southeast-1", "Asia Pacific (Singapore)"},
+	{"ap-southeast-2", "Asia Pacific (Sydney)"},
+	{"ap-southeast-3", "Asia Pacific (Jakarta)"},
+	{"ap-southeast-4", "Asia Pacific (Melbourne)"},
+	{"ap-southeast-5", "Asia Pacific (Malaysia)"},
+	{"ap-southeast-7", "Asia Pacific (Thailand)"},
+	{"ap-northeast-1", "Asia Pacific (Tokyo)"},
+	{"ap-northeast-2", "Asia Pacific (Seoul)"},
+	{"ap-northeast-3", "Asia Pacific (Osaka)"},
+	{"ap-south-1", "Asia Pacific (Mumbai)"},
+	{"ap-south-2", "Asia Pacific (Hyderabad)"},
+	{"ap-east-1", "Asia Pacific (Hong Kong)"},
+	{"ap-east-2", "Asia Pacific (Taipei)"},
+	{"sa-east-1", "South America (Sao Paulo)"},
+	{"ca-central-1", "Canada (Central)"},
+	{"ca-west-1", "Canada West (Calgary)"},
+	{"mx-central-1", "Mexico (Central)"},
+	{"me-south-1", "Middle East (Bahrain)"},
+	{"me-central-1", "Middle East (UAE)"},
+	{"il-central-1", "Israel (Tel Aviv)"},
+	{"af-south-1", "Africa (Cape Town)"},
+	{"us-gov-west-1", "AWS GovCloud (US-West)"},
+	{"us-gov-east-1", "AWS GovCloud (US-East)"},
+	{"cn-north-1", "China (Beijing)"},
+	{"cn-northwest-1", "China (Ningxia)"},
+}
+
+// BuiltinRegions returns the regions known to this version, commercial ones
+// first, then GovCloud and China, for use when the region list cannot be
+// discovered. Not every region is guaranteed to sell the EKS capabilities.
+func BuiltinRegions() []Region {
+	return append([]Region(nil), builtinRegions...)
+}
+
+// RegionName returns the human-readable name of a built-in region, or "".
+func RegionName(code string) string {
+	for _, r := range builtinRegions {
+		if r.Code == code {
+			return r.Name
+		}
+	}
+	return ""
+}
+
+// DiscoverRegions lists the regions that have EKS capability products in
+// the Pricing API, named after the products' location attribute. Built-in
+// regions come first in their usual order, followed by any others by code.
+func DiscoverRegions(ctx context.Context, client PricingAPI) ([]Region, error) {
+	var suffixes []string
+	for _, cs := range allCapSuffixes {
+		suffixes = append(suffixes, cs.suffixes.baseSuffix, cs.suffixes.resourceSuffix)
+	}
+
+	names := make(map[string]string)
+	var nextToken *string
+	for {
+		output, err := client.GetProducts(ctx, &pricing.GetProductsInput{
+			ServiceCode: aws.String("AmazonEKS"),
+			Filters: []types.Filter{{
+				Type:  types.FilterTypeContains,
+				Field: aws.String("usagetype"),
+				Value: aws.String("AmazonEKSCapabilities-"),
+			}},
+			MaxResults: aws.Int32(100),
+			NextToken:  nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("discovering regions: %w", err)
+		}
+
+		for _, priceJSON := range output.PriceList {
+			var doc productDoc
+			if err := json.Unmarshal([]byte(priceJSON), &doc); err != nil {
+				continue
+			}
+			attrs := doc.Product.Attributes
+			code := attrs["regionCode"]
+			if code == "" || !hasAnySuffix(attrs["usagetype"], suffixes) {
+				continue
+			}
+			if names[code] == "" {
+				names[code] = attrs["location"]
+			}
+		}
+
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	if len(names) == 0 {
+		return nil, ErrNoRegions
+	}
+
+	regions := make([]Region, 0, len(names))
+	for code, name := range names {
+		if name == "" {
+			name = RegionName(code)
+		}
+		regions = append(regions, Region{Code: code, Name: name})
+	}
+	sortRegions(regions)
+	return regions, nil
+}
+
+func hasAnySuffix(usageType string, suffixes []string) bool {
+	for _, s := range suffixes {
+		if usageTypeHasSuffix(usageType, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortRegions orders built-in regions as in BuiltinRegions, then the others
+// by code.
+func sortRegions(regions []Region) {
+	rank := make(map[string]int, len(builtinRegions))
+	for i, r := range builtinRegions {
+		rank[r.Code] = i
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		ri, iok := rank[regions[i].Code]
+		rj, jok := rank[regions[j].Code]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		default:
+			return regions[i].Code < regions[j].Code
+		}
+	})
+}
+
+// cachedRegions is the on-disk format of the discovered region list.
+type cachedRegions struct {
+	Regions   []Region  `json:"regions"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+func (c *Cache) regionsPath() string {
+	return filepath.Join(c.dir, "regions.json")
+}
+
+// LoadRegions returns the cached region list if it is present and has not
+// expired, or nil.
+func (c *Cache) LoadRegions() []Region {
+	data, err := os.ReadFile(c.regionsPath())
+	if err != nil {
+		return nil
+	}
+	var entry cachedRegions
+	if err := json.Unmarshal(data, &entry); err != nil || len(entry.Regions) == 0 {
+		return nil
+	}
+	if c.expired(entry.FetchedAt) {
+		return nil
+	}
+	return entry.Regions
+}
+
+// SaveRegions writes the region list to the cache.
+func (c *Cache) SaveRegions(regions []Region) error {
+	data, err := cacheJSONMarshal(cachedRegions{Regions: regions, FetchedAt: c.now()})
+	if err != nil {
+		return err
+	}
+	unlock, err := c.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeFileAtomic(c.regionsPath(), data)
+}
+
+// Regions returns the regions where the EKS capabilities are sold: from
+// cache while fresh, otherwise discovered through the Pricing API and saved
+// to cache. A nil client uses the default credential chain, and a nil cache
+// is neither read nor written. If discovery fails, BuiltinRegions is
+// returned along with the error.
+func Regions(ctx context.Context, client PricingAPI, cache *Cache) ([]Region, error) {
+	if cache != nil {
+		if regions := cache.LoadRegions(); regions != nil {
+			return regions, nil
+		}
+	}
+
+	if client == nil {
+		client = NewClient(Credentials{})
+	}
+
+	regions, err := DiscoverRegions(ctx, client)
+	if err != nil {
+		return BuiltinRegions(), err
+	}
+	if cache != nil {
+		_ = cache.SaveRegions(regions)
+	}
+	return regions, nil
+}
@@ -0,0 +1,209 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+const regionsKey = "AmazonEKS:usagetype=AmazonEKSCapabilities-"
+
+func regionProductJSON(region, location, usageType string) string {
+	return fmt.Sprintf(`{"product": {"attributes": {"regionCode": %q, "location": %q, "usagetype": %q}}, "terms": {}}`, region, location, usageType)
+}
+
+func regionsAPI() *mockPricingAPI {
+	return &mockPricingAPI{responses: map[string]*pricing.GetProductsOutput{
+		regionsKey: {PriceList: []string{
+			regionProductJSON("eu-west-1", "Europe (Ireland)", "EU-AmazonEKSCapabilities-ArgoCD-Hours:perCapability"),
+			regionProductJSON("eu-west-1", "Europe (Ireland)", "EU-AmazonEKSCapabilities-ACK-Hours:perCapability"),
+			regionProductJSON("us-east-1", "US East (N. Virginia)", "USE1-AmazonEKSCapabilities-KRO-CR-Hours:perCustomResource"),
+			regionProductJSON("xx-new-1", "", "XXN1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability"),
+			regionProductJSON("mx-central-1", "", "MXC1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability"),
+			regionProductJSON("aa-new-1", "Somewhere New", "AAN1-AmazonEKSCapabilities-ArgoCD-Hours:perCapability"),
+			regionProductJSON("ap-south-1", "Asia Pacific (Mumbai)", "APS3-AmazonEKSCapabilities-Preview-Hours"),
+			regionProductJSON("", "", "AmazonEKSCapabilities-ArgoCD-Hours:perCapability"),
+			"not json",
+		}},
+	}}
+}
+
+func TestDiscoverRegions(t *testing.T) {
+	regions, err := DiscoverRegions(context.Background(), regionsAPI())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Region{
+		{"us-east-1", "US East (N. Virginia)"},
+		{"eu-west-1", "Europe (Ireland)"},
+		{"mx-central-1", "Mexico (Central)"},
+		{"aa-new-1", "Somewhere New"},
+		{"xx-new-1", ""},
+	}
+	if len(regions) != len(want) {
+		t.Fatalf("expected %v, got %v", want, regions)
+	}
+	for i := range want {
+		if regions[i] != want[i] {
+			t.Errorf("region %d: expected %v, got %v", i, want[i], regions[i])
+		}
+	}
+}
+
+func TestDiscoverRegionsPaginated(t *testing.T) {
+	mock := &paginatedMockPricingAPI{pages: []map[string]*pricing.GetProductsOutput{
+		{regionsKey: {PriceList: []string{regionProductJSON("us-west-2", "US West (Oregon)", "USW2-AmazonEKSCapabilities-ACK-Hours:perCapability")}}},
+		{regionsKey: {PriceList: []string{regionProductJSON("ca-west-1", "Canada West (Calgary)", "CAW1-AmazonEKSCapabilities-ACK-Hours:perCapability")}}},
+	}}
+	regions, err := DiscoverRegions(context.Background(), mock)
+	if err != nil || len(regions) != 2 || regions[0].Code != "us-west-2" || regions[1].Code != "ca-west-1" {
+		t.Errorf("expected regions from both pages, got %v, %v", regions, err)
+	}
+}
+
+func TestDiscoverRegionsErrors(t *testing.T) {
+	if _, err := DiscoverRegions(context.Background(), &mockPricingAPI{err: errors.New("denied")}); err == nil || err.Error() != "discovering regions: denied" {
+		t.Errorf("expected the API error, got %v", err)
+	}
+	if _, err := DiscoverRegions(context.Background(), &mockPricingAPI{}); !errors.Is(err, ErrNoRegions) {
+		t.Errorf("expected ErrNoRegions, got %v", err)
+	}
+}
+
+func TestBuiltinRegions(t *testing.T) {
+	regions := BuiltinRegions()
+	if regions[0].Code != "us-east-1" {
+		t.Errorf("expected us-east-1 first, got %v", regions[0])
+	}
+	seen := make(map[string]bool)
+	for _, r := range regions {
+		if r.Name == "" || seen[r.Code] {
+			t.Errorf("bad built-in region %v", r)
+		}
+		seen[r.Code] = true
+	}
+	for _, code := range []string{"ap-southeast-3", "ap-southeast-4", "ap-southeast-5", "il-central-1", "mx-central-1", "ca-west-1"} {
+		if !seen[code] {
+			t.Errorf("missing %s", code)
+		}
+	}
+
+	regions[0].Code = "changed"
+	if BuiltinRegions()[0].Code != "us-east-1" {
+		t.Error("BuiltinRegions should return a copy")
+	}
+}
+
+func TestRegionName(t *testing.T) {
+	if got := RegionName("il-central-1"); got != "Israel (Tel Aviv)" {
+		t.Errorf("unexpected name %q", got)
+	}
+	if got := RegionName("xx-new-1"); got != "" {
+		t.Errorf("expected no name for an unknown region, got %q", got)
+	}
+}
+
+func TestCacheRegions(t *testing.T) {
+	c := newTestCache(t)
+	if c.LoadRegions() != nil {
+		t.Error("expected no cached regions")
+	}
+
+	regions := []Region{{"us-east-1", "US East (N. Virginia)"}}
+	if err := c.SaveRegions(regions); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.LoadRegions(); len(got) != 1 || got[0] != regions[0] {
+		t.Errorf("expected the saved regions, got %v", got)
+	}
+
+	c.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	if c.LoadRegions() != nil {
+		t.Error("expected expired regions to be ignored")
+	}
+
+	for _, content := range []string{"nope", `{"regions": [], "fetched_at": "2026-10-18T00:00:00Z"}`} {
+		if err := os.WriteFile(c.regionsPath(), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if c.LoadRegions() != nil {
+			t.Errorf("%s: expected nil", content)
+		}
+	}
+}
+
+func TestCacheSaveRegionsErrors(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Cache{dir: filepath.Join(blocker, "sub"), now: time.Now}).SaveRegions(BuiltinRegions()); err == nil {
+		t.Error("expected a mkdir error")
+	}
+
+	orig := cacheJSONMarshal
+	defer func() { cacheJSONMarshal = orig }()
+	cacheJSONMarshal = func(any) ([]byte, error) { return nil, errors.New("marshal error") }
+	if err := newTestCache(t).SaveRegions(BuiltinRegions()); err == nil || err.Error() != "marshal error" {
+		t.Errorf("expected the marshal error, got %v", err)
+	}
+}
+
+func TestRegions(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache(t)
+
+	regions, err := Regions(ctx, regionsAPI(), c)
+	if err != nil || len(regions) != 5 {
+		t.Fatalf("expected discovered regions, got %v, %v", regions, err)
+	}
+	if cached := c.LoadRegions(); len(cached) != 5 {
+		t.Errorf("expected the regions cached, got %v", cached)
+	}
+
+	// A fresh cache entry is used without calling the API.
+	regions, err = Regions(ctx, &mockPricingAPI{err: errors.New("should not be called")}, c)
+	if err != nil || len(regions) != 5 {
+		t.Errorf("expected cached regions, got %v, %v", regions, err)
+	}
+
+	regions, err = Regions(ctx, &mockPricingAPI{err: errors.New("offline")}, nil)
+	if err == nil || len(regions) != len(BuiltinRegions()) {
+		t.Errorf("expected the built-in regions and the error, got %d regions, %v", len(regions), err)
+	}
+
+	if regions, err := Regions(ctx, regionsAPI(), nil); err != nil || len(regions) != 5 {
+		t.Errorf("expected discovery without a cache, got %v, %v", regions, err)
+	}
+}
+
+func TestRegionsDefaultClient(t *testing.T) {
+	origLoad, origNew := loadDefaultConfig, newPricingClient
+	defer func() { loadDefaultConfig, newPricingClient = origLoad, origNew }()
+
+	loadDefaultConfig = func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{}, fmt.Errorf("no creds")
+	}
+	regions, err := Regions(context.Background(), nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "no creds") || len(regions) != len(BuiltinRegions()) {
+		t.Errorf("expected the built-in regions and the config error, got %d regions, %v", len(regions), err)
+	}
+
+	loadDefaultConfig = func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{}, nil
+	}
+	newPricingClient = func(cfg aws.Config) PricingAPI { return regionsAPI() }
+	if regions, err := Regions(context.Background(), nil, nil); err != nil || len(regions) != 5 {
+		t.Errorf("expected regions from the default client, got %v, %v", regions, err)
+	}
+}
@@ -0,0 +1,146 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+// Retry policy for clients wrapped by NewRetryingClient. A throttled or
+// transient failure is retried up to retryMaxAttempts calls in total, after
+// a random delay of up to retryBaseDelay doubled on every attempt and capped
+// at retryMaxDelay ("full jitter"). Each attempt that has not answered
+// within retryAttemptTimeout fails like an unreachable endpoint.
+var (
+	retryMaxAttempts    = 5
+	retryBaseDelay      = 250 * time.Millisecond
+	retryMaxDelay       = 8 * time.Second
+	retryAttemptTimeout = 5 * time.Second
+)
+
+// apiRequestsPerSecond is the client-side limit shared by every retrying
+// client, comfortably below the Pricing API's throttling threshold.
+const apiRequestsPerSecond = 5
+
+// apiLimiter is shared by every retrying client, so that concurrent fetches
+// (for example while warming the cache) do not add up past the limit.
+var apiLimiter = newLimiter(apiRequestsPerSecond)
+
+// sleepCtx and jitter are package-level vars for testing.
+var (
+	sleepCtx = func(ctx context.Context, d time.Duration) error {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	jitter = rand.Float64
+)
+
+// NewRetryingClient wraps client so that every call first waits for the
+// package's shared rate limiter, and throttled or transient failures (see
+// Classify) are retried with jittered exponential backoff. An attempt that
+// hangs times out and is retried too. Other failures and cancellation of
+// the call's context are returned at once.
+//
+// Clients created by this package from the default credential chain are
+// already wrapped, with the SDK's own retries turned off.
+func NewRetryingClient(client PricingAPI) PricingAPI {
+	return retryingClient{client: client, limiter: apiLimiter}
+}
+
+type retryingClient struct {
+	client  PricingAPI
+	limiter *limiter
+}
+
+func (c retryingClient) GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if waitErr := c.limiter.wait(ctx); waitErr != nil {
+			return nil, errors.Join(err, waitErr)
+		}
+
+		var output *pricing.GetProductsOutput
+		output, err = c.attempt(ctx, params, optFns...)
+		if err == nil || !retryable(err) || ctx.Err() != nil || attempt == retryMaxAttempts {
+			return output, err
+		}
+
+		if sleepErr := sleepCtx(ctx, backoff(attempt)); sleepErr != nil {
+			return nil, errors.Join(err, sleepErr)
+		}
+	}
+}
+
+// attempt makes one call under its own deadline, so that a hung endpoint
+// leaves time for the retries and failover that follow.
+func (c retryingClient) attempt(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, retryAttemptTimeout)
+	defer cancel()
+	return c.client.GetProducts(ctx, params, optFns...)
+}
+
+// CallTimeout returns the longest a Client can take over one call for
+// region's prices before giving up: every attempt at every endpoint of the
+// region's partition running to its deadline, after the longest backoffs.
+// A fetch bounded by less may be cut off before failing over.
+func CallTimeout(region string) time.Duration {
+	perEndpoint := time.Duration(retryMaxAttempts) * (retryAttemptTimeout + apiLimiter.interval)
+	for n := 1; n < retryMaxAttempts; n++ {
+		perEndpoint += min(retryBaseDelay<<(n-1), retryMaxDelay)
+	}
+	return time.Duration(len(PartitionOf(region).Endpoints)) * perEndpoint
+}
+
+// retryable reports whether a failed call may succeed if repeated.
+func retryable(err error) bool {
+	kind := Classify(err)
+	return kind == ErrorThrottled || kind == ErrorNetwork
+}
+
+// backoff returns the delay before the retry that follows attempt n.
+func backoff(n int) time.Duration {
+	d := retryMaxDelay
+	if n < 16 && retryBaseDelay<<(n-1) < retryMaxDelay {
+		d = retryBaseDelay << (n - 1)
+	}
+	return time.Duration(jitter() * float64(d))
+}
+
+// limiter spaces calls evenly, at most perSecond a second, across every
+// goroutine that shares it.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(perSecond float64) *limiter {
+	return &limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the caller's turn, or until ctx is done.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := timeNow()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	if d := at.Sub(now); d > 0 {
+		return sleepCtx(ctx, d)
+	}
+	return nil
+}
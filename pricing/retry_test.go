@@ -0,0 +1,242 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+// flakyClient fails with each of errs in turn, then succeeds.
+type flakyClient struct {
+	errs  []error
+	calls int
+}
+
+func (c *flakyClient) GetProducts(context.Context, *pricing.GetProductsInput, ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	c.calls++
+	if c.calls <= len(c.errs) {
+		return nil, c.errs[c.calls-1]
+	}
+	return &pricing.GetProductsOutput{}, nil
+}
+
+// fakeSleep records requested sleeps instead of sleeping, and fails with
+// err once set.
+func fakeSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var slept []time.Duration
+	var mu sync.Mutex
+	orig := sleepCtx
+	sleepCtx = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		slept = append(slept, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleepCtx = orig })
+	return &slept
+}
+
+func newTestRetryingClient(client PricingAPI) retryingClient {
+	return retryingClient{client: client, limiter: &limiter{}}
+}
+
+func TestRetryingClientRetriesThrottling(t *testing.T) {
+	slept := fakeSleep(t)
+	origJitter := jitter
+	jitter = func() float64 { return 1 }
+	t.Cleanup(func() { jitter = origJitter })
+
+	client := &flakyClient{errs: []error{apiError{"ThrottlingException"}, sendError{}, statusError{503}}}
+	if _, err := newTestRetryingClient(client).GetProducts(context.Background(), &pricing.GetProductsInput{}); err != nil {
+		t.Fatalf("GetProducts: %v", err)
+	}
+	if client.calls != 4 {
+		t.Errorf("expected 4 calls, got %d", client.calls)
+	}
+	want := []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, time.Second}
+	if len(*slept) != len(want) {
+		t.Fatalf("expected backoffs %v, got %v", want, *slept)
+	}
+	for i := range want {
+		if (*slept)[i] != want[i] {
+			t.Errorf("backoff %d: got %v, want %v", i, (*slept)[i], want[i])
+		}
+	}
+}
+
+func TestRetryingClientGivesUp(t *testing.T) {
+	fakeSleep(t)
+	throttled := apiError{"ThrottlingException"}
+	client := &flakyClient{errs: []error{throttled, throttled, throttled, throttled, throttled, throttled}}
+
+	_, err := newTestRetryingClient(client).GetProducts(context.Background(), &pricing.GetProductsInput{})
+	if Classify(err) != ErrorThrottled {
+		t.Errorf("expected the throttling error, got %v", err)
+	}
+	if client.calls != retryMaxAttempts {
+		t.Errorf("expected %d calls, got %d", retryMaxAttempts, client.calls)
+	}
+}
+
+func TestRetryingClientDoesNotRetryPermanentErrors(t *testing.T) {
+	slept := fakeSleep(t)
+	client := &flakyClient{errs: []error{apiError{"AccessDeniedException"}}}
+
+	_, err := newTestRetryingClient(client).GetProducts(context.Background(), &pricing.GetProductsInput{})
+	if Classify(err) != ErrorAuth || client.calls != 1 || len(*slept) != 0 {
+		t.Errorf("expected one call and no retry, got %d calls, %v sleeps, err %v", client.calls, *slept, err)
+	}
+}
+
+func TestRetryingClientStopsWhenCancelled(t *testing.T) {
+	fakeSleep(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := &flakyClient{errs: []error{sendError{}}}
+
+	_, err := newTestRetryingClient(client).GetProducts(ctx, &pricing.GetProductsInput{})
+	if client.calls != 1 || Classify(err) != ErrorNetwork {
+		t.Errorf("expected one call, got %d calls and %v", client.calls, err)
+	}
+}
+
+// hangingClient blocks its first hangs calls until their context is done,
+// then succeeds.
+type hangingClient struct {
+	hangs int
+	calls int
+}
+
+func (c *hangingClient) GetProducts(ctx context.Context, _ *pricing.GetProductsInput, _ ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	c.calls++
+	if c.calls <= c.hangs {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &pricing.GetProductsOutput{}, nil
+}
+
+func TestRetryingClientTimesOutAttempts(t *testing.T) {
+	fakeSleep(t)
+	orig := retryAttemptTimeout
+	retryAttemptTimeout = time.Millisecond
+	t.Cleanup(func() { retryAttemptTimeout = orig })
+
+	client := &hangingClient{hangs: 1}
+	if _, err := newTestRetryingClient(client).GetProducts(context.Background(), &pricing.GetProductsInput{}); err != nil || client.calls != 2 {
+		t.Errorf("expected the hung attempt retried, got %d calls and %v", client.calls, err)
+	}
+
+	client = &hangingClient{hangs: retryMaxAttempts}
+	_, err := newTestRetryingClient(client).GetProducts(context.Background(), &pricing.GetProductsInput{})
+	if !errors.Is(err, context.DeadlineExceeded) || Classify(err) != ErrorNetwork || !failsOver(context.Background(), err) {
+		t.Errorf("expected a network error to fail over from, got %v", err)
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	// 5 attempts of 5s plus the 200ms limiter interval, and backoffs of
+	// 250ms, 500ms, 1s and 2s: 29.75s per endpoint.
+	perEndpoint := 29750 * time.Millisecond
+	if got := CallTimeout("us-east-1"); got != 3*perEndpoint {
+		t.Errorf("expected 3 endpoints' budget, got %v", got)
+	}
+	if got := CallTimeout("cn-north-1"); got != perEndpoint {
+		t.Errorf("expected 1 endpoint's budget, got %v", got)
+	}
+}
+
+func TestRetryingClientBackoffInterrupted(t *testing.T) {
+	orig := sleepCtx
+	sleepCtx = func(context.Context, time.Duration) error { return context.DeadlineExceeded }
+	t.Cleanup(func() { sleepCtx = orig })
+	client := &flakyClient{errs: []error{apiError{"ThrottlingException"}}}
+
+	_, err := newTestRetryingClient(client).GetProducts(context.Background(), &pricing.GetProductsInput{})
+	if !errors.Is(err, context.DeadlineExceeded) || Classify(err) != ErrorThrottled {
+		t.Errorf("expected the throttling and deadline errors, got %v", err)
+	}
+}
+
+func TestRetryingClientLimiterInterrupted(t *testing.T) {
+	orig := sleepCtx
+	sleepCtx = func(context.Context, time.Duration) error { return context.Canceled }
+	t.Cleanup(func() { sleepCtx = orig })
+	l := newLimiter(1)
+	l.next = time.Now().Add(time.Hour)
+	client := &flakyClient{}
+
+	_, err := retryingClient{client: client, limiter: l}.GetProducts(context.Background(), &pricing.GetProductsInput{})
+	if !errors.Is(err, context.Canceled) || client.calls != 0 {
+		t.Errorf("expected cancellation before any call, got %d calls and %v", client.calls, err)
+	}
+}
+
+func TestBackoffCapped(t *testing.T) {
+	origJitter := jitter
+	jitter = func() float64 { return 1 }
+	t.Cleanup(func() { jitter = origJitter })
+
+	if d := backoff(1); d != retryBaseDelay {
+		t.Errorf("first backoff: got %v, want %v", d, retryBaseDelay)
+	}
+	for _, n := range []int{6, 20, 100} {
+		if d := backoff(n); d != retryMaxDelay {
+			t.Errorf("backoff(%d): got %v, want the cap %v", n, d, retryMaxDelay)
+		}
+	}
+
+	jitter = func() float64 { return 0.5 }
+	if d := backoff(2); d != 250*time.Millisecond {
+		t.Errorf("jittered backoff: got %v, want 250ms", d)
+	}
+}
+
+func TestLimiterSpacesCalls(t *testing.T) {
+	slept := fakeSleep(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	origNow := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = origNow })
+
+	l := newLimiter(5)
+	for range 3 {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	want := []time.Duration{200 * time.Millisecond, 400 * time.Millisecond}
+	if len(*slept) != 2 || (*slept)[0] != want[0] || (*slept)[1] != want[1] {
+		t.Errorf("expected waits %v, got %v", want, *slept)
+	}
+
+	// After a quiet period the next call goes straight through.
+	now = now.Add(time.Minute)
+	if err := l.wait(context.Background()); err != nil || len(*slept) != 2 {
+		t.Errorf("expected no wait after a quiet period, got %v, %v", *slept, err)
+	}
+}
+
+func TestSleepCtx(t *testing.T) {
+	if err := sleepCtx(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("sleep: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sleepCtx(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+}
+
+func TestNewRetryingClientSharesLimiter(t *testing.T) {
+	a := NewRetryingClient(&flakyClient{}).(retryingClient)
+	b := NewRetryingClient(&flakyClient{}).(retryingClient)
+	if a.limiter != apiLimiter || b.limiter != apiLimiter {
+		t.Error("retrying clients should share the package limiter")
+	}
+}
@@ -0,0 +1,150 @@
+package pricing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnomalyKind classifies why a rate looks wrong.
+type AnomalyKind int
+
+const (
+	// AnomalyZero means the rate is zero or negative.
+	AnomalyZero AnomalyKind = iota
+	// AnomalyUnit means AWS priced the rate in a unit other than the one
+	// its field is measured in, such as seconds for an hourly EKS rate.
+	AnomalyUnit
+	// AnomalyHigh means the rate is more than anomalyFactor times its
+	// built-in default.
+	AnomalyHigh
+	// AnomalyLow means the rate is less than its built-in default divided
+	// by anomalyFactor.
+	AnomalyLow
+)
+
+// String returns a short name for the kind.
+func (k AnomalyKind) String() string {
+	switch k {
+	case AnomalyUnit:
+		return "unexpected unit"
+	case AnomalyHigh:
+		return "too high"
+	case AnomalyLow:
+		return "too low"
+	default:
+		return "zero"
+	}
+}
+
+// anomalyFactor is how far a rate may stray from its default, either way,
+// before it is suspect. AWS prices differ by region, but not by an order of
+// magnitude.
+const anomalyFactor = 10
+
+// fieldUnits lists, for each field, the substrings one of which the
+// lowercased unit of its price must contain. Fargate is priced per second
+// in some regions.
+var fieldUnits = map[Field][]string{
+	FieldArgoCDBasePerHour:   {"hr", "hour"},
+	FieldArgoCDAppPerHour:    {"hr", "hour"},
+	FieldACKBasePerHour:      {"hr", "hour"},
+	FieldACKResourcePerHour:  {"hr", "hour"},
+	FieldKroBasePerHour:      {"hr", "hour"},
+	FieldKroRGDPerHour:       {"hr", "hour"},
+	FieldFargateVCPUPerHour:  {"hr", "hour", "second"},
+	FieldFargateMemGBPerHour: {"hr", "hour", "second"},
+	FieldALBPerHour:          {"hr", "hour"},
+	FieldALBLCUPerHour:       {"hr", "hour"},
+	FieldEBSGBMonth:          {"mo"},
+	FieldCloudWatchLogsPerGB: {"gb"},
+}
+
+// Anomaly is a rate that looks wrong next to DefaultRates.
+type Anomaly struct {
+	Field Field
+	Kind  AnomalyKind
+
+	// Value is the suspect rate and Default the built-in one.
+	Value, Default float64
+
+	// Unit is the unit the rate was priced in.
+	Unit string
+
+	// Source names the source that supplied the rate.
+	Source string
+
+	// Rejected reports whether a Chain set to reject suspect rates took
+	// the field from a later source instead.
+	Rejected bool
+}
+
+// String describes the anomaly, e.g. "ArgoCDBasePerHour is 3, 100x the
+// default 0.03".
+func (a Anomaly) String() string {
+	switch a.Kind {
+	case AnomalyUnit:
+		return fmt.Sprintf("%s is priced per %q", a.Field, a.Unit)
+	case AnomalyHigh:
+		return fmt.Sprintf("%s is %g, %.0fx the default %g", a.Field, a.Value, a.Value/a.Default, a.Default)
+	case AnomalyLow:
+		return fmt.Sprintf("%s is %g, 1/%.0f of the default %g", a.Field, a.Value, a.Default/a.Value, a.Default)
+	default:
+		return fmt.Sprintf("%s is %g", a.Field, a.Value)
+	}
+}
+
+// CheckRates returns the anomalies among the rates the Pricing API, the
+// cache, offer files and other sources supplied, in field order. Overrides
+// and built-in defaults are trusted, and fields without provenance are not
+// checked. Rates in a currency other than USD are not compared with the
+// defaults, which are in USD.
+func CheckRates(r RateSheet) []Anomaly {
+	var anomalies []Anomaly
+	for _, f := range Fields() {
+		p, ok := r.Provenance[f]
+		if !ok {
+			continue
+		}
+		if a, suspect := checkRate(f, r.Get(f), p, r.CurrencyCode()); suspect {
+			anomalies = append(anomalies, a)
+		}
+	}
+	return anomalies
+}
+
+// checkRate reports whether the rate v of field f, with provenance p, is
+// suspect, and why.
+func checkRate(f Field, v float64, p Provenance, currency string) (Anomaly, bool) {
+	if p.Source == SourceOverrides || p.Source == SourceDefaults {
+		return Anomaly{}, false
+	}
+	def := DefaultRates().Get(f)
+	a := Anomaly{Field: f, Value: v, Default: def, Unit: p.Unit, Source: p.Source}
+
+	switch {
+	case v <= 0:
+		a.Kind = AnomalyZero
+	case p.Unit != "" && !unitMatches(f, p.Unit):
+		a.Kind = AnomalyUnit
+	case currency != "USD":
+		return Anomaly{}, false
+	case v > def*anomalyFactor:
+		a.Kind = AnomalyHigh
+	case v < def/anomalyFactor:
+		a.Kind = AnomalyLow
+	default:
+		return Anomaly{}, false
+	}
+	return a, true
+}
+
+// unitMatches reports whether unit is one field f may be priced in.
+func unitMatches(f Field, unit string) bool {
+	unit = strings.ToLower(unit)
+	for _, want := range fieldUnits[f] {
+		if strings.Contains(unit, want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package pricing
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCheckRates(t *testing.T) {
+	api := func(unit string) Provenance { return Provenance{Source: SourceAPI, Unit: unit} }
+	rates := RateSheet{Rates: DefaultRates()}
+	rates.ArgoCDBasePerHour = 3       // 100x
+	rates.ArgoCDAppPerHour = 0.00001  // 1/150
+	rates.ACKBasePerHour = 0.04       // 8x: within bounds
+	rates.FargateVCPUPerHour = 0      // zero
+	rates.KroBasePerHour = 0.005      // per second
+	rates.FargateMemGBPerHour = 0.004 // per second is fine for Fargate
+	rates.ALBPerHour = 99             // an override is trusted
+	rates.EBSGBMonth = 99             // so is a default
+	rates.CloudWatchLogsPerGB = 99    // and a field without provenance
+	rates.Provenance = map[Field]Provenance{
+		FieldArgoCDBasePerHour:   api("Hrs"),
+		FieldArgoCDAppPerHour:    api(""),
+		FieldACKBasePerHour:      {Source: SourceCache},
+		FieldFargateVCPUPerHour:  api("hours"),
+		FieldKroBasePerHour:      api("Seconds"),
+		FieldFargateMemGBPerHour: api("Second"),
+		FieldALBPerHour:          {Source: SourceOverrides},
+		FieldEBSGBMonth:          {Source: SourceDefaults},
+	}
+
+	got := CheckRates(rates)
+	want := []Anomaly{
+		{Field: FieldArgoCDBasePerHour, Kind: AnomalyHigh, Value: 3, Default: 0.03, Unit: "Hrs", Source: SourceAPI},
+		{Field: FieldArgoCDAppPerHour, Kind: AnomalyLow, Value: 0.00001, Default: 0.0015, Source: SourceAPI},
+		{Field: FieldKroBasePerHour, Kind: AnomalyUnit, Value: 0.005, Default: 0.005, Unit: "Seconds", Source: SourceAPI},
+		{Field: FieldFargateVCPUPerHour, Kind: AnomalyZero, Value: 0, Default: 0.04048, Unit: "hours", Source: SourceAPI},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	// Rates in another currency are not compared with the USD defaults.
+	rates.Currency = "CNY"
+	if got := CheckRates(rates); len(got) != 2 || got[0].Kind != AnomalyUnit || got[1].Kind != AnomalyZero {
+		t.Errorf("expected only the unit and zero anomalies in CNY, got %+v", got)
+	}
+
+	if got := CheckRates(RateSheet{Rates: DefaultRates()}); got != nil {
+		t.Errorf("expected hand-built rates unchecked, got %+v", got)
+	}
+}
+
+func TestAnomalyString(t *testing.T) {
+	tests := []struct {
+		a    Anomaly
+		want string
+	}{
+		{Anomaly{Field: FieldArgoCDBasePerHour, Kind: AnomalyHigh, Value: 3, Default: 0.03}, "ArgoCDBasePerHour is 3, 100x the default 0.03"},
+		{Anomaly{Field: FieldArgoCDBasePerHour, Kind: AnomalyLow, Value: 0.0003, Default: 0.03}, "ArgoCDBasePerHour is 0.0003, 1/100 of the default 0.03"},
+		{Anomaly{Field: FieldKroBasePerHour, Kind: AnomalyUnit, Unit: "Seconds"}, `KroBasePerHour is priced per "Seconds"`},
+		{Anomaly{Field: FieldALBPerHour, Kind: AnomalyZero}, "ALBPerHour is 0"},
+	}
+	for _, tt := range tests {
+		if got := tt.a.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+
+	kinds := map[AnomalyKind]string{AnomalyZero: "zero", AnomalyUnit: "unexpected unit", AnomalyHigh: "too high", AnomalyLow: "too low"}
+	for k, want := range kinds {
+		if k.String() != want {
+			t.Errorf("%d: got %q, want %q", k, k.String(), want)
+		}
+	}
+}
+
+func TestChainSuspectRates(t *testing.T) {
+	suspect := NewSource("suspect", func(context.Context, string) (RateSheet, error) {
+		return RateSheet{Rates: Rates{ArgoCDBasePerHour: 3, ArgoCDAppPerHour: 0.002}}, nil
+	})
+	chain := NewChain(suspect, DefaultsSource())
+
+	// Accepted by default, and reported.
+	res, err := chain.Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rates.ArgoCDBasePerHour != 3 || len(res.Anomalies) != 1 || res.Anomalies[0].Rejected || res.Anomalies[0].Source != "suspect" {
+		t.Errorf("expected the suspect rate accepted and reported, got %+v and %+v", res.Rates, res.Anomalies)
+	}
+
+	// Rejected, the field comes from the defaults.
+	chain.SetRejectSuspect(true)
+	if !chain.RejectSuspect() {
+		t.Error("expected the chain to reject suspect rates")
+	}
+	res, err = chain.Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rates.ArgoCDBasePerHour != 0.03 || res.Rates.Provenance[FieldArgoCDBasePerHour].Source != SourceDefaults ||
+		res.Rates.ArgoCDAppPerHour != 0.002 || len(res.Anomalies) != 1 || !res.Anomalies[0].Rejected {
+		t.Errorf("expected the suspect rate rejected, got %+v and %+v", res.Rates, res.Anomalies)
+	}
+
+	built, err := BuildChain([]string{SourceDefaults}, ChainConfig{Cache: NewCacheDir(t.TempDir()), RejectSuspect: true})
+	if err != nil || !built.RejectSuspect() {
+		t.Errorf("expected BuildChain to apply RejectSuspect, got %v", err)
+	}
+}
+
+func TestFetchRatesRecordsUnit(t *testing.T) {
+	mock := &mockPricingAPI{responses: allCapabilityProducts("us-east-1")}
+	rates, err := APISource(mock, nil).Lookup(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := rates.Provenance[FieldArgoCDBasePerHour].Unit; u != "Hour" {
+		t.Errorf("expected the EKS unit recorded, got %q", u)
+	}
+	if u := rates.Provenance[FieldFargateVCPUPerHour].Unit; u != "Second" {
+		t.Errorf("expected the Fargate unit recorded, got %q", u)
+	}
+	if got := CheckRates(rates); got != nil {
+		t.Errorf("expected no anomalies, got %+v", got)
+	}
+
+	if got := (Schedule{{Rate: 0}, {Rate: 1, Unit: "Seconds"}}).unit(); got != "Seconds" {
+		t.Errorf("expected the unit of the priced tier, got %q", got)
+	}
+	if got := (Schedule{{Rate: 0, Unit: "Hrs"}}).unit(); got != "" {
+		t.Errorf("expected no unit without a priced tier, got %q", got)
+	}
+}
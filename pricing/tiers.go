@@ -0,0 +1,155 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+)
+
+// Tier is one price dimension of a product: the rate charged for usage
+// between BeginRange and EndRange, measured in Unit.
+type Tier struct {
+	BeginRange float64 `json:"begin_range"`
+	EndRange   float64 `json:"end_range,omitempty"` // zero means no upper bound
+
+	// Rate is the price per unit, in the currency of the Rates it belongs
+	// to. Per-second prices are converted to per-hour, together with their
+	// ranges.
+	Rate        float64 `json:"rate"`
+	Unit        string  `json:"unit,omitempty"`
+	Description string  `json:"description,omitempty"`
+}
+
+// Schedule is the price dimensions of a product, ordered by BeginRange. A
+// product with a single flat price has a one-tier schedule.
+type Schedule []Tier
+
+// Rate returns the headline rate of the schedule: that of the first tier
+// with a positive price, so that a free allowance does not read as a free
+// product. Zero if no tier is priced.
+func (s Schedule) Rate() float64 {
+	for _, t := range s {
+		if t.Rate > 0 {
+			return t.Rate
+		}
+	}
+	return 0
+}
+
+// unit returns the unit of the tier Rate is read from, or "" if no tier is
+// priced.
+func (s Schedule) unit() string {
+	for _, t := range s {
+		if t.Rate > 0 {
+			return t.Unit
+		}
+	}
+	return ""
+}
+
+// RateTiers converts the schedule for calculator.ScenarioInput.ResourceTiers.
+func (s Schedule) RateTiers() []calculator.RateTier {
+	tiers := make([]calculator.RateTier, len(s))
+	for i, t := range s {
+		tiers[i] = calculator.RateTier{From: t.BeginRange, To: t.EndRange, PerHour: t.Rate}
+	}
+	return tiers
+}
+
+// errNoOnDemand is returned for products without an OnDemand price in the
+// currency of their partition.
+var errNoOnDemand = errors.New("no OnDemand pricing found")
+
+// parseSchedule reads every price dimension in the currency code of a
+// product's OnDemand term into a schedule. Products rarely carry more than one OnDemand term;
+// when they do, the first by term code is used so that the result does not
+// depend on map iteration order. Dimensions are ordered by BeginRange, then
+// by dimension code.
+func parseSchedule(doc productDoc, code string) (Schedule, error) {
+	for _, termCode := range sortedKeys(doc.Terms.OnDemand) {
+		dims := doc.Terms.OnDemand[termCode].PriceDimensions
+
+		var s Schedule
+		for _, dimCode := range sortedKeys(dims) {
+			t, ok, err := parseTier(dims[dimCode], code)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				s = append(s, t)
+			}
+		}
+		if len(s) > 0 {
+			sort.SliceStable(s, func(i, j int) bool { return s[i].BeginRange < s[j].BeginRange })
+			return s, nil
+		}
+	}
+	return nil, errNoOnDemand
+}
+
+// parseTier converts a price dimension, reporting false if it has no price
+// in the currency code.
+func parseTier(dim priceDimension, code string) (Tier, bool, error) {
+	priceStr, ok := dim.PricePerUnit[code]
+	if !ok {
+		return Tier{}, false, nil
+	}
+
+	rate, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil {
+		return Tier{}, false, fmt.Errorf("parsing %s rate %q: %w", code, priceStr, err)
+	}
+	begin, err := parseRange(dim.BeginRange)
+	if err != nil {
+		return Tier{}, false, fmt.Errorf("parsing beginRange %q: %w", dim.BeginRange, err)
+	}
+	end, err := parseRange(dim.EndRange)
+	if err != nil {
+		return Tier{}, false, fmt.Errorf("parsing endRange %q: %w", dim.EndRange, err)
+	}
+
+	// Fargate pricing is per-second; convert to per-hour
+	if strings.EqualFold(dim.Unit, "Second") || strings.EqualFold(dim.Unit, "Seconds") {
+		rate *= 3600
+		begin /= 3600
+		end /= 3600
+	}
+
+	return Tier{BeginRange: begin, EndRange: end, Rate: rate, Unit: dim.Unit, Description: dim.Description}, true, nil
+}
+
+// parseRange parses a beginRange or endRange. Missing and "Inf" bounds
+// are zero.
+func parseRange(s string) (float64, error) {
+	if s == "" || strings.EqualFold(s, "Inf") {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setTiered stores v in field f with its provenance and, when tiers has more
+// than one tier, the full schedule. A flat rate clears any schedule f had.
+func (s *RateSheet) setTiered(f Field, v float64, tiers Schedule, p Provenance) {
+	s.setWithProvenance(f, v, p)
+	if len(tiers) < 2 {
+		return
+	}
+	if s.Tiers == nil {
+		s.Tiers = make(map[Field]Schedule)
+	}
+	s.Tiers[f] = slices.Clone(tiers)
+}
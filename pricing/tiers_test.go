@@ -0,0 +1,254 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/josegonzalez/aws-eks-calculator/calculator"
+)
+
+// tieredDimensions is a per-resource price in three volume tiers, listed
+// out of order so that parsing must sort them.
+const tieredDimensions = `{
+	"T3": {"beginRange": "5000000", "endRange": "Inf", "unit": "Hrs", "description": "$0.00025 per CR-hour over 5M", "pricePerUnit": {"USD": "0.0002500000"}},
+	"T1": {"beginRange": "0", "endRange": "1000000", "unit": "Hrs", "description": "$0.0015 per CR-hour for the first 1M", "pricePerUnit": {"USD": "0.0015000000"}},
+	"T2": {"beginRange": "1000000", "endRange": "5000000", "unit": "Hrs", "description": "$0.0010 per CR-hour for the next 4M", "pricePerUnit": {"USD": "0.0010000000"}}
+}`
+
+func tieredProductJSON(usagetype string) string {
+	return `{
+		"product": {"attributes": {"usagetype": "` + usagetype + `"}},
+		"terms": {"OnDemand": {"offer1": {"priceDimensions": ` + tieredDimensions + `}}}
+	}`
+}
+
+var wantSchedule = Schedule{
+	{BeginRange: 0, EndRange: 1000000, Rate: 0.0015, Unit: "Hrs", Description: "$0.0015 per CR-hour for the first 1M"},
+	{BeginRange: 1000000, EndRange: 5000000, Rate: 0.001, Unit: "Hrs", Description: "$0.0010 per CR-hour for the next 4M"},
+	{BeginRange: 5000000, Rate: 0.00025, Unit: "Hrs", Description: "$0.00025 per CR-hour over 5M"},
+}
+
+func parseDoc(t *testing.T, priceJSON string) productDoc {
+	t.Helper()
+	var doc productDoc
+	if err := json.Unmarshal([]byte(priceJSON), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return doc
+}
+
+func TestParseScheduleTiers(t *testing.T) {
+	// Map iteration order varies between runs; the schedule must not.
+	for range 20 {
+		s, err := parseSchedule(parseDoc(t, tieredProductJSON("USE1-AmazonEKSCapabilities-ACK-CR-Hours:perCustomResource")), "USD")
+		if err != nil {
+			t.Fatalf("parseSchedule: %v", err)
+		}
+		if len(s) != len(wantSchedule) {
+			t.Fatalf("got %d tiers, want %d", len(s), len(wantSchedule))
+		}
+		for i := range s {
+			if s[i] != wantSchedule[i] {
+				t.Errorf("tier %d: got %+v, want %+v", i, s[i], wantSchedule[i])
+			}
+		}
+	}
+}
+
+func TestParseSchedulePerSecond(t *testing.T) {
+	s, err := parseSchedule(parseDoc(t, `{"terms": {"OnDemand": {"o": {"priceDimensions": {
+		"d": {"beginRange": "0", "endRange": "36000", "unit": "Second", "pricePerUnit": {"USD": "0.00001"}}
+	}}}}}`), "USD")
+	if err != nil {
+		t.Fatalf("parseSchedule: %v", err)
+	}
+	if s[0].EndRange != 10 || s[0].Rate < 0.0359 || s[0].Rate > 0.0361 {
+		t.Errorf("expected the range and rate converted to hours, got %+v", s[0])
+	}
+}
+
+func TestParseScheduleFirstTermWins(t *testing.T) {
+	doc := parseDoc(t, `{"terms": {"OnDemand": {
+		"B": {"priceDimensions": {"d": {"pricePerUnit": {"USD": "0.2"}}}},
+		"A": {"priceDimensions": {"d": {"pricePerUnit": {"USD": "0.1"}}}},
+		"0": {"priceDimensions": {"d": {"pricePerUnit": {"EUR": "0.3"}}}}
+	}}}`)
+	for range 20 {
+		s, err := parseSchedule(doc, "USD")
+		if err != nil || len(s) != 1 || s[0].Rate != 0.1 {
+			t.Fatalf("expected term A's rate, got %+v, %v", s, err)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := map[string]string{
+		"no USD":      `{"pricePerUnit": {"EUR": "0.1"}}`,
+		"bad begin":   `{"beginRange": "x", "pricePerUnit": {"USD": "0.1"}}`,
+		"bad end":     `{"endRange": "x", "pricePerUnit": {"USD": "0.1"}}`,
+		"bad USD":     `{"pricePerUnit": {"USD": "x"}}`,
+		"no OnDemand": ``,
+	}
+	for name, dim := range tests {
+		priceJSON := `{}`
+		if dim != "" {
+			priceJSON = `{"terms": {"OnDemand": {"o": {"priceDimensions": {"d": ` + dim + `}}}}}`
+		}
+		_, err := parseSchedule(parseDoc(t, priceJSON), "USD")
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if (name == "no USD" || name == "no OnDemand") && !errors.Is(err, errNoOnDemand) {
+			t.Errorf("%s: expected errNoOnDemand, got %v", name, err)
+		}
+	}
+}
+
+func TestScheduleRate(t *testing.T) {
+	if got := wantSchedule.Rate(); got != 0.0015 {
+		t.Errorf("Rate: got %v, want 0.0015", got)
+	}
+	free := Schedule{{EndRange: 1000, Rate: 0}, {BeginRange: 1000, Rate: 0.002}}
+	if got := free.Rate(); got != 0.002 {
+		t.Errorf("a free allowance should not be the headline rate, got %v", got)
+	}
+	if got := (Schedule{{Rate: 0}}).Rate(); got != 0 {
+		t.Errorf("unpriced schedule: got %v, want 0", got)
+	}
+}
+
+func TestScheduleRateTiers(t *testing.T) {
+	got := wantSchedule.RateTiers()
+	want := []calculator.RateTier{
+		{From: 0, To: 1000000, PerHour: 0.0015},
+		{From: 1000000, To: 5000000, PerHour: 0.001},
+		{From: 5000000, PerHour: 0.00025},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tier %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSetTiered(t *testing.T) {
+	var r RateSheet
+	p := Provenance{Source: SourceAPI}
+
+	r.setTiered(FieldACKResourcePerHour, 0.0015, wantSchedule[:1], p)
+	if r.Tiers != nil {
+		t.Errorf("a single tier should not be recorded, got %+v", r.Tiers)
+	}
+
+	r.setTiered(FieldACKResourcePerHour, 0.0015, wantSchedule, p)
+	if len(r.Tiers[FieldACKResourcePerHour]) != 3 || r.ACKResourcePerHour != 0.0015 {
+		t.Errorf("expected the schedule recorded, got %+v", r)
+	}
+
+	r.setWithProvenance(FieldACKResourcePerHour, 0.002, Provenance{Source: SourceOverrides})
+	if _, ok := r.Tiers[FieldACKResourcePerHour]; ok {
+		t.Error("a flat rate should clear the schedule")
+	}
+}
+
+func TestRateSheetEqualComparesTiers(t *testing.T) {
+	a := RateSheet{Rates: DefaultRates()}
+	b := RateSheet{Rates: DefaultRates()}
+	b.setTiered(FieldACKResourcePerHour, a.ACKResourcePerHour, Schedule{{Rate: a.ACKResourcePerHour, EndRange: 10}, {BeginRange: 10, Rate: 0.00001}}, Provenance{})
+	if a.Equal(b) {
+		t.Error("rates with different tiers should not be equal")
+	}
+}
+
+func TestAPISourceTiers(t *testing.T) {
+	responses := allCapabilityProducts("us-east-1")
+	eks := responses["AmazonEKS:regionCode=us-east-1"]
+	for i, p := range eks.PriceList {
+		if strings.Contains(p, "ACK-CR-Hours") {
+			eks.PriceList[i] = tieredProductJSON("USE1-AmazonEKSCapabilities-ACK-CR-Hours:perCustomResource")
+		}
+	}
+
+	rates, err := APISource(&mockPricingAPI{responses: responses}, nil).Lookup(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if rates.ACKResourcePerHour != 0.0015 {
+		t.Errorf("ACKResourcePerHour: got %v, want the first tier's 0.0015", rates.ACKResourcePerHour)
+	}
+	if len(rates.Tiers[FieldACKResourcePerHour]) != 3 {
+		t.Errorf("expected the ACK schedule, got %+v", rates.Tiers)
+	}
+	if _, ok := rates.Tiers[FieldArgoCDAppPerHour]; ok {
+		t.Error("flat rates should have no schedule")
+	}
+}
+
+func TestChainKeepsTiers(t *testing.T) {
+	var tiered RateSheet
+	tiered.setTiered(FieldKroRGDPerHour, 0.0015, wantSchedule, Provenance{Source: "tiered"})
+	flat := NewSource("flat", func(context.Context, string) (RateSheet, error) {
+		r := RateSheet{Rates: DefaultRates()}
+		r.setTiered(FieldArgoCDAppPerHour, 0.1, wantSchedule, Provenance{})
+		return r, nil
+	})
+	override := NewSource(SourceOverrides, func(context.Context, string) (RateSheet, error) {
+		return RateSheet{Rates: Rates{ArgoCDAppPerHour: 0.002}}, nil
+	})
+
+	chain := NewChain(override, NewSource("tiered", func(context.Context, string) (RateSheet, error) { return tiered, nil }), flat)
+	res, err := chain.Resolve(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	rates := res.Rates
+	if len(rates.Tiers[FieldKroRGDPerHour]) != 3 {
+		t.Errorf("expected the kro schedule carried through the chain, got %+v", rates.Tiers)
+	}
+	if _, ok := rates.Tiers[FieldArgoCDAppPerHour]; ok {
+		t.Error("an overridden field should not keep a lower source's schedule")
+	}
+}
+
+func TestCacheRoundTripsTiers(t *testing.T) {
+	c := newTestCache(t)
+	rates := RateSheet{Rates: DefaultRates()}
+	rates.setTiered(FieldACKResourcePerHour, 0.0015, wantSchedule, Provenance{Source: SourceAPI})
+	if err := c.SaveSheet("us-east-1", rates); err != nil {
+		t.Fatalf("SaveSheet: %v", err)
+	}
+
+	got, err := CacheSource(c).Lookup(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if !got.Equal(rates) {
+		t.Errorf("expected the schedule cached, got %+v", got.Tiers)
+	}
+}
+
+func TestParseOffersTiers(t *testing.T) {
+	offer := `{
+		"products": {"S1": {"sku": "S1", "attributes": {"servicecode": "AmazonEKS", "regionCode": "us-east-1",
+			"usagetype": "USE1-AmazonEKSCapabilities-ACK-CR-Hours:perCustomResource"}}},
+		"terms": {"OnDemand": {"S1": {"S1.T": {"priceDimensions": ` + tieredDimensions + `}}}}
+	}`
+	o, err := ParseOffers(strings.NewReader(offer))
+	if err != nil {
+		t.Fatalf("ParseOffers: %v", err)
+	}
+
+	rates, err := o.Lookup(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if rates.ACKResourcePerHour != 0.0015 || len(rates.Tiers[FieldACKResourcePerHour]) != 3 {
+		t.Errorf("expected the ACK schedule from the offer file, got %v %+v", rates.ACKResourcePerHour, rates.Tiers)
+	}
+	if p := rates.Provenance[FieldACKResourcePerHour]; p.UsageType != "USE1-AmazonEKSCapabilities-ACK-CR-Hours:perCustomResource" {
+		t.Errorf("unexpected provenance %+v", p)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+const dateLayout = "2006-01-02"
+
+// rateHistory lists every recorded change to a region's rates.
+func rateHistory(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("history requires a region\n\n%s", usage)
+	}
+	region := args[0]
+
+	snapshots, err := newCache().History(region)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		_, err := fmt.Fprintf(stdout, "No rate history for %s\n", region)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(stdout, "%d snapshot(s) for %s since %s\n", len(snapshots), region, snapshots[0].FetchedAt.Local().Format(dateLayout)); err != nil {
+		return err
+	}
+	changes := pricing.Changes(snapshots)
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(stdout, "No rate changes")
+		return err
+	}
+
+	// The tabwriter holds the table until Flush, which reports any error
+	// writing it out.
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "DATE\tRATE\tFROM\tTO\tCHANGE")
+	for _, c := range changes {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%.6f\t%.6f\t%+.1f%%\n", c.At.Local().Format(dateLayout), c.Field, c.From, c.To, c.Percent())
+	}
+	return w.Flush()
+}
+
+// runAsOf starts the calculator, configured by opts, with the rates
+// recorded at the end of the given day.
+func runAsOf(args []string, opts tui.Options) error {
+	if len(args) != 1 {
+		return fmt.Errorf("--as-of requires a date (YYYY-MM-DD)\n\n%s", usage)
+	}
+	day, err := time.ParseInLocation(dateLayout, args[0], time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid --as-of date %q: use YYYY-MM-DD", args[0])
+	}
+	return tuiRunAsOf(day.AddDate(0, 0, 1).Add(-time.Nanosecond), opts)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josegonzalez/aws-eks-calculator/internal/tui"
+	"github.com/josegonzalez/aws-eks-calculator/pricing"
+)
+
+func writeHistory(t *testing.T, dir, region string, snapshots ...pricing.Snapshot) {
+	t.Helper()
+	var b strings.Builder
+	for _, s := range snapshots {
+		fmt.Fprintf(&b, `{"rates":{"ArgoCDBasePerHour":%v,"EBSGBMonth":0.08},"fetched_at":%q}`+"\n", s.Rates.ArgoCDBasePerHour, s.FetchedAt.Format(time.RFC3339))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "history-"+region+".jsonl"), []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateHistory(t *testing.T) {
+	dir := withTestCache(t)
+	oldOut := stdout
+	defer func() { stdout = oldOut }()
+	var buf bytes.Buffer
+	stdout = &buf
+
+	first := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	second := time.Date(2026, 9, 15, 12, 0, 0, 0, time.UTC)
+	writeHistory(t, dir, "us-east-1",
+		pricing.Snapshot{Rates: pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 0.025}}, FetchedAt: first},
+		pricing.Snapshot{Rates: pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 0.027}}, FetchedAt: second},
+	)
+
+	if err := run([]string{"history", "us-east-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "2 snapshot(s) for us-east-1 since "+first.Local().Format("2006-01-02")) {
+		t.Errorf("missing summary in %q", out)
+	}
+	if !strings.Contains(out, "DATE") || !strings.Contains(out, second.Local().Format("2006-01-02")+"  ArgoCDBasePerHour  0.025000  0.027000  +8.0%") {
+		t.Errorf("missing change in %q", out)
+	}
+	if strings.Contains(out, "EBSGBMonth") {
+		t.Errorf("unchanged rates should not be listed: %q", out)
+	}
+}
+
+func TestRateHistoryNoChanges(t *testing.T) {
+	dir := withTestCache(t)
+	oldOut := stdout
+	defer func() { stdout = oldOut }()
+	var buf bytes.Buffer
+	stdout = &buf
+
+	writeHistory(t, dir, "eu-west-1", pricing.Snapshot{Rates: pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 0.025}}, FetchedAt: time.Now()})
+	if err := run([]string{"history", "eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No rate changes") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := run([]string{"history", "ap-south-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "No rate history for ap-south-1\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestRateHistoryErrors(t *testing.T) {
+	if err := run([]string{"history"}); err == nil || !strings.HasPrefix(err.Error(), "history requires a region") {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+
+	dir := withTestCache(t)
+	if err := os.Mkdir(filepath.Join(dir, "history-us-east-1.jsonl"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"history", "us-east-1"}); err == nil || !strings.HasPrefix(err.Error(), "reading rate history: ") {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
+
+func TestRateHistoryWriteErrors(t *testing.T) {
+	dir := withTestCache(t)
+	oldOut := stdout
+	defer func() { stdout = oldOut }()
+	stdout = &failingWriter{}
+
+	writeHistory(t, dir, "us-east-1", pricing.Snapshot{Rates: pricing.RateSheet{Rates: pricing.Rates{ArgoCDBasePerHour: 0.025}}, FetchedAt: time.Now()})
+	for _, region := range []string{"us-east-1", "ap-south-1"} {
+		if err := run([]string{"history", region}); err == nil || err.Error() != "write failed" {
+			t.Errorf("%s: expected the write error, got %v", region, err)
+		}
+	}
+}
+
+func TestRunAsOf(t *testing.T) {
+	old := tuiRunAsOf
+	defer func() { tuiRunAsOf = old }()
+	var got time.Time
+	var gotOpts tui.Options
+	tuiRunAsOf = func(asOf time.Time, opts tui.Options) error {
+		got, gotOpts = asOf, opts
+		return nil
+	}
+
+	if err := run([]string{"--offline", "--as-of", "2026-09-01"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Date(2026, 9, 1, 23, 59, 59, 999999999, time.Local)
+	if !got.Equal(want) {
+		t.Errorf("expected the end of the day %v, got %v", want, got)
+	}
+	if !gotOpts.Offline {
+		t.Error("expected the global options passed on")
+	}
+}
+
+func TestRunAsOfErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--as-of"}, "--as-of requires a date (YYYY-MM-DD)"},
+		{[]string{"--as-of", "2026-09-01", "extra"}, "--as-of requires a date (YYYY-MM-DD)"},
+		{[]string{"--as-of", "yesterday"}, `invalid --as-of date "yesterday": use YYYY-MM-DD`},
+	}
+	for _, tt := range tests {
+		if err := run(tt.args); err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%v: expected %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}